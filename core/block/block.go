@@ -6,13 +6,27 @@ type Block struct {
 	Durability  float32 `json:"durability,omitempty"`
 	Hardness    float32 `json:"hardness,omitempty"`
 	Liquid      bool    `json:"liquid,omitempty"`
+	Fluid       string  `json:"fluid,omitempty"`
+	LiquidLevel uint8   `json:"liquidLevel,omitempty"`
+	FlowDelay   int     `json:"flowDelay,omitempty"`
+	FlowDrop    uint8   `json:"flowDrop,omitempty"`
+	Renewable   bool    `json:"renewable,omitempty"`
 	Material    string  `json:"material,omitempty"`
 	Strength    float32 `json:"strength,omitempty"`
 	StepSound   string  `json:"stepSound,omitempty"`
 	Transparent bool    `json:"transparent,omitempty"`
+	Translucent bool    `json:"translucent,omitempty"`
 	Visible     bool    `json:"visible,omitempty"`
 	Obstacle    bool    `json:"obstacle,omitempty"`
 	Plant       bool    `json:"plant,omitempty"`
+	LightLevel  uint8   `json:"lightLevel,omitempty"`
+	Drop        string  `json:"drop,omitempty"` // block given when broken, the block itself when empty
+
+	// cuboids is the shape of the block, see Shape
+	cuboids []Cuboid
+
+	// index is the position of the block in the register
+	index int
 }
 
 func NewBlock(id string) *Block {
@@ -49,13 +63,16 @@ func (b *Block) stepSound(f string) *Block {
 	return b
 }
 
-func (b *Block) liquid() *Block {
-	b.Liquid = true
+func (b *Block) transparent() *Block {
+	b.Transparent = true
 	return b
 }
 
-func (b *Block) transparent() *Block {
+// translucent blocks are see-through and blended,
+// which also makes them transparent
+func (b *Block) translucent() *Block {
 	b.Transparent = true
+	b.Translucent = true
 	return b
 }
 
@@ -73,3 +90,35 @@ func (b *Block) plant() *Block {
 	b.Plant = true
 	return b
 }
+
+func (b *Block) lightLevel(l uint8) *Block {
+	if l > MaxLightLevel {
+		l = MaxLightLevel
+	}
+	b.LightLevel = l
+	return b
+}
+
+// drops sets the block given when broken, AirID for nothing
+func (b *Block) drops(id string) *Block {
+	b.Drop = id
+	return b
+}
+
+// Drops returns the block given to the player breaking b, "" when
+// nothing is given
+func (b *Block) Drops() string {
+	if !b.Breakable || b.Liquid || b.Drop == AirID {
+		return ""
+	}
+	if b.Drop != "" {
+		return b.Drop
+	}
+	return b.ID
+}
+
+// Opaque reports whether the block fully hides whatever is behind it,
+// which is what ambient occlusion and sky light checks care about
+func (b *Block) Opaque() bool {
+	return b.Visible && !b.Transparent && !b.Plant
+}
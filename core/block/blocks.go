@@ -11,6 +11,10 @@ const (
 	GrassID      = "core:grass"
 	DandelionID  = "core:dandelion"
 	CloudID      = "core:cloud"
+	TorchID      = "core:torch"
+	LampID       = "core:lamp"
+	GlassID      = "core:glass"
+	IceID        = "core:ice"
 )
 
 func LoadBlocks() {
@@ -20,6 +24,7 @@ func LoadBlocks() {
 	_ = AddBlock(
 		NewBlock(GrassBlockID).
 			breakable().
+			drops(DirtID).
 			obstacle().
 			visible().
 			durability(0.5).
@@ -68,6 +73,7 @@ func LoadBlocks() {
 	_ = AddBlock(
 		NewBlock(LeavesID).
 			breakable().
+			drops(AirID).
 			visible().
 			obstacle().
 			transparent().
@@ -93,8 +99,10 @@ func LoadBlocks() {
 	_ = AddBlock(
 		NewBlock(GrassID).
 			breakable().
+			drops(AirID).
 			visible().
 			plant().
+			shape(crossShape(15, 13)...).
 			transparent().
 			durability(0.1).
 			hardness(0.1).
@@ -107,6 +115,7 @@ func LoadBlocks() {
 			breakable().
 			visible().
 			plant().
+			shape(crossShape(10, 10)...).
 			transparent().
 			durability(0.1).
 			hardness(0.1).
@@ -114,9 +123,67 @@ func LoadBlocks() {
 			strength(0.1),
 	)
 
+	_ = AddBlock(
+		NewBlock(TorchID).
+			breakable().
+			visible().
+			plant().
+			shape(cuboid(7, 0, 7, 9, 14, 9)).
+			transparent().
+			durability(0.1).
+			hardness(0.1).
+			material("wood").
+			strength(0.1).
+			lightLevel(14).
+			stepSound("wood"),
+	)
+
+	_ = AddBlock(
+		NewBlock(LampID).
+			breakable().
+			visible().
+			obstacle().
+			durability(0.3).
+			hardness(0.3).
+			material("glass").
+			strength(0.3).
+			lightLevel(15).
+			stepSound("glass"),
+	)
+
+	_ = AddBlock(
+		NewBlock(GlassID).
+			breakable().
+			drops(AirID).
+			visible().
+			obstacle().
+			translucent().
+			durability(0.3).
+			hardness(0.3).
+			material("glass").
+			strength(0.3).
+			stepSound("glass"),
+	)
+
+	_ = AddBlock(
+		NewBlock(IceID).
+			breakable().
+			drops(AirID).
+			visible().
+			obstacle().
+			translucent().
+			durability(0.5).
+			hardness(0.5).
+			material("ice").
+			strength(0.5).
+			stepSound("glass"),
+	)
+
 	_ = AddBlock(
 		NewBlock(CloudID).
 			visible().
 			material("cloud"),
 	)
+
+	loadLiquids()
 }
@@ -0,0 +1,27 @@
+package block
+
+// MaxLightLevel is the brightest light a block can emit
+const MaxLightLevel = 15
+
+// Encode returns the int a block is exchanged with the server as, the
+// index of the block in the register. The index depends on the order
+// blocks are registered in, so clients and the worlds saved by a server
+// only agree while that order doesn't change. The light a block emits
+// isn't sent, it follows from the block
+func Encode(b *Block) int {
+	if b == nil {
+		return 0
+	}
+	return b.index
+}
+
+// Decode returns the block sent as w, nil if the index isn't registered
+func Decode(w int) *Block {
+	instance.mx.Lock()
+	defer instance.mx.Unlock()
+
+	if w < 0 || w >= len(instance.order) {
+		return nil
+	}
+	return instance.order[w]
+}
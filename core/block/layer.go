@@ -0,0 +1,28 @@
+package block
+
+// Layer is the render pass a block is drawn in
+type Layer int
+
+const (
+	// LayerOpaque blocks are solid and drawn first
+	LayerOpaque Layer = iota
+	// LayerCutout blocks have fully transparent pixels that are
+	// alpha tested, like leaves and plants
+	LayerCutout
+	// LayerTranslucent blocks are blended, back to front,
+	// after the other layers
+	LayerTranslucent
+
+	LayerCount = 3
+)
+
+func (b *Block) Layer() Layer {
+	switch {
+	case b.Translucent:
+		return LayerTranslucent
+	case b.Transparent || b.Plant:
+		return LayerCutout
+	default:
+		return LayerOpaque
+	}
+}
@@ -0,0 +1,98 @@
+package block
+
+import "fmt"
+
+const (
+	WaterID = "core:water"
+	LavaID  = "core:lava"
+)
+
+// Liquid levels, a source is level 0 and every block the liquid flows
+// sideways raises the level by the FlowDrop of the liquid, until it
+// would pass MaxLiquidLevel. Liquid flowing down is at FallingLevel
+const (
+	SourceLevel    = 0
+	MaxLiquidLevel = 7
+	FallingLevel   = 8
+)
+
+// LiquidID returns the id of the variant of liquid fluid at level
+func LiquidID(fluid string, level uint8) string {
+	switch {
+	case level == SourceLevel:
+		return fluid
+	case level >= FallingLevel:
+		return fluid + "_falling"
+	default:
+		return fmt.Sprintf("%s_flowing_%d", fluid, level)
+	}
+}
+
+// LiquidVariant returns the block of the same liquid as b at level
+func LiquidVariant(b *Block, level uint8) *Block {
+	return GetBlock(LiquidID(b.Fluid, level))
+}
+
+// IsSource tells if b is a liquid source block
+func (b *Block) IsSource() bool {
+	return b.Liquid && b.LiquidLevel == SourceLevel
+}
+
+// SameFluid tells if a and b are both the same liquid, at any level
+func (b *Block) SameFluid(o *Block) bool {
+	return b.Liquid && o.Liquid && b.Fluid == o.Fluid
+}
+
+// Height returns the height of the liquid surface within the block
+func (b *Block) Height() float32 {
+	if !b.Liquid || b.LiquidLevel >= FallingLevel {
+		return 1
+	}
+	return float32(8-b.LiquidLevel) / 9
+}
+
+func (b *Block) liquid(fluid string, level uint8) *Block {
+	b.Liquid = true
+	b.Fluid = fluid
+	b.LiquidLevel = level
+	return b
+}
+
+func (b *Block) flow(delay int, drop uint8) *Block {
+	b.FlowDelay = delay
+	b.FlowDrop = drop
+	return b
+}
+
+func (b *Block) renewable() *Block {
+	b.Renewable = true
+	return b
+}
+
+// addLiquid registers the source, flowing and falling variants of a
+// liquid, all made by calling base with the id of the variant
+func addLiquid(fluid string, base func(id string) *Block) {
+	for level := uint8(SourceLevel); level <= FallingLevel; level++ {
+		_ = AddBlock(base(LiquidID(fluid, level)).liquid(fluid, level))
+	}
+}
+
+func loadLiquids() {
+	addLiquid(WaterID, func(id string) *Block {
+		return NewBlock(id).
+			visible().
+			translucent().
+			material("water").
+			flow(5, 1).
+			renewable()
+	})
+
+	addLiquid(LavaID, func(id string) *Block {
+		return NewBlock(id).
+			visible().
+			transparent().
+			material("lava").
+			lightLevel(15).
+			flow(30, 2)
+	})
+}
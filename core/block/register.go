@@ -7,16 +7,21 @@ import (
 
 type register struct {
 	blocks map[string]*Block
-	mx *sync.Mutex
+	order  []*Block
+	mx     *sync.Mutex
 }
 
-var instance *register
+var instance = newRegister()
 
-func InitRegister() {
-	instance = &register{
+func newRegister() *register {
+	return &register{
 		blocks: map[string]*Block{},
-		mx: &sync.Mutex{},
+		mx:     &sync.Mutex{},
 	}
+}
+
+func InitRegister() {
+	instance = newRegister()
 
 	LoadBlocks()
 }
@@ -33,7 +38,9 @@ func AddBlock(block *Block) error {
 		return errors.Errorf("block with id %s is already registered", block.ID)
 	}
 
+	block.index = len(instance.order)
 	instance.blocks[block.ID] = block
+	instance.order = append(instance.order, block)
 
 	return nil
 }
@@ -45,11 +52,13 @@ func GetBlock(id string) (block *Block) {
 	return instance.blocks[id]
 }
 
+// RangeBlocks calls rangeFunc for the blocks in the order they were
+// added, until it returns false
 func RangeBlocks(rangeFunc func(block *Block) bool) {
 	instance.mx.Lock()
 	defer instance.mx.Unlock()
 
-	for _, block := range instance.blocks {
+	for _, block := range instance.order {
 		if f := rangeFunc(block); !f {
 			break
 		}
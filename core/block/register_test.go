@@ -20,4 +20,69 @@ func TestRegisterBlockWithEmptyIDShouldFail(t *testing.T) {
 
 	err = AddBlock(&Block{})
 	assert.Error(t, err)
-}
\ No newline at end of file
+}
+
+func TestEncodeDecode(t *testing.T) {
+	InitRegister()
+
+	torch := GetBlock(TorchID)
+	assert.Equal(t, torch, Decode(Encode(torch)))
+	assert.Equal(t, AirID, Decode(Encode(GetBlock(AirID))).ID)
+	assert.Nil(t, Decode(-1))
+	assert.Nil(t, Decode(1<<20))
+}
+
+func TestBlockLayers(t *testing.T) {
+	InitRegister()
+
+	assert.Equal(t, LayerOpaque, GetBlock(StoneID).Layer())
+	assert.Equal(t, LayerCutout, GetBlock(LeavesID).Layer())
+	assert.Equal(t, LayerCutout, GetBlock(DandelionID).Layer())
+	assert.Equal(t, LayerTranslucent, GetBlock(GlassID).Layer())
+	assert.Equal(t, LayerTranslucent, GetBlock(IceID).Layer())
+	assert.False(t, GetBlock(IceID).Opaque())
+}
+
+func TestLiquidVariants(t *testing.T) {
+	InitRegister()
+
+	water := GetBlock(WaterID)
+	assert.True(t, water.IsSource())
+	assert.Equal(t, WaterID, water.Fluid)
+
+	flowing := LiquidVariant(water, 3)
+	assert.Equal(t, "core:water_flowing_3", flowing.ID)
+	assert.Equal(t, uint8(3), flowing.LiquidLevel)
+	assert.False(t, flowing.IsSource())
+	assert.True(t, flowing.SameFluid(water))
+	assert.False(t, flowing.SameFluid(GetBlock(LavaID)))
+	assert.Equal(t, LayerTranslucent, flowing.Layer())
+
+	falling := LiquidVariant(flowing, FallingLevel)
+	assert.Equal(t, "core:water_falling", falling.ID)
+	assert.Equal(t, float32(1), falling.Height())
+	assert.Greater(t, water.Height(), flowing.Height())
+
+	assert.Equal(t, uint8(15), LiquidVariant(GetBlock(LavaID), 2).LightLevel)
+}
+
+func TestRangeBlocksInRegisterOrder(t *testing.T) {
+	InitRegister()
+
+	var ids []string
+	RangeBlocks(func(b *Block) bool {
+		ids = append(ids, b.ID)
+		return len(ids) < 3
+	})
+	assert.Equal(t, []string{AirID, GrassBlockID, DirtID}, ids)
+}
+
+func TestDrops(t *testing.T) {
+	InitRegister()
+
+	assert.Equal(t, StoneID, GetBlock(StoneID).Drops())
+	assert.Equal(t, DirtID, GetBlock(GrassBlockID).Drops())
+	assert.Equal(t, "", GetBlock(GlassID).Drops())
+	assert.Equal(t, "", GetBlock(WaterID).Drops())
+	assert.Equal(t, "", GetBlock(CloudID).Drops(), "unbreakable blocks drop nothing")
+}
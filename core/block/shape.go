@@ -0,0 +1,129 @@
+package block
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Cuboid is an axis aligned box. The cuboids of a block are relative to
+// its center, the block spans from -0.5 to 0.5 on each axis
+type Cuboid struct {
+	Min, Max mgl32.Vec3
+}
+
+// FullCuboid is the cuboid of a whole block
+var FullCuboid = Cuboid{Min: mgl32.Vec3{-0.5, -0.5, -0.5}, Max: mgl32.Vec3{0.5, 0.5, 0.5}}
+
+// pixel is the size of a pixel of the block textures
+const pixel = 1.0 / 16
+
+// cuboid returns the cuboid from x0, y0, z0 to x1, y1, z1 in pixels
+// from the bottom corner of the block, like the textures are laid out
+func cuboid(x0, y0, z0, x1, y1, z1 float32) Cuboid {
+	return Cuboid{
+		Min: mgl32.Vec3{x0*pixel - 0.5, y0*pixel - 0.5, z0*pixel - 0.5},
+		Max: mgl32.Vec3{x1*pixel - 0.5, y1*pixel - 0.5, z1*pixel - 0.5},
+	}
+}
+
+// crossShape is the shape of a plant drawn with two crossed quads
+// through the center of the block, width and height pixels of which
+// are covered by the plant
+func crossShape(width, height float32) []Cuboid {
+	side := (16 - width) / 2
+	return []Cuboid{
+		cuboid(side, 0, 7.5, 16-side, height, 8.5),
+		cuboid(7.5, 0, side, 8.5, height, 16-side),
+	}
+}
+
+// shape sets the cuboids b is made of
+func (b *Block) shape(cuboids ...Cuboid) *Block {
+	b.cuboids = cuboids
+	return b
+}
+
+// Shape returns the cuboids the block is made of, which its outline is
+// drawn around and rays hit. Invisible blocks have none, liquids are
+// as high as their level and other blocks fill the whole block unless
+// they were given a shape
+func (b *Block) Shape() []Cuboid {
+	switch {
+	case b.cuboids != nil:
+		return b.cuboids
+	case !b.Visible:
+		return nil
+	case b.Liquid:
+		return []Cuboid{{Min: FullCuboid.Min, Max: mgl32.Vec3{0.5, b.Height() - 0.5, 0.5}}}
+	}
+	return []Cuboid{FullCuboid}
+}
+
+// Collision returns the cuboids of the block which stop the player,
+// only obstacles have any
+func (b *Block) Collision() []Cuboid {
+	if !b.Obstacle {
+		return nil
+	}
+	return b.Shape()
+}
+
+// Offset returns c moved by v
+func (c Cuboid) Offset(v mgl32.Vec3) Cuboid {
+	return Cuboid{Min: c.Min.Add(v), Max: c.Max.Add(v)}
+}
+
+// Overlaps tells if c and o share any volume, cuboids which only touch
+// don't
+func (c Cuboid) Overlaps(o Cuboid) bool {
+	for i := 0; i < 3; i++ {
+		if c.Max[i] <= o.Min[i] || c.Min[i] >= o.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Hit returns how far along dir the ray from origin first enters c, 0
+// when origin is inside. It tells if the ray hits c at all
+func (c Cuboid) Hit(origin, dir mgl32.Vec3) (float32, bool) {
+	near, far := float32(0), float32(math.Inf(1))
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			if origin[i] < c.Min[i] || origin[i] > c.Max[i] {
+				return 0, false
+			}
+			continue
+		}
+		t0, t1 := (c.Min[i]-origin[i])/dir[i], (c.Max[i]-origin[i])/dir[i]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > near {
+			near = t0
+		}
+		if t1 < far {
+			far = t1
+		}
+		if near > far {
+			return 0, false
+		}
+	}
+	return near, true
+}
+
+// Hit returns how far along dir the ray from origin first hits the
+// shape of b, with origin relative to the center of the block
+func (b *Block) Hit(origin, dir mgl32.Vec3) (float32, bool) {
+	var (
+		nearest float32
+		hit     bool
+	)
+	for _, c := range b.Shape() {
+		if t, ok := c.Hit(origin, dir); ok && (!hit || t < nearest) {
+			nearest, hit = t, true
+		}
+	}
+	return nearest, hit
+}
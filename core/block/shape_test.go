@@ -0,0 +1,70 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShape(t *testing.T) {
+	InitRegister()
+
+	assert.Nil(t, GetBlock(AirID).Shape())
+	assert.Equal(t, []Cuboid{FullCuboid}, GetBlock(StoneID).Shape())
+	assert.Len(t, GetBlock(GrassID).Shape(), 2, "plants are crossed quads")
+
+	torch := GetBlock(TorchID).Shape()
+	assert.Len(t, torch, 1)
+	assert.InDelta(t, 0.375, torch[0].Max.Y(), 1e-6)
+
+	water := GetBlock(WaterID)
+	flowing := GetBlock(water.Fluid + "_flowing_4")
+	assert.Equal(t, water.Height()-0.5, water.Shape()[0].Max.Y())
+	assert.Less(t, flowing.Shape()[0].Max.Y(), water.Shape()[0].Max.Y(), "liquids are as high as their level")
+}
+
+func TestCuboidHit(t *testing.T) {
+	t0, ok := FullCuboid.Hit(mgl32.Vec3{-2, 0, 0}, mgl32.Vec3{1, 0, 0})
+	assert.True(t, ok)
+	assert.Equal(t, float32(1.5), t0)
+
+	_, ok = FullCuboid.Hit(mgl32.Vec3{-2, 0.6, 0}, mgl32.Vec3{1, 0, 0})
+	assert.False(t, ok, "passing over")
+	_, ok = FullCuboid.Hit(mgl32.Vec3{-2, 0, 0}, mgl32.Vec3{-1, 0, 0})
+	assert.False(t, ok, "behind")
+
+	t0, ok = FullCuboid.Hit(mgl32.Vec3{0, 0.2, 0}, mgl32.Vec3{0, 1, 0})
+	assert.True(t, ok)
+	assert.Zero(t, t0, "from inside")
+}
+
+func TestPlantHit(t *testing.T) {
+	InitRegister()
+	grass := GetBlock(GrassID)
+
+	// a ray along the edge of the block misses the crossed quads
+	_, ok := grass.Hit(mgl32.Vec3{-0.48, 0, -2}, mgl32.Vec3{0, 0, 1})
+	assert.False(t, ok)
+	_, ok = grass.Hit(mgl32.Vec3{0.3, 0, -2}, mgl32.Vec3{0, 0, 1})
+	assert.True(t, ok)
+	_, ok = grass.Hit(mgl32.Vec3{0, 0.4, -2}, mgl32.Vec3{0, 0, 1})
+	assert.False(t, ok, "above the grass")
+}
+
+func TestCollision(t *testing.T) {
+	InitRegister()
+
+	assert.Equal(t, []Cuboid{FullCuboid}, GetBlock(StoneID).Collision())
+	assert.Nil(t, GetBlock(WaterID).Collision(), "liquids are swum through")
+	assert.Nil(t, GetBlock(GrassID).Collision(), "plants are walked through")
+	assert.Nil(t, GetBlock(AirID).Collision())
+}
+
+func TestCuboidOverlaps(t *testing.T) {
+	next := FullCuboid.Offset(mgl32.Vec3{1, 0, 0})
+	assert.Equal(t, mgl32.Vec3{0.5, -0.5, -0.5}, next.Min)
+	assert.False(t, FullCuboid.Overlaps(next), "touching")
+	assert.True(t, FullCuboid.Overlaps(FullCuboid.Offset(mgl32.Vec3{0.9, 0.5, -0.2})))
+	assert.False(t, FullCuboid.Overlaps(FullCuboid.Offset(mgl32.Vec3{0.2, 2, 0})))
+}
@@ -0,0 +1,293 @@
+// Package chat has the chat console, the log of messages and the line
+// being typed with its history
+package chat
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/artheus/go-minecraft/core/types"
+)
+
+const (
+	// MaxMessages is how many messages the log keeps
+	MaxMessages = 100
+	// MaxHistory is how many sent lines can be recalled
+	MaxHistory = 50
+	// MaxLength is the most runes a line can have
+	MaxLength = 256
+)
+
+// Console is the chat log and, while it is open, the line being
+// typed. Sent lines are kept in a history browsed with Up and Down
+type Console struct {
+	mx       sync.Mutex
+	messages []types.ChatMessage
+	open     bool
+	input    []rune
+	cursor   int
+	scroll   int
+
+	history []string
+	browse  int    // index in history of the shown line, len(history) for the draft
+	draft   []rune // line typed before browsing the history
+
+	completions []string // lines the typed line completes to, until it is edited
+	completion  int      // index of the shown completion
+}
+
+func New() *Console {
+	return new(Console)
+}
+
+// Add appends a message to the log, the oldest are dropped past
+// MaxMessages
+func (c *Console) Add(text string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.messages = append(c.messages, types.ChatMessage{Text: text, Time: time.Now()})
+	if n := len(c.messages) - MaxMessages; n > 0 {
+		c.messages = append(c.messages[:0], c.messages[n:]...)
+	}
+	// the scrolled back log stays on the same messages
+	if c.scroll > 0 {
+		c.scroll++
+		c.clampScroll()
+	}
+}
+
+// Messages returns the log, oldest first
+func (c *Console) Messages() []types.ChatMessage {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return append([]types.ChatMessage(nil), c.messages...)
+}
+
+// Open opens the console with the line set to prefix, "/" starts a
+// command
+func (c *Console) Open(prefix string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.open = true
+	c.setInput([]rune(prefix))
+	c.browse = len(c.history)
+	c.scroll = 0
+}
+
+// Close closes the console, the typed line is dropped
+func (c *Console) Close() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.open = false
+	c.setInput(nil)
+	c.draft = nil
+	c.scroll = 0
+}
+
+func (c *Console) Visible() bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.open
+}
+
+// Input returns the typed line and the cursor position in runes
+func (c *Console) Input() (string, int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return string(c.input), c.cursor
+}
+
+// Scroll returns how many messages the log is scrolled back
+func (c *Console) Scroll() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.scroll
+}
+
+// ScrollBy scrolls the log n messages back, or forward when n is
+// negative
+func (c *Console) ScrollBy(n int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.scroll += n
+	c.clampScroll()
+}
+
+func (c *Console) clampScroll() {
+	if c.scroll > len(c.messages)-1 {
+		c.scroll = len(c.messages) - 1
+	}
+	if c.scroll < 0 {
+		c.scroll = 0
+	}
+}
+
+// Insert types r at the cursor, control characters are ignored
+func (c *Console) Insert(r rune) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if !c.open || unicode.IsControl(r) || len(c.input) >= MaxLength {
+		return
+	}
+	c.input = append(c.input, 0)
+	copy(c.input[c.cursor+1:], c.input[c.cursor:])
+	c.input[c.cursor] = r
+	c.cursor++
+	c.completions = nil
+}
+
+// Backspace deletes the rune before the cursor
+func (c *Console) Backspace() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.cursor == 0 {
+		return
+	}
+	c.input = append(c.input[:c.cursor-1], c.input[c.cursor:]...)
+	c.cursor--
+	c.completions = nil
+}
+
+// Delete deletes the rune after the cursor
+func (c *Console) Delete() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.cursor == len(c.input) {
+		return
+	}
+	c.input = append(c.input[:c.cursor], c.input[c.cursor+1:]...)
+	c.completions = nil
+}
+
+// Move moves the cursor n runes, to the left when n is negative
+func (c *Console) Move(n int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.cursor += n
+	if c.cursor < 0 {
+		c.cursor = 0
+	}
+	if c.cursor > len(c.input) {
+		c.cursor = len(c.input)
+	}
+}
+
+// Home moves the cursor to the start of the line
+func (c *Console) Home() {
+	c.mx.Lock()
+	c.cursor = 0
+	c.mx.Unlock()
+}
+
+// End moves the cursor to the end of the line
+func (c *Console) End() {
+	c.mx.Lock()
+	c.cursor = len(c.input)
+	c.mx.Unlock()
+}
+
+// Up shows the previous line of the history, the typed line is kept
+// as the draft to come back to
+func (c *Console) Up() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.browse == 0 {
+		return
+	}
+	if c.browse == len(c.history) {
+		c.draft = append([]rune(nil), c.input...)
+	}
+	c.browse--
+	c.setInput([]rune(c.history[c.browse]))
+}
+
+// Down shows the next line of the history, after the last one the
+// draft
+func (c *Console) Down() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.browse >= len(c.history) {
+		return
+	}
+	c.browse++
+	if c.browse == len(c.history) {
+		c.setInput(c.draft)
+		return
+	}
+	c.setInput([]rune(c.history[c.browse]))
+}
+
+// Submit closes the console and returns the typed line, trimmed. A
+// line that isn't blank is added to the history
+func (c *Console) Submit() string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	line := strings.TrimSpace(string(c.input))
+	if line != "" && (len(c.history) == 0 || c.history[len(c.history)-1] != line) {
+		c.history = append(c.history, line)
+		if n := len(c.history) - MaxHistory; n > 0 {
+			c.history = append(c.history[:0], c.history[n:]...)
+		}
+	}
+	c.open = false
+	c.setInput(nil)
+	c.draft = nil
+	c.scroll = 0
+	return line
+}
+
+// Complete replaces the line with the first line complete returns for
+// it, completing again goes through the others
+func (c *Console) Complete(complete func(line string) []string) {
+	c.mx.Lock()
+	if c.completions != nil {
+		c.completion = (c.completion + 1) % len(c.completions)
+		c.input = append(c.input[:0], []rune(c.completions[c.completion])...)
+		c.cursor = len(c.input)
+		c.mx.Unlock()
+		return
+	}
+	line := string(c.input)
+	c.mx.Unlock()
+
+	// complete may take long, the line is only changed if it is still
+	// the one completed
+	lines := complete(line)
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if len(lines) == 0 || string(c.input) != line {
+		return
+	}
+	c.setInput([]rune(lines[0]))
+	c.completions, c.completion = lines, 0
+}
+
+// Completions returns the lines the typed line completes to and the
+// index of the one shown, nil when the line isn't being completed
+func (c *Console) Completions() ([]string, int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.completions, c.completion
+}
+
+// setInput replaces the line, with the cursor at its end. The line is
+// no longer being completed
+func (c *Console) setInput(line []rune) {
+	c.input = append(c.input[:0], line...)
+	c.cursor = len(c.input)
+	c.completions = nil
+}
+
+// Format returns the line of the log for a message of player name.
+// Color codes in the name and the text are escaped, they are shown as
+// typed
+func Format(name, text string) string {
+	return "<" + escape(name) + "> " + escape(text)
+}
+
+// escape doubles the § starting color codes, which is shown as one
+func escape(s string) string {
+	return strings.ReplaceAll(s, "§", "§§")
+}
@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func typeText(c *Console, s string) {
+	for _, r := range s {
+		c.Insert(r)
+	}
+}
+
+func TestConsoleEditing(t *testing.T) {
+	c := New()
+	c.Insert('x')
+	line, _ := c.Input()
+	assert.Equal(t, "", line, "nothing is typed while closed")
+
+	c.Open("/")
+	typeText(c, "tme")
+	c.Move(-2)
+	c.Insert('i')
+	line, cursor := c.Input()
+	assert.Equal(t, "/time", line)
+	assert.Equal(t, 3, cursor)
+
+	c.Home()
+	c.Delete()
+	c.End()
+	c.Backspace()
+	c.Insert('\n')
+	line, cursor = c.Input()
+	assert.Equal(t, "tim", line, "control characters are ignored")
+	assert.Equal(t, 3, cursor)
+
+	c.Move(10)
+	_, cursor = c.Input()
+	assert.Equal(t, 3, cursor, "the cursor stays on the line")
+}
+
+func TestConsoleMaxLength(t *testing.T) {
+	c := New()
+	c.Open("")
+	for i := 0; i < MaxLength+10; i++ {
+		c.Insert('a')
+	}
+	line, _ := c.Input()
+	assert.Len(t, line, MaxLength)
+}
+
+func TestConsoleHistory(t *testing.T) {
+	c := New()
+	for _, s := range []string{"one", "  two ", "two", " "} {
+		c.Open("")
+		typeText(c, s)
+		c.Submit()
+	}
+	assert.False(t, c.Visible())
+
+	c.Open("")
+	typeText(c, "draft")
+	c.Up()
+	line, cursor := c.Input()
+	assert.Equal(t, "two", line, "repeated and blank lines are not kept")
+	assert.Equal(t, 3, cursor)
+	c.Up()
+	c.Up()
+	line, _ = c.Input()
+	assert.Equal(t, "one", line)
+
+	c.Down()
+	c.Down()
+	line, _ = c.Input()
+	assert.Equal(t, "draft", line, "the typed line comes back after the history")
+	c.Down()
+	line, _ = c.Input()
+	assert.Equal(t, "draft", line)
+
+	c.Up()
+	c.Close()
+	c.Open("")
+	c.Down()
+	line, _ = c.Input()
+	assert.Equal(t, "", line)
+}
+
+func TestConsoleMessages(t *testing.T) {
+	c := New()
+	for i := 0; i < MaxMessages+5; i++ {
+		c.Add(fmt.Sprint(i))
+	}
+	msgs := c.Messages()
+	assert.Len(t, msgs, MaxMessages)
+	assert.Equal(t, "5", msgs[0].Text)
+	assert.False(t, msgs[0].Time.IsZero())
+
+	c.ScrollBy(3)
+	assert.Equal(t, 3, c.Scroll())
+	c.Add("new")
+	assert.Equal(t, 4, c.Scroll(), "a new message doesn't move the scrolled back log")
+	c.ScrollBy(-10)
+	assert.Equal(t, 0, c.Scroll())
+	c.ScrollBy(1000)
+	assert.Equal(t, MaxMessages-1, c.Scroll())
+
+	c.Open("")
+	assert.Equal(t, 0, c.Scroll(), "opening shows the latest messages")
+}
+
+func TestConsoleComplete(t *testing.T) {
+	c := New()
+	c.Open("/t")
+	options := func(line string) []string {
+		if line != "/t" {
+			return nil
+		}
+		return []string{"/time", "/tp"}
+	}
+	c.Complete(options)
+	line, cursor := c.Input()
+	assert.Equal(t, "/time", line)
+	assert.Equal(t, 5, cursor)
+	completions, shown := c.Completions()
+	assert.Equal(t, []string{"/time", "/tp"}, completions)
+	assert.Equal(t, 0, shown)
+
+	c.Complete(options)
+	c.Complete(options)
+	line, _ = c.Input()
+	assert.Equal(t, "/time", line, "completing again cycles through the lines")
+
+	c.Insert(' ')
+	completions, _ = c.Completions()
+	assert.Nil(t, completions, "typing ends the completion")
+	c.Complete(options)
+	line, _ = c.Input()
+	assert.Equal(t, "/time ", line, "a line without completions is kept")
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "<steve> hi", Format("steve", "hi"))
+	assert.Equal(t, "<§§cred> §§4x §§", Format("§cred", "§4x §"), "color codes are escaped")
+}
@@ -11,6 +11,11 @@ import (
 type Chunk struct {
 	id       Vec3
 	segments sync.Map // map[uint8]*Segment
+
+	heightMx sync.RWMutex
+	heights  [ChunkWidth][ChunkWidth]float32 // y of the highest opaque block per column
+
+	lights sync.Map // map[Vec3]uint8, block light of the lit positions
 }
 
 func NewChunk(id Vec3) *Chunk {
@@ -18,6 +23,11 @@ func NewChunk(id Vec3) *Chunk {
 		id: id,
 		segments: sync.Map{},
 	}
+	for x := range c.heights {
+		for z := range c.heights[x] {
+			c.heights[x][z] = -1
+		}
+	}
 	return c
 }
 
@@ -64,6 +74,7 @@ func (c *Chunk) Add(id Vec3, w *Block) {
 	}
 
 	seg.(*Segment).blocks.Store(id, w)
+	c.updateHeight(id, w)
 }
 
 func (c *Chunk) Del(id Vec3) {
@@ -74,6 +85,68 @@ func (c *Chunk) Del(id Vec3) {
 	if seg, ok := c.segments.Load(segmentId(id.Y)); ok {
 		seg.(*Segment).blocks.Delete(id)
 	}
+	c.updateHeight(id, GetBlock(AirID))
+}
+
+// Height returns the y of the highest opaque block in the column of
+// block id, or -1 when the column has none. Everything above it is
+// exposed to the sky
+func (c *Chunk) Height(id Vec3) float32 {
+	x, z := c.column(id)
+
+	c.heightMx.RLock()
+	defer c.heightMx.RUnlock()
+
+	return c.heights[x][z]
+}
+
+// updateHeight keeps the height map in sync after block id changed to w
+func (c *Chunk) updateHeight(id Vec3, w *Block) {
+	x, z := c.column(id)
+
+	c.heightMx.Lock()
+	defer c.heightMx.Unlock()
+
+	top := c.heights[x][z]
+	switch {
+	case w.Opaque() && id.Y > top:
+		c.heights[x][z] = id.Y
+	case !w.Opaque() && id.Y == top:
+		y := top - 1
+		for y >= 0 && !c.Block(Vec3{X: id.X, Y: y, Z: id.Z}).Opaque() {
+			y--
+		}
+		c.heights[x][z] = y
+	}
+}
+
+// BlockLight returns the block light level (0-15) at id
+func (c *Chunk) BlockLight(id Vec3) uint8 {
+	if l, ok := c.lights.Load(id); ok {
+		return l.(uint8)
+	}
+	return 0
+}
+
+// SetBlockLight sets the block light level at id
+func (c *Chunk) SetBlockLight(id Vec3, l uint8) {
+	if l == 0 {
+		c.lights.Delete(id)
+		return
+	}
+	c.lights.Store(id, l)
+}
+
+// RangeBlockLight calls f for every lit position in the chunk
+func (c *Chunk) RangeBlockLight(f func(id Vec3, l uint8)) {
+	c.lights.Range(func(key, value interface{}) bool {
+		f(key.(Vec3), value.(uint8))
+		return true
+	})
+}
+
+func (c *Chunk) column(id Vec3) (int, int) {
+	return int(id.X - c.id.X*ChunkWidth), int(id.Z - c.id.Z*ChunkWidth)
 }
 
 func (c *Chunk) RangeBlocks(f func(id Vec3, w *Block)) {
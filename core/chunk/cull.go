@@ -0,0 +1,57 @@
+package chunk
+
+import (
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+// sectionStep is a section reached by the visibility traversal
+type sectionStep struct {
+	id   Vec3
+	from int   // face the section was entered through, -1 for the start
+	dirs uint8 // directions taken to get here
+}
+
+// visibleSections walks the sections that can be seen from section start,
+// breadth first so the result is ordered near to far. A section is only
+// left through faces its visibility connects to the face it was entered
+// through, and the walk never turns back towards the camera. vis returns
+// the visibility of a section and inView tells if a section passes the
+// frustum, sections farther than radius chunks from start are skipped
+func visibleSections(start Vec3, radius int, vis func(id Vec3) Visibility, inView func(id Vec3) bool) []Vec3 {
+	if start.Y < 0 {
+		start.Y = 0
+	}
+	if start.Y >= SectionCount {
+		start.Y = SectionCount - 1
+	}
+
+	visited := map[Vec3]bool{start: true}
+	queue := []sectionStep{{id: start, from: -1}}
+	var sections []Vec3
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		sections = append(sections, s.id)
+
+		v := vis(s.id)
+		for d := range cubeFaces {
+			if s.dirs&(1<<uint(oppositeFace(d))) != 0 {
+				continue
+			}
+			if s.from >= 0 && !v.Connected(s.from, d) {
+				continue
+			}
+			n := s.id.Add(cubeFaces[d].normal)
+			dx, dz := int(n.X-start.X), int(n.Z-start.Z)
+			if n.Y < 0 || n.Y >= SectionCount || dx*dx+dz*dz > radius*radius {
+				continue
+			}
+			if visited[n] || !inView(n) {
+				continue
+			}
+			visited[n] = true
+			queue = append(queue, sectionStep{id: n, from: oppositeFace(d), dirs: s.dirs | 1<<uint(d)})
+		}
+	}
+	return sections
+}
@@ -0,0 +1,107 @@
+package chunk
+
+import (
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Box is an axis aligned bounding box in world coordinates
+type Box struct {
+	Min, Max mgl32.Vec3
+}
+
+// Center returns the point in the middle of the box
+func (b Box) Center() mgl32.Vec3 {
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+// extend grows the box to hold p, an empty box becomes a box around p
+func (b *Box) extend(p mgl32.Vec3, empty bool) {
+	if empty {
+		b.Min, b.Max = p, p
+		return
+	}
+	for i := 0; i < 3; i++ {
+		b.Min[i] = Min(b.Min[i], p[i])
+		b.Max[i] = Max(b.Max[i], p[i])
+	}
+}
+
+// sectionBox returns the space taken by section id, blocks are
+// centered on whole coordinates so it starts half a block lower
+func sectionBox(id Vec3) Box {
+	min := mgl32.Vec3{id.X*ChunkWidth - 0.5, id.Y*SectionHeight - 0.5, id.Z*ChunkWidth - 0.5}
+	return Box{Min: min, Max: min.Add(mgl32.Vec3{ChunkWidth, SectionHeight, ChunkWidth})}
+}
+
+// columnBox returns the space taken by chunk id over the whole world height
+func columnBox(id Vec3) Box {
+	b := sectionBox(Vec3{X: id.X, Z: id.Z})
+	b.Max[1] = WorldHeight - 0.5
+	return b
+}
+
+// meshBounds returns the box around the vertices of all layers of data
+func meshBounds(data *MeshData) Box {
+	var b Box
+	empty := true
+	for _, layer := range data {
+		for i := 0; i+vertexSize <= len(layer); i += vertexSize {
+			b.extend(mgl32.Vec3{layer[i], layer[i+1], layer[i+2]}, empty)
+			empty = false
+		}
+	}
+	return b
+}
+
+// Frustum is the six planes around what a projection shows, left, right,
+// bottom, top, near and far. A point p is inside a plane when
+// plane.Dot(p.Vec4(1)) >= 0
+type Frustum [6]mgl32.Vec4
+
+// NewFrustum extracts the planes of the view frustum from mat, the
+// projection times the camera matrix, so the planes are in world space
+func NewFrustum(mat mgl32.Mat4) Frustum {
+	r1, r2, r3, r4 := mat.Rows()
+	f := Frustum{
+		r4.Add(r1), // left
+		r4.Sub(r1), // right
+		r4.Add(r2), // bottom
+		r4.Sub(r2), // top
+		r4.Add(r3), // near
+		r4.Sub(r3), // far
+	}
+	for i, p := range f {
+		f[i] = p.Mul(1 / p.Vec3().Len())
+	}
+	return f
+}
+
+// ContainsPoint tells if p is inside all planes
+func (f *Frustum) ContainsPoint(p mgl32.Vec3) bool {
+	for _, plane := range f {
+		if plane.Dot(p.Vec4(1)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsBox tells if b is at least partly inside the frustum. Boxes
+// near a corner of the frustum may pass without being inside, which
+// only costs drawing them
+func (f *Frustum) IntersectsBox(b Box) bool {
+	for _, plane := range f {
+		// the corner of the box farthest along the plane normal
+		p := b.Min
+		for i := 0; i < 3; i++ {
+			if plane[i] > 0 {
+				p[i] = b.Max[i]
+			}
+		}
+		if plane.Dot(p.Vec4(1)) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// testFrustum looks down -z from the origin, seeing from 1 to 100 blocks
+func testFrustum() Frustum {
+	mat := mgl32.Perspective(Radian(90), 1, 1, 100)
+	return NewFrustum(mat.Mul4(mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, 1, 0})))
+}
+
+func TestFrustumPlanesFollowTheProjection(t *testing.T) {
+	f := testFrustum()
+
+	// normalized planes give the distance to the plane
+	near, far := f[4], f[5]
+	assert.InDelta(t, 1, near.Vec3().Len(), 1e-5)
+	assert.InDelta(t, 4, near.Dot(mgl32.Vec4{0, 0, -5, 1}), 1e-3)
+	assert.InDelta(t, 95, far.Dot(mgl32.Vec4{0, 0, -5, 1}), 1e-3)
+	// a 90 degree field of view has its side planes at 45 degrees
+	left := f[0]
+	assert.InDelta(t, 0, left.Dot(mgl32.Vec4{-10, 0, -10, 1}), 1e-3)
+
+	assert.True(t, f.ContainsPoint(mgl32.Vec3{0, 0, -50}))
+	assert.True(t, f.ContainsPoint(mgl32.Vec3{9, -9, -10}))
+	assert.False(t, f.ContainsPoint(mgl32.Vec3{0, 0, -0.5}))
+	assert.False(t, f.ContainsPoint(mgl32.Vec3{0, 0, -101}))
+	assert.False(t, f.ContainsPoint(mgl32.Vec3{0, 0, 10}))
+	assert.False(t, f.ContainsPoint(mgl32.Vec3{11, 0, -10}))
+}
+
+func TestFrustumFollowsRenderRadius(t *testing.T) {
+	for _, radius := range []float32{64, 512} {
+		mat := mgl32.Perspective(Radian(45), 1, 0.01, radius)
+		f := NewFrustum(mat)
+
+		assert.True(t, f.ContainsPoint(mgl32.Vec3{0, 0, -(radius - 1)}), "radius %v", radius)
+		assert.False(t, f.ContainsPoint(mgl32.Vec3{0, 0, -(radius + 1)}), "radius %v", radius)
+	}
+}
+
+func TestFrustumIntersectsBox(t *testing.T) {
+	f := testFrustum()
+	box := func(x, y, z, size float32) Box {
+		return Box{Min: mgl32.Vec3{x, y, z}, Max: mgl32.Vec3{x + size, y + size, z + size}}
+	}
+
+	assert.True(t, f.IntersectsBox(box(-1, -1, -20, 2)))
+	// partly inside, crossing the right plane
+	assert.True(t, f.IntersectsBox(box(9, 0, -10, 4)))
+	// around the camera
+	assert.True(t, f.IntersectsBox(box(-5, -5, -5, 10)))
+	// holding the whole frustum
+	assert.True(t, f.IntersectsBox(box(-500, -500, -500, 1000)))
+
+	assert.False(t, f.IntersectsBox(box(-1, -1, 5, 2)))
+	assert.False(t, f.IntersectsBox(box(-1, -1, -110, 2)))
+	assert.False(t, f.IntersectsBox(box(20, 0, -10, 4)))
+	assert.False(t, f.IntersectsBox(box(0, -30, -10, 4)))
+}
+
+func TestSectionBoxes(t *testing.T) {
+	b := sectionBox(Vec3{X: 1, Y: 2, Z: -1})
+	assert.Equal(t, mgl32.Vec3{ChunkWidth - 0.5, 2*SectionHeight - 0.5, -ChunkWidth - 0.5}, b.Min)
+	assert.Equal(t, mgl32.Vec3{2*ChunkWidth - 0.5, 3*SectionHeight - 0.5, -0.5}, b.Max)
+
+	c := columnBox(Vec3{X: 1, Z: -1})
+	assert.Equal(t, float32(-0.5), c.Min.Y())
+	assert.Equal(t, float32(WorldHeight-0.5), c.Max.Y())
+}
+
+func TestMeshBoundsAreTight(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	w := testWorld{
+		{X: 2, Y: 3, Z: 4}: stone,
+		{X: 5, Y: 8, Z: 4}: stone,
+	}
+
+	data := meshChunk(&Mesher{}, w, w)
+
+	assert.Equal(t, Box{Min: mgl32.Vec3{1.5, 2.5, 3.5}, Max: mgl32.Vec3{5.5, 8.5, 4.5}}, data.Bounds[0])
+	assert.Equal(t, Box{}, data.Bounds[1])
+}
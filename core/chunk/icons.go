@@ -0,0 +1,95 @@
+package chunk
+
+import (
+	"image"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// iconTilt turned towards the camera and a quarter turn show the
+	// top and two sides of a block, like an isometric drawing
+	iconTilt = 30
+	iconTurn = 45
+	// iconExtent is half the size of the view, a tilted block fits
+	iconExtent = 0.82
+)
+
+// iconMatrix is the projection and turn of a block drawn as an icon
+func iconMatrix() mgl32.Mat4 {
+	mat := mgl32.Ortho2D(-iconExtent, iconExtent, -iconExtent, iconExtent)
+	mat = mat.Mul4(mgl32.HomogRotate3DX(Radian(iconTilt)))
+	return mat.Mul4(mgl32.HomogRotate3DY(Radian(iconTurn)))
+}
+
+// ItemIcons draws each block of ids on a transparent background, as
+// seen from above at an angle. Unknown blocks have a nil icon
+func (r *ChunkRenderer) ItemIcons(ids []string, size int) ([]*image.NRGBA, error) {
+	icons := make([]*image.NRGBA, len(ids))
+	var err error
+	mainthread.Call(func() {
+		var fbo *types.Framebuffer
+		fbo, err = types.NewFramebuffer(size, size)
+		if err != nil {
+			return
+		}
+		defer fbo.Release()
+		fbo.Begin()
+		defer fbo.End()
+
+		r.shader.Begin()
+		r.texture.Begin()
+		defer r.shader.End()
+		defer r.texture.End()
+		r.shader.SetUniformAttr(0, iconMatrix())
+		r.shader.SetUniformAttr(1, mgl32.Vec3{0, 0, 0})
+		r.shader.SetUniformAttr(2, float32(1000))
+		r.shader.SetUniformAttr(3, float32(1))
+		r.shader.SetUniformAttr(4, mgl32.Vec3{-1, 1, -1}.Normalize())
+		r.shader.SetUniformAttr(5, mgl32.Vec3{0, 0, 0})
+		r.shader.SetUniformAttr(6, int32(block.LayerTranslucent))
+
+		gl.Enable(gl.BLEND)
+		defer gl.Disable(gl.BLEND)
+		// the alpha of translucent faces is kept in the icon
+		gl.BlendFuncSeparate(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA, gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+		defer gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+		gl.ClearColor(0, 0, 0, 0)
+		for i, id := range ids {
+			b := block.GetBlock(id)
+			if b == nil {
+				continue
+			}
+			gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+			mesh := types.NewMesh(r.shader, r.mesher.Item(b, nil))
+			mesh.Render()
+			mesh.Release()
+			icons[i] = unpremultiply(fbo.ImageAlpha())
+		}
+	})
+	return icons, err
+}
+
+// unpremultiply undoes the darkening of translucent pixels blended
+// onto the black background
+func unpremultiply(img *image.NRGBA) *image.NRGBA {
+	for i := 0; i < len(img.Pix); i += 4 {
+		a := int(img.Pix[i+3])
+		if a == 0 || a == 255 {
+			continue
+		}
+		for c := i; c < i+3; c++ {
+			v := int(img.Pix[c]) * 255 / a
+			if v > 255 {
+				v = 255
+			}
+			img.Pix[c] = uint8(v)
+		}
+	}
+	return img
+}
@@ -0,0 +1,40 @@
+package chunk
+
+import (
+	"image"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/offscreen"
+	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/faiface/mainthread"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemIcons(t *testing.T) {
+	var err error
+	mainthread.Call(func() {
+		err = offscreen.Init()
+	})
+	if err != nil {
+		t.Skip(err)
+	}
+
+	path := *texture.TexturePath
+	*texture.TexturePath = "../../texture.png"
+	defer func() { *texture.TexturePath = path }()
+	r, err := NewChunkRenderer(nil)
+	require.NoError(t, err)
+
+	ids := []string{block.GrassBlockID, block.GlassID, block.DandelionID, block.WaterID, "core:unknown", block.LampID}
+	icons, err := r.ItemIcons(ids, 32)
+	require.NoError(t, err)
+	require.Len(t, icons, len(ids))
+	assert.Nil(t, icons[4], "unknown blocks have no icon")
+
+	grass := icons[0]
+	assert.Equal(t, image.Rect(0, 0, 32, 32), grass.Rect)
+	assert.EqualValues(t, 255, grass.NRGBAAt(16, 16).A, "the block fills the middle")
+	assert.EqualValues(t, 0, grass.NRGBAAt(0, 0).A, "the corners are transparent")
+}
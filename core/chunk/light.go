@@ -0,0 +1,114 @@
+package chunk
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+// LightMap is the part of the world the block light flood fill works on
+type LightMap interface {
+	Block(id Vec3) *block.Block
+	BlockLight(id Vec3) uint8
+	SetBlockLight(id Vec3, l uint8)
+}
+
+// lightNode is a position queued for light removal, with the
+// light level it had before it was cleared
+type lightNode struct {
+	id    Vec3
+	level uint8
+}
+
+// RelightBlock updates the block light around id after the block at
+// id changed. Light the old block emitted or let through is removed,
+// then the new block's own light and the light of its neighbors are
+// flooded back in
+func RelightBlock(m LightMap, id Vec3) {
+	RelightBlocks(m, []Vec3{id})
+}
+
+// RelightBlocks updates the block light after the blocks at ids
+// changed, like RelightBlock, flooding the light back in once for all
+// of them
+func RelightBlocks(m LightMap, ids []Vec3) {
+	var spread []Vec3
+	for _, id := range ids {
+		if l := m.BlockLight(id); l > 0 {
+			spread = append(spread, removeLight(m, id, l)...)
+		}
+	}
+
+	for _, id := range ids {
+		w := m.Block(id)
+		switch {
+		case w.LightLevel > 0:
+			m.SetBlockLight(id, w.LightLevel)
+			spread = append(spread, id)
+		case !w.Opaque():
+			for _, n := range lightNeighbors(id) {
+				if m.BlockLight(n) > 1 {
+					spread = append(spread, n)
+				}
+			}
+		}
+	}
+
+	SpreadLight(m, spread)
+}
+
+// SpreadLight floods the light of the given positions outwards, each
+// step through a non opaque block loses one light level
+func SpreadLight(m LightMap, ids []Vec3) {
+	queue := ids
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		l := m.BlockLight(id)
+		if l <= 1 {
+			continue
+		}
+		for _, n := range lightNeighbors(id) {
+			if n.Y < 0 || m.BlockLight(n) >= l-1 || m.Block(n).Opaque() {
+				continue
+			}
+			m.SetBlockLight(n, l-1)
+			queue = append(queue, n)
+		}
+	}
+}
+
+// removeLight clears the light at id and all the light that came from it,
+// returning the positions that are lit by some other source and have to
+// be spread again to fill the hole
+func removeLight(m LightMap, id Vec3, level uint8) (spread []Vec3) {
+	m.SetBlockLight(id, 0)
+	queue := []lightNode{{id: id, level: level}}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, n := range lightNeighbors(node.id) {
+			l := m.BlockLight(n)
+			switch {
+			case l == 0:
+			case l < node.level:
+				m.SetBlockLight(n, 0)
+				queue = append(queue, lightNode{id: n, level: l})
+				// other emitters keep their own light
+				if e := m.Block(n).LightLevel; e > 0 {
+					m.SetBlockLight(n, e)
+					spread = append(spread, n)
+				}
+			default:
+				spread = append(spread, n)
+			}
+		}
+	}
+	return spread
+}
+
+func lightNeighbors(id Vec3) [6]Vec3 {
+	return [6]Vec3{id.Left(), id.Right(), id.Up(), id.Down(), id.Front(), id.Back()}
+}
@@ -0,0 +1,137 @@
+package chunk
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// testLightMap is a LightMap backed by plain maps
+type testLightMap struct {
+	testWorld
+	lights map[Vec3]uint8
+}
+
+func newTestLightMap() *testLightMap {
+	return &testLightMap{
+		testWorld: testWorld{},
+		lights:    map[Vec3]uint8{},
+	}
+}
+
+func (m *testLightMap) BlockLight(id Vec3) uint8 {
+	return m.lights[id]
+}
+
+func (m *testLightMap) SetBlockLight(id Vec3, l uint8) {
+	if l == 0 {
+		delete(m.lights, id)
+		return
+	}
+	m.lights[id] = l
+}
+
+func (m *testLightMap) set(id Vec3, w *block.Block) {
+	m.testWorld[id] = w
+	RelightBlock(m, id)
+}
+
+func TestPlacingTorchFloodsLight(t *testing.T) {
+	m := newTestLightMap()
+	torch := block.GetBlock(block.TorchID)
+	pos := Vec3{X: 0, Y: 10, Z: 0}
+
+	m.set(pos, torch)
+
+	assert.Equal(t, torch.LightLevel, m.BlockLight(pos))
+	assert.Equal(t, torch.LightLevel-1, m.BlockLight(pos.Up()))
+	assert.Equal(t, torch.LightLevel-3, m.BlockLight(Vec3{X: 1, Y: 11, Z: 1}))
+	assert.Equal(t, uint8(1), m.BlockLight(Vec3{X: float32(torch.LightLevel - 1), Y: 10}))
+	assert.Zero(t, m.BlockLight(Vec3{X: float32(torch.LightLevel), Y: 10}))
+}
+
+func TestOpaqueBlocksStopLight(t *testing.T) {
+	m := newTestLightMap()
+	stone := block.GetBlock(block.StoneID)
+	pos := Vec3{X: 0, Y: 10, Z: 0}
+	m.set(pos, block.GetBlock(block.LampID))
+
+	// placing a wall next to the lamp darkens it and the light goes around it
+	m.set(pos.Right(), stone)
+	assert.Zero(t, m.BlockLight(pos.Right()))
+	assert.Equal(t, uint8(11), m.BlockLight(pos.Right().Right()))
+}
+
+func TestBreakingTorchRemovesLight(t *testing.T) {
+	m := newTestLightMap()
+	torch := block.GetBlock(block.TorchID)
+	pos := Vec3{X: 0, Y: 10, Z: 0}
+
+	m.set(pos, torch)
+	m.set(pos, block.GetBlock(block.AirID))
+
+	assert.Empty(t, m.lights)
+}
+
+func TestBreakingOneOfTwoLightsKeepsTheOther(t *testing.T) {
+	m := newTestLightMap()
+	torch := block.GetBlock(block.TorchID)
+	lamp := block.GetBlock(block.LampID)
+	a, b := Vec3{X: 0, Y: 10}, Vec3{X: 6, Y: 10}
+
+	m.set(a, torch)
+	m.set(b, lamp)
+	m.set(a, block.GetBlock(block.AirID))
+
+	assert.Equal(t, lamp.LightLevel, m.BlockLight(b))
+	assert.Equal(t, lamp.LightLevel-6, m.BlockLight(a))
+	assert.Equal(t, lamp.LightLevel-7, m.BlockLight(a.Left()))
+
+	// everything equals the distance to the remaining lamp
+	for id, l := range m.lights {
+		d := Abs(id.X-b.X) + Abs(id.Y-b.Y) + Abs(id.Z-b.Z)
+		assert.Equal(t, lamp.LightLevel-uint8(d), l, "%v", id)
+	}
+}
+
+func TestBreakingWallLetsLightThrough(t *testing.T) {
+	m := newTestLightMap()
+	stone := block.GetBlock(block.StoneID)
+	pos := Vec3{X: 0, Y: 10, Z: 0}
+	m.testWorld[pos.Right()] = stone
+	m.set(pos, block.GetBlock(block.LampID))
+
+	m.set(pos.Right(), block.GetBlock(block.AirID))
+
+	assert.Equal(t, uint8(14), m.BlockLight(pos.Right()))
+	assert.Equal(t, uint8(13), m.BlockLight(pos.Right().Right()))
+}
+
+func TestRelightBlocksLikeOneByOne(t *testing.T) {
+	torch := block.GetBlock(block.TorchID)
+	stone := block.GetBlock(block.StoneID)
+	lamp := block.GetBlock(block.LampID)
+	one, many := newTestLightMap(), newTestLightMap()
+	for _, m := range []*testLightMap{one, many} {
+		m.set(Vec3{X: 0, Y: 10}, torch)
+		m.set(Vec3{X: 8, Y: 10}, lamp)
+	}
+
+	// a wall of stone between the lights, with a lamp in it
+	var ids []Vec3
+	for y := float32(8); y <= 12; y++ {
+		for z := float32(-2); z <= 2; z++ {
+			id := Vec3{X: 4, Y: y, Z: z}
+			w := stone
+			if y == 10 && z == 0 {
+				w = lamp
+			}
+			one.set(id, w)
+			many.testWorld[id] = w
+			ids = append(ids, id)
+		}
+	}
+	RelightBlocks(many, ids)
+	assert.Equal(t, one.lights, many.lights)
+}
@@ -1,8 +1,152 @@
 package chunk
 
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/glhf"
+	"github.com/go-gl/mathgl/mgl32"
+	"sort"
+)
+
+// resortDistance is how far the camera has to move before
+// the translucent faces of a chunk are sorted again
+const resortDistance = 1
+
+// quadSize is the number of floats of a quad, two triangles
+const quadSize = 6 * vertexSize
+
+// Mesh is the geometry of a chunk, split into sections
 type Mesh struct {
-	vao     uint32
-	vbos    map[uint8]uint32
-	faces   int
-	visible bool
+	Id    Vec3
+	Dirty bool
+
+	sections [SectionCount]*Section
+}
+
+// Section is the geometry of one section of a chunk, with one GPU mesh
+// per render layer, and the faces of the section that see each other
+type Section struct {
+	Id         Vec3 // chunk id with Y set to the section index
+	Bounds     Box  // box around the faces of the section
+	Visibility Visibility
+
+	layers [block.LayerCount]*types.Mesh
+
+	// translucent is a copy of the translucent vertices,
+	// reordered back to front when the camera moves
+	translucent []float32
+	sortedFrom  mgl32.Vec3
+	sorted      bool
+}
+
+// newMesh uploads data to the GPU, must be called on the main thread
+func newMesh(id Vec3, shader *glhf.Shader, data *ChunkData) *Mesh {
+	m := &Mesh{Id: id}
+	for i := range m.sections {
+		s := newSection(Vec3{X: id.X, Y: float32(i), Z: id.Z}, shader, &data.Sections[i])
+		s.Bounds = data.Bounds[i]
+		s.Visibility = data.Visibility[i]
+		m.sections[i] = s
+	}
+	return m
+}
+
+func newSection(id Vec3, shader *glhf.Shader, data *MeshData) *Section {
+	s := &Section{Id: id}
+	for i := range s.layers {
+		s.layers[i] = types.NewMesh(shader, data[i])
+	}
+	s.translucent = append([]float32(nil), data[block.LayerTranslucent]...)
+	return s
+}
+
+// Section returns section i of the mesh
+func (m *Mesh) Section(i int) *Section {
+	return m.sections[i]
+}
+
+// Faces returns the number of faces in all sections
+func (m *Mesh) Faces() int {
+	var n int
+	for _, s := range m.sections {
+		n += s.Faces()
+	}
+	return n
+}
+
+func (m *Mesh) Release() {
+	for _, s := range m.sections {
+		s.Release()
+	}
+}
+
+// Faces returns the number of faces in all layers
+func (s *Section) Faces() int {
+	var n int
+	for _, l := range s.layers {
+		n += l.Faces()
+	}
+	return n
+}
+
+// Render draws a single layer of the section
+func (s *Section) Render(layer block.Layer) {
+	s.layers[layer].Render()
+}
+
+// Center returns the center of the faces of the section in world coordinates
+func (s *Section) Center() mgl32.Vec3 {
+	return s.Bounds.Center()
+}
+
+// SortTranslucent orders the translucent faces back to front as seen
+// from camera, when it moved far enough since the last sort
+func (s *Section) SortTranslucent(camera mgl32.Vec3) {
+	if len(s.translucent) == 0 {
+		return
+	}
+	if s.sorted && camera.Sub(s.sortedFrom).Len() < resortDistance {
+		return
+	}
+	sortBackToFront(s.translucent, camera)
+	s.layers[block.LayerTranslucent].Update(s.translucent)
+	s.sortedFrom = camera
+	s.sorted = true
+}
+
+func (s *Section) Release() {
+	for _, l := range s.layers {
+		l.Release()
+	}
+}
+
+// sortBackToFront reorders the quads of data by the distance
+// of their centers to camera, farthest first
+func sortBackToFront(data []float32, camera mgl32.Vec3) {
+	n := len(data) / quadSize
+	dist := make([]float32, n)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+		dist[i] = quadCenter(data[i*quadSize:]).Sub(camera).LenSqr()
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return dist[order[i]] > dist[order[j]]
+	})
+
+	sorted := make([]float32, 0, n*quadSize)
+	for _, i := range order {
+		sorted = append(sorted, data[i*quadSize:(i+1)*quadSize]...)
+	}
+	copy(data, sorted)
+}
+
+func quadCenter(quad []float32) mgl32.Vec3 {
+	var c mgl32.Vec3
+	for v := 0; v < 6; v++ {
+		p := quad[v*vertexSize:]
+		c = c.Add(mgl32.Vec3{p[0], p[1], p[2]})
+	}
+	return c.Mul(1.0 / 6)
 }
@@ -0,0 +1,431 @@
+package chunk
+
+import (
+	"flag"
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/item"
+	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+var (
+	AmbientOcclusion = flag.Bool("ao", true, "ambient occlusion")
+	SmoothLighting   = flag.Bool("smooth", true, "smooth lighting")
+)
+
+// vertexSize is the number of floats the Mesher writes per vertex,
+// it has to match blockVertexFormat
+const vertexSize = 11
+
+// aoCurve maps the ambient occlusion level of a vertex (0 = fully
+// occluded, 3 = no occluding neighbors) to a brightness factor
+var aoCurve = [4]float32{0.45, 0.65, 0.82, 1}
+
+// BlockSource is the part of the world the Mesher reads blocks from
+type BlockSource interface {
+	Block(id Vec3) *block.Block
+}
+
+// LightSource may be implemented by a BlockSource to tell the Mesher
+// how much sky light and block light (both 0-1) reach a block position.
+// Without it every position is lit by the sky only
+type LightSource interface {
+	Light(id Vec3) (sky, torch float32)
+}
+
+// light is the sky and block light of a position or face corner
+type light struct {
+	sky, torch float32
+}
+
+func (l light) sum() float32 {
+	return l.sky + l.torch
+}
+
+// Mesher turns the blocks of a chunk into vertex data for the block shader.
+// Every vertex is laid out as pos(3), tex(2), normal(3), ao(1), sky(1), torch(1)
+type Mesher struct {
+	// AO enables per vertex ambient occlusion computed from
+	// the three blocks touching each face corner
+	AO bool
+	// Smooth averages the light of the blocks around each face corner,
+	// instead of using the light of the block in front of the face
+	Smooth bool
+}
+
+// NewMesher creates a Mesher configured from the command line flags
+func NewMesher() *Mesher {
+	return &Mesher{
+		AO:     *AmbientOcclusion,
+		Smooth: *SmoothLighting,
+	}
+}
+
+// quadFace describes one side of a block as a quad
+type quadFace struct {
+	normal  Vec3
+	corners [4]Vec3 // offsets from the block center, in counter-clockwise order
+	texture func(tex *texture.BlockTexture) texture.FaceTexture
+}
+
+const (
+	faceLeft = iota
+	faceRight
+	faceUp
+	faceDown
+	faceFront
+	faceBack
+)
+
+var cubeFaces = [6]quadFace{
+	faceLeft: {
+		normal:  Vec3{X: -1},
+		corners: [4]Vec3{vec(-0.5, -0.5, -0.5), vec(-0.5, -0.5, 0.5), vec(-0.5, 0.5, 0.5), vec(-0.5, 0.5, -0.5)},
+		texture: func(tex *texture.BlockTexture) texture.FaceTexture { return tex.Left },
+	},
+	faceRight: {
+		normal:  Vec3{X: 1},
+		corners: [4]Vec3{vec(0.5, -0.5, 0.5), vec(0.5, -0.5, -0.5), vec(0.5, 0.5, -0.5), vec(0.5, 0.5, 0.5)},
+		texture: func(tex *texture.BlockTexture) texture.FaceTexture { return tex.Right },
+	},
+	faceUp: {
+		normal:  Vec3{Y: 1},
+		corners: [4]Vec3{vec(-0.5, 0.5, 0.5), vec(0.5, 0.5, 0.5), vec(0.5, 0.5, -0.5), vec(-0.5, 0.5, -0.5)},
+		texture: func(tex *texture.BlockTexture) texture.FaceTexture { return tex.Up },
+	},
+	faceDown: {
+		normal:  Vec3{Y: -1},
+		corners: [4]Vec3{vec(-0.5, -0.5, -0.5), vec(0.5, -0.5, -0.5), vec(0.5, -0.5, 0.5), vec(-0.5, -0.5, 0.5)},
+		texture: func(tex *texture.BlockTexture) texture.FaceTexture { return tex.Down },
+	},
+	faceFront: {
+		normal:  Vec3{Z: 1},
+		corners: [4]Vec3{vec(-0.5, -0.5, 0.5), vec(0.5, -0.5, 0.5), vec(0.5, 0.5, 0.5), vec(-0.5, 0.5, 0.5)},
+		texture: func(tex *texture.BlockTexture) texture.FaceTexture { return tex.Front },
+	},
+	faceBack: {
+		normal:  Vec3{Z: -1},
+		corners: [4]Vec3{vec(0.5, -0.5, -0.5), vec(-0.5, -0.5, -0.5), vec(-0.5, 0.5, -0.5), vec(0.5, 0.5, -0.5)},
+		texture: func(tex *texture.BlockTexture) texture.FaceTexture { return tex.Back },
+	},
+}
+
+// plantFaces are the crossed quads of plants, they are the vertical
+// cube faces moved into the center of the block
+var plantFaces = func() (faces [4]quadFace) {
+	for i, side := range []int{faceLeft, faceRight, faceFront, faceBack} {
+		faces[i] = cubeFaces[side]
+		for j := range faces[i].corners {
+			c := &faces[i].corners[j]
+			if faces[i].normal.X != 0 {
+				c.X = 0
+			} else {
+				c.Z = 0
+			}
+		}
+	}
+	return faces
+}()
+
+// Triangle orders for a quad, split along the 0-2 or the 1-3 diagonal
+var (
+	quadOrder        = [6]int{0, 1, 2, 2, 3, 0}
+	flippedQuadOrder = [6]int{1, 2, 3, 3, 0, 1}
+)
+
+// MeshData is the vertex data of a section, one slice per render layer
+type MeshData [block.LayerCount][]float32
+
+// Reset empties all layers, keeping their memory
+func (d *MeshData) Reset() {
+	for i := range d {
+		d[i] = d[i][:0]
+	}
+}
+
+// ChunkData is the vertex data, bounds and visibility of every section of a chunk
+type ChunkData struct {
+	Sections   [SectionCount]MeshData
+	Bounds     [SectionCount]Box
+	Visibility [SectionCount]Visibility
+}
+
+// Reset empties all sections, keeping their memory
+func (d *ChunkData) Reset() {
+	for i := range d.Sections {
+		d.Sections[i].Reset()
+	}
+}
+
+// meshBuilder holds the state of a single Mesh call
+type meshBuilder struct {
+	*Mesher
+	blocks BlockSource
+	lights LightSource
+	data   *MeshData
+	layer  block.Layer // layer of the block being added
+}
+
+// Mesh appends the vertices of all visible faces in chunk c to the layers
+// of the sections of data, neighbor blocks (also outside of c) are looked
+// up in w. It also computes which faces of each section see each other
+func (m *Mesher) Mesh(c types.IChunk, w BlockSource, data *ChunkData) {
+	b := m.builder(w, nil)
+	c.RangeBlocks(func(pos Vec3, tp *block.Block) {
+		if tp == nil || tp.ID == block.AirID || !tp.Visible {
+			return
+		}
+		b.data = &data.Sections[sectionIndex(pos.Y)]
+		b.addBlock(pos, tp)
+	})
+	for i := range data.Sections {
+		data.Bounds[i] = meshBounds(&data.Sections[i])
+	}
+	data.Visibility = sectionVisibility(c)
+}
+
+// Item appends the vertices of a single, unoccluded block at origin,
+// used for rendering the block held by the player
+func (m *Mesher) Item(w *block.Block, vertices []float32) []float32 {
+	var data MeshData
+	m.builder(airSource{}, &data).addBlock(Vec3{}, w)
+	for _, layer := range data {
+		vertices = append(vertices, layer...)
+	}
+	return vertices
+}
+
+func (m *Mesher) builder(w BlockSource, data *MeshData) *meshBuilder {
+	b := &meshBuilder{
+		Mesher: m,
+		blocks: w,
+		data:   data,
+	}
+	b.lights, _ = w.(LightSource)
+	return b
+}
+
+func (b *meshBuilder) addBlock(pos Vec3, w *block.Block) {
+	tex := item.Tex.Texture(w.ID)
+	b.layer = w.Layer()
+
+	if w.Liquid {
+		b.addLiquid(pos, w, tex)
+		return
+	}
+
+	if w.Plant {
+		l := b.light(pos)
+		for i := range plantFaces {
+			b.addQuad(pos, &plantFaces[i], tex, [4]float32{1, 1, 1, 1}, [4]light{l, l, l, l})
+		}
+		return
+	}
+
+	for i := range cubeFaces {
+		f := &cubeFaces[i]
+		neighbor := b.blocks.Block(pos.Add(f.normal))
+		show := neighbor.Transparent || !neighbor.Visible
+		if i == faceDown {
+			show = (pos.Y > 0 && neighbor.Transparent) || !neighbor.Visible
+		}
+		// no faces between two blocks of the same translucent kind
+		if w.Translucent && sameKind(w, neighbor) {
+			show = false
+		}
+		if show {
+			b.addFace(pos, f, tex)
+		}
+	}
+}
+
+// addLiquid adds the faces of a liquid block, the top corners are lowered
+// to the liquid surface, which slopes towards the lower levels around it
+func (b *meshBuilder) addLiquid(pos Vec3, w *block.Block, tex *texture.BlockTexture) {
+	l := b.light(pos)
+	lights := [4]light{l, l, l, l}
+	ao := [4]float32{1, 1, 1, 1}
+
+	for i := range cubeFaces {
+		neighbor := b.blocks.Block(pos.Add(cubeFaces[i].normal))
+		if sameKind(w, neighbor) || (i != faceUp && neighbor.Opaque()) {
+			continue
+		}
+
+		f := cubeFaces[i]
+		for j, c := range f.corners {
+			if c.Y > 0 {
+				f.corners[j].Y = b.liquidHeight(pos, w, c) - 0.5
+			}
+		}
+		b.addQuad(pos, &f, tex, ao, lights)
+
+		// the surface of see-through liquids is also seen from below
+		if i == faceUp && w.Translucent {
+			under := f
+			under.normal = Vec3{Y: -1}
+			for j := range f.corners {
+				under.corners[j] = f.corners[3-j]
+			}
+			b.addQuad(pos, &under, tex, ao, lights)
+		}
+	}
+}
+
+// liquidHeight returns the height of the liquid surface at a top corner of
+// the liquid block at pos, averaged over the blocks sharing the corner
+func (b *meshBuilder) liquidHeight(pos Vec3, w *block.Block, corner Vec3) float32 {
+	var sum, n float32
+	sx, sz := sign(corner.X), sign(corner.Z)
+	for _, id := range []Vec3{pos, pos.Add(Vec3{X: sx}), pos.Add(Vec3{Z: sz}), pos.Add(Vec3{X: sx, Z: sz})} {
+		if b.blocks.Block(id.Up()).SameFluid(w) {
+			return 1
+		}
+		if other := b.blocks.Block(id); other.SameFluid(w) {
+			sum += other.Height()
+			n++
+		}
+	}
+	return sum / n
+}
+
+// addFace computes ambient occlusion and light for each corner of
+// the face and appends it as a quad
+func (b *meshBuilder) addFace(pos Vec3, f *quadFace, tex *texture.BlockTexture) {
+	front := pos.Add(f.normal)
+	flat := b.light(front)
+
+	ao := [4]float32{1, 1, 1, 1}
+	lights := [4]light{flat, flat, flat, flat}
+
+	if b.AO || b.Smooth {
+		for i, corner := range f.corners {
+			s1, s2 := cornerSides(f.normal, corner)
+			side1, side2 := front.Add(s1), front.Add(s2)
+			diagonal := side1.Add(s2)
+			o1, o2, oc := b.opaque(side1), b.opaque(side2), b.opaque(diagonal)
+
+			if b.AO {
+				ao[i] = aoCurve[vertexAO(o1, o2, oc)]
+			}
+			if b.Smooth {
+				lights[i] = b.smoothLight(flat, side1, side2, diagonal, o1, o2, oc)
+			}
+		}
+	}
+
+	b.addQuad(pos, f, tex, ao, lights)
+}
+
+func (b *meshBuilder) addQuad(pos Vec3, f *quadFace, tex *texture.BlockTexture, ao [4]float32, lights [4]light) {
+	t := f.texture(tex)
+	uv := [4][2]float32{t[0], t[1], t[2], t[4]}
+
+	// Split the quad along the brighter diagonal, otherwise a single dark
+	// corner bleeds across the whole face
+	order := quadOrder
+	if ao[0]+lights[0].sum()+ao[2]+lights[2].sum() < ao[1]+lights[1].sum()+ao[3]+lights[3].sum() {
+		order = flippedQuadOrder
+	}
+
+	n := f.normal
+	vertices := b.data[b.layer]
+	for _, i := range order {
+		c := f.corners[i]
+		vertices = append(vertices,
+			pos.X+c.X, pos.Y+c.Y, pos.Z+c.Z,
+			uv[i][0], uv[i][1],
+			n.X, n.Y, n.Z,
+			ao[i], lights[i].sky, lights[i].torch,
+		)
+	}
+	b.data[b.layer] = vertices
+}
+
+func (b *meshBuilder) opaque(id Vec3) bool {
+	return b.blocks.Block(id).Opaque()
+}
+
+func (b *meshBuilder) light(id Vec3) light {
+	if b.lights == nil {
+		return light{sky: 1}
+	}
+	sky, torch := b.lights.Light(id)
+	return light{sky: sky, torch: torch}
+}
+
+// smoothLight averages the light in front of the face with the light of
+// the blocks touching the corner, ignoring the ones that are opaque
+func (b *meshBuilder) smoothLight(flat light, side1, side2, diagonal Vec3, o1, o2, oc bool) light {
+	sum, n := flat, float32(1)
+	add := func(id Vec3) {
+		l := b.light(id)
+		sum.sky += l.sky
+		sum.torch += l.torch
+		n++
+	}
+	if !o1 {
+		add(side1)
+	}
+	if !o2 {
+		add(side2)
+	}
+	// The diagonal block can't contribute light when both sides are closed
+	if !oc && !(o1 && o2) {
+		add(diagonal)
+	}
+	return light{sky: sum.sky / n, torch: sum.torch / n}
+}
+
+// vertexAO returns the ambient occlusion level (0-3) of a face corner
+// given which of its neighbors are opaque
+func vertexAO(side1, side2, corner bool) int {
+	if side1 && side2 {
+		return 0
+	}
+	level := 3
+	for _, o := range []bool{side1, side2, corner} {
+		if o {
+			level--
+		}
+	}
+	return level
+}
+
+// cornerSides returns the unit offsets, in the plane of a face, pointing
+// from the block in front of the face towards the two blocks that
+// touch the given corner
+func cornerSides(normal, corner Vec3) (Vec3, Vec3) {
+	switch {
+	case normal.X != 0:
+		return Vec3{Y: sign(corner.Y)}, Vec3{Z: sign(corner.Z)}
+	case normal.Y != 0:
+		return Vec3{X: sign(corner.X)}, Vec3{Z: sign(corner.Z)}
+	default:
+		return Vec3{X: sign(corner.X)}, Vec3{Y: sign(corner.Y)}
+	}
+}
+
+// sameKind tells if there is no visible surface between a and b
+func sameKind(a, b *block.Block) bool {
+	return a.ID == b.ID || a.SameFluid(b)
+}
+
+func sign(v float32) float32 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+func vec(x, y, z float32) Vec3 {
+	return Vec3{X: x, Y: y, Z: z}
+}
+
+// airSource is an empty BlockSource
+type airSource struct{}
+
+func (airSource) Block(Vec3) *block.Block {
+	return block.GetBlock(block.AirID)
+}
@@ -0,0 +1,465 @@
+package chunk
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/item"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	block.InitRegister()
+	_ = item.LoadTextureDesc()
+	// the render tests make their OpenGL calls on the main thread
+	var code int
+	mainthread.Run(func() {
+		code = m.Run()
+	})
+	os.Exit(code)
+}
+
+// testWorld is a BlockSource backed by a plain map
+type testWorld map[Vec3]*block.Block
+
+func (w testWorld) Block(id Vec3) *block.Block {
+	if b, ok := w[id]; ok {
+		return b
+	}
+	return block.GetBlock(block.AirID)
+}
+
+// litWorld adds a fixed light map to a testWorld
+type litWorld struct {
+	testWorld
+	light map[Vec3]float32
+	torch map[Vec3]float32
+}
+
+func (w litWorld) Light(id Vec3) (float32, float32) {
+	sky, ok := w.light[id]
+	if !ok {
+		sky = 1
+	}
+	return sky, w.torch[id]
+}
+
+// vertex is a single vertex read back from the mesher output
+type vertex struct {
+	pos, normal Vec3
+	ao, sky     float32
+	torch       float32
+}
+
+func vertices(data []float32) []vertex {
+	var vs []vertex
+	for i := 0; i+vertexSize <= len(data); i += vertexSize {
+		d := data[i : i+vertexSize]
+		vs = append(vs, vertex{
+			pos:    Vec3{X: d[0], Y: d[1], Z: d[2]},
+			normal: Vec3{X: d[5], Y: d[6], Z: d[7]},
+			ao:     d[8],
+			sky:    d[9],
+			torch:  d[10],
+		})
+	}
+	return vs
+}
+
+// faceVertices returns the vertices of the face of block pos with the given normal
+func faceVertices(data []float32, pos, normal Vec3) []vertex {
+	var vs []vertex
+	all := vertices(data)
+	want := mgl32.Vec3{pos.X + normal.X/2, pos.Y + normal.Y/2, pos.Z + normal.Z/2}
+	for i := 0; i+6 <= len(all); i += 6 {
+		quad := all[i : i+6]
+		if quad[0].normal != normal {
+			continue
+		}
+		if quadCenter(data[i*vertexSize:]).Sub(want).Len() < 1e-4 {
+			vs = append(vs, quad...)
+		}
+	}
+	return vs
+}
+
+// cornerAO returns the ao value of the face vertex at the given position
+func cornerAO(t *testing.T, vs []vertex, pos Vec3) float32 {
+	for _, v := range vs {
+		if v.pos == pos {
+			return v.ao
+		}
+	}
+	t.Fatalf("no vertex at %v", pos)
+	return 0
+}
+
+func meshChunk(m *Mesher, w BlockSource, blocks map[Vec3]*block.Block) *ChunkData {
+	c := NewChunk(Vec3{})
+	for id, b := range blocks {
+		c.Add(id, b)
+	}
+	data := new(ChunkData)
+	m.Mesh(c, w, data)
+	return data
+}
+
+// meshLayers returns the vertices of all sections, by layer
+func meshLayers(m *Mesher, w BlockSource, blocks map[Vec3]*block.Block) *MeshData {
+	var layers MeshData
+	for _, section := range meshChunk(m, w, blocks).Sections {
+		for i := range section {
+			layers[i] = append(layers[i], section[i]...)
+		}
+	}
+	return &layers
+}
+
+// meshWorld returns the vertices of all layers
+func meshWorld(m *Mesher, w BlockSource, blocks map[Vec3]*block.Block) []float32 {
+	var vertices []float32
+	for _, layer := range meshLayers(m, w, blocks) {
+		vertices = append(vertices, layer...)
+	}
+	return vertices
+}
+
+func TestMeshSingleBlockHasSixUnshadedFaces(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	w := testWorld{{X: 1, Y: 1, Z: 1}: stone}
+
+	data := meshWorld(&Mesher{AO: true, Smooth: true}, w, w)
+
+	vs := vertices(data)
+	assert.Len(t, vs, 6*6)
+	for _, v := range vs {
+		assert.Equal(t, float32(1), v.ao)
+		assert.Equal(t, float32(1), v.sky)
+	}
+}
+
+func TestMeshHidesFacesBetweenOpaqueBlocks(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	w := testWorld{
+		{X: 1, Y: 1, Z: 1}: stone,
+		{X: 2, Y: 1, Z: 1}: stone,
+	}
+
+	data := meshWorld(&Mesher{}, w, w)
+
+	assert.Len(t, vertices(data), 10*6)
+	assert.Empty(t, faceVertices(data, Vec3{X: 1, Y: 1, Z: 1}, Vec3{X: 1}))
+	assert.Empty(t, faceVertices(data, Vec3{X: 2, Y: 1, Z: 1}, Vec3{X: -1}))
+}
+
+func TestMeshAmbientOcclusionFromNeighbors(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := testWorld{
+		floor: stone,
+		// one side neighbor above the +x edge
+		{X: 3, Y: 2, Z: 2}: stone,
+		// a diagonal neighbor above the -x,-z corner
+		{X: 1, Y: 2, Z: 1}: stone,
+	}
+
+	data := meshWorld(&Mesher{AO: true}, w, testWorld{floor: stone})
+
+	top := faceVertices(data, floor, Vec3{Y: 1})
+	assert.Len(t, top, 6)
+	assert.Equal(t, aoCurve[2], cornerAO(t, top, Vec3{X: 2.5, Y: 1.5, Z: 2.5}))
+	assert.Equal(t, aoCurve[2], cornerAO(t, top, Vec3{X: 2.5, Y: 1.5, Z: 1.5}))
+	assert.Equal(t, aoCurve[2], cornerAO(t, top, Vec3{X: 1.5, Y: 1.5, Z: 1.5}))
+	assert.Equal(t, aoCurve[3], cornerAO(t, top, Vec3{X: 1.5, Y: 1.5, Z: 2.5}))
+}
+
+func TestMeshAmbientOcclusionInsideCorner(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := testWorld{
+		floor:              stone,
+		{X: 3, Y: 2, Z: 2}: stone,
+		{X: 2, Y: 2, Z: 3}: stone,
+	}
+
+	data := meshWorld(&Mesher{AO: true}, w, testWorld{floor: stone})
+
+	top := faceVertices(data, floor, Vec3{Y: 1})
+	// both sides are closed, the diagonal doesn't matter
+	assert.Equal(t, aoCurve[0], cornerAO(t, top, Vec3{X: 2.5, Y: 1.5, Z: 2.5}))
+	assert.Equal(t, aoCurve[2], cornerAO(t, top, Vec3{X: 2.5, Y: 1.5, Z: 1.5}))
+	assert.Equal(t, aoCurve[2], cornerAO(t, top, Vec3{X: 1.5, Y: 1.5, Z: 2.5}))
+	assert.Equal(t, aoCurve[3], cornerAO(t, top, Vec3{X: 1.5, Y: 1.5, Z: 1.5}))
+}
+
+func TestMeshAmbientOcclusionOff(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := testWorld{
+		floor:              stone,
+		{X: 3, Y: 2, Z: 2}: stone,
+		{X: 2, Y: 2, Z: 3}: stone,
+	}
+
+	data := meshWorld(&Mesher{AO: false}, w, testWorld{floor: stone})
+
+	for _, v := range faceVertices(data, floor, Vec3{Y: 1}) {
+		assert.Equal(t, float32(1), v.ao)
+	}
+}
+
+func TestMeshFlipsQuadTowardsBrighterDiagonal(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := testWorld{
+		floor: stone,
+		// darkens only the -x,-z corner, which is corner 3 of the top face
+		{X: 1, Y: 2, Z: 1}: stone,
+	}
+
+	data := meshWorld(&Mesher{AO: true}, w, testWorld{floor: stone})
+	top := faceVertices(data, floor, Vec3{Y: 1})
+	corners := cubeFaces[faceUp].corners
+	for i, v := range top {
+		assert.Equal(t, floor.Add(corners[quadOrder[i]]), v.pos)
+	}
+
+	// darkening corner 0 (-x,+z) instead makes the 0-2 diagonal the darker one
+	w = testWorld{
+		floor:              stone,
+		{X: 1, Y: 2, Z: 3}: stone,
+	}
+	data = meshWorld(&Mesher{AO: true}, w, testWorld{floor: stone})
+	top = faceVertices(data, floor, Vec3{Y: 1})
+	for i, v := range top {
+		assert.Equal(t, floor.Add(corners[flippedQuadOrder[i]]), v.pos)
+	}
+}
+
+func TestMeshSmoothLighting(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := litWorld{
+		testWorld: testWorld{floor: stone},
+		light: map[Vec3]float32{
+			// the block above the floor is dark, its +x neighbor is lit
+			{X: 2, Y: 2, Z: 2}: 0,
+			{X: 1, Y: 2, Z: 2}: 0,
+			{X: 1, Y: 2, Z: 1}: 0,
+			{X: 1, Y: 2, Z: 3}: 0,
+			{X: 2, Y: 2, Z: 1}: 0,
+			{X: 2, Y: 2, Z: 3}: 0,
+		},
+	}
+
+	flat := meshWorld(&Mesher{}, w, w.testWorld)
+	for _, v := range faceVertices(flat, floor, Vec3{Y: 1}) {
+		assert.Equal(t, float32(0), v.sky)
+	}
+
+	smooth := meshWorld(&Mesher{Smooth: true}, w, w.testWorld)
+	for _, v := range faceVertices(smooth, floor, Vec3{Y: 1}) {
+		if v.pos.X > floor.X {
+			// two of the four blocks around the +x corners are lit
+			assert.Equal(t, float32(0.5), v.sky)
+		} else {
+			assert.Equal(t, float32(0), v.sky)
+		}
+	}
+}
+
+func TestMeshSmoothLightingIgnoresOpaqueBlocks(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := litWorld{
+		testWorld: testWorld{
+			floor:              stone,
+			{X: 3, Y: 2, Z: 2}: stone,
+		},
+		light: map[Vec3]float32{
+			{X: 3, Y: 2, Z: 2}: 0,
+		},
+	}
+
+	data := meshWorld(&Mesher{Smooth: true}, w, testWorld{floor: stone})
+	for _, v := range faceVertices(data, floor, Vec3{Y: 1}) {
+		assert.Equal(t, float32(1), v.sky)
+	}
+}
+
+func TestMeshPlantIsCrossedQuads(t *testing.T) {
+	grass := block.GetBlock(block.GrassID)
+	w := testWorld{{X: 1, Y: 1, Z: 1}: grass}
+
+	data := meshWorld(&Mesher{AO: true, Smooth: true}, w, w)
+
+	vs := vertices(data)
+	assert.Len(t, vs, 4*6)
+	for _, v := range vs {
+		if v.normal.X != 0 {
+			assert.Equal(t, float32(1), v.pos.X)
+		} else {
+			assert.Equal(t, float32(1), v.pos.Z)
+		}
+		assert.Equal(t, float32(1), v.ao)
+	}
+}
+
+func TestMeshSmoothBlockLight(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	floor := Vec3{X: 2, Y: 1, Z: 2}
+	w := litWorld{
+		testWorld: testWorld{floor: stone},
+		torch: map[Vec3]float32{
+			{X: 2, Y: 2, Z: 2}: 1,
+			{X: 3, Y: 2, Z: 2}: 1,
+		},
+	}
+
+	data := meshWorld(&Mesher{Smooth: true}, w, w.testWorld)
+	for _, v := range faceVertices(data, floor, Vec3{Y: 1}) {
+		assert.Equal(t, float32(1), v.sky)
+		if v.pos.X > floor.X {
+			assert.Equal(t, float32(0.5), v.torch)
+		} else {
+			assert.Equal(t, float32(0.25), v.torch)
+		}
+	}
+}
+
+func TestMeshSortsBlocksIntoLayers(t *testing.T) {
+	w := testWorld{
+		{X: 1, Y: 1, Z: 1}:  block.GetBlock(block.StoneID),
+		{X: 4, Y: 1, Z: 1}:  block.GetBlock(block.LeavesID),
+		{X: 7, Y: 1, Z: 1}:  block.GetBlock(block.GrassID),
+		{X: 10, Y: 1, Z: 1}: block.GetBlock(block.IceID),
+	}
+
+	data := meshLayers(&Mesher{}, w, w)
+
+	assert.Len(t, vertices(data[block.LayerOpaque]), 6*6)
+	assert.Len(t, vertices(data[block.LayerCutout]), 6*6+4*6)
+	assert.Len(t, vertices(data[block.LayerTranslucent]), 6*6)
+	for _, v := range vertices(data[block.LayerTranslucent]) {
+		assert.InDelta(t, 10, v.pos.X, 0.5)
+	}
+}
+
+func TestMeshHidesFacesBetweenTranslucentBlocks(t *testing.T) {
+	ice := block.GetBlock(block.IceID)
+	w := testWorld{
+		{X: 1, Y: 1, Z: 1}: ice,
+		{X: 2, Y: 1, Z: 1}: ice,
+		{X: 1, Y: 1, Z: 2}: block.GetBlock(block.StoneID),
+	}
+
+	data := meshLayers(&Mesher{}, w, w)
+
+	assert.Empty(t, faceVertices(data[block.LayerTranslucent], Vec3{X: 1, Y: 1, Z: 1}, Vec3{X: 1}))
+	assert.Empty(t, faceVertices(data[block.LayerTranslucent], Vec3{X: 2, Y: 1, Z: 1}, Vec3{X: -1}))
+	// ice next to stone hides its face, but the stone face behind the ice stays
+	assert.Empty(t, faceVertices(data[block.LayerTranslucent], Vec3{X: 1, Y: 1, Z: 1}, Vec3{Z: 1}))
+	assert.Len(t, faceVertices(data[block.LayerOpaque], Vec3{X: 1, Y: 1, Z: 2}, Vec3{Z: -1}), 6)
+}
+
+func TestMeshLiquidSurfaceIsLowered(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	w := testWorld{{X: 1, Y: 1, Z: 1}: water}
+
+	data := meshLayers(&Mesher{}, w, w)[block.LayerTranslucent]
+
+	// the four sides, the top and the top seen from below
+	assert.Len(t, vertices(data), 6*6+6)
+	var top, under int
+	for _, v := range vertices(data) {
+		assert.LessOrEqual(t, v.pos.Y, 0.5+water.Height()+1e-4)
+		if v.normal == (Vec3{Y: 1}) {
+			top++
+			assert.InDelta(t, 0.5+water.Height(), v.pos.Y, 1e-4)
+		}
+		if v.normal == (Vec3{Y: -1}) && v.pos.Y > 1 {
+			under++
+		}
+	}
+	assert.Equal(t, 6, top)
+	assert.Equal(t, 6, under)
+}
+
+func TestMeshLiquidSlopesTowardsFlowingWater(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	flowing := block.LiquidVariant(water, block.MaxLiquidLevel)
+	w := testWorld{
+		{X: 1, Y: 1, Z: 1}: water,
+		{X: 2, Y: 1, Z: 1}: flowing,
+	}
+
+	data := meshLayers(&Mesher{}, w, w)[block.LayerTranslucent]
+
+	// no faces between the two, and the shared top edge is averaged
+	assert.Empty(t, faceVertices(data, Vec3{X: 1, Y: 1, Z: 1}, Vec3{X: 1}))
+	for _, v := range vertices(data) {
+		if v.normal != (Vec3{Y: 1}) {
+			continue
+		}
+		switch v.pos.X {
+		case 0.5:
+			assert.InDelta(t, 0.5+water.Height(), v.pos.Y, 1e-4)
+		case 1.5:
+			assert.InDelta(t, 0.5+(water.Height()+flowing.Height())/2, v.pos.Y, 1e-4)
+		case 2.5:
+			assert.InDelta(t, 0.5+flowing.Height(), v.pos.Y, 1e-4)
+		}
+	}
+}
+
+func TestSortBackToFront(t *testing.T) {
+	ice := block.GetBlock(block.IceID)
+	w := testWorld{
+		{X: 1, Y: 1, Z: 1}: ice,
+		{X: 5, Y: 1, Z: 1}: ice,
+		{X: 9, Y: 1, Z: 1}: ice,
+	}
+	data := meshLayers(&Mesher{}, w, w)[block.LayerTranslucent]
+
+	sortBackToFront(data, mgl32.Vec3{20, 1, 1})
+
+	var last float32 = 1e9
+	for i := 0; i < len(data); i += quadSize {
+		d := quadCenter(data[i:]).Sub(mgl32.Vec3{20, 1, 1}).Len()
+		assert.LessOrEqual(t, d, last)
+		last = d
+	}
+	// the farthest block comes first
+	assert.InDelta(t, 1, vertices(data)[0].pos.X, 0.5)
+}
+
+func TestVertexAO(t *testing.T) {
+	assert.Equal(t, 3, vertexAO(false, false, false))
+	assert.Equal(t, 2, vertexAO(true, false, false))
+	assert.Equal(t, 2, vertexAO(false, false, true))
+	assert.Equal(t, 1, vertexAO(false, true, true))
+	assert.Equal(t, 0, vertexAO(true, true, false))
+	assert.Equal(t, 0, vertexAO(true, true, true))
+}
+
+func TestChunkHeightFollowsOpaqueBlocks(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	c := NewChunk(Vec3{X: 1})
+	col := Vec3{X: 33, Z: 4}
+
+	assert.Equal(t, float32(-1), c.Height(col))
+
+	c.Add(Vec3{X: 33, Y: 3, Z: 4}, stone)
+	c.Add(Vec3{X: 33, Y: 7, Z: 4}, stone)
+	c.Add(Vec3{X: 33, Y: 9, Z: 4}, block.GetBlock(block.GrassID))
+	assert.Equal(t, float32(7), c.Height(col))
+
+	c.Del(Vec3{X: 33, Y: 7, Z: 4})
+	assert.Equal(t, float32(3), c.Height(col))
+
+	c.Del(Vec3{X: 33, Y: 3, Z: 4})
+	assert.Equal(t, float32(-1), c.Height(col))
+}
@@ -0,0 +1,173 @@
+package chunk
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/game/clock"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/offscreen"
+	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden images of the render tests")
+
+const (
+	goldenWidth  = 320
+	goldenHeight = 240
+
+	// llvmpipe is deterministic, the tolerance only covers
+	// small differences between Mesa and LLVM versions
+	goldenChannelDelta = 8
+	goldenMaxPixels    = goldenWidth * goldenHeight / 500
+)
+
+// goldenScene is a small patch of ground with a block of each render layer
+func goldenScene() testWorld {
+	w := testWorld{}
+	for x := 0; x < 12; x++ {
+		for z := 0; z < 12; z++ {
+			w[Vec3{X: float32(x), Z: float32(z)}] = block.GetBlock(block.GrassBlockID)
+		}
+	}
+	water := block.GetBlock(block.WaterID)
+	for x := 6; x < 9; x++ {
+		for z := 6; z < 9; z++ {
+			w[Vec3{X: float32(x), Z: float32(z)}] = water
+		}
+	}
+	w[Vec3{X: 9, Z: 7}] = block.LiquidVariant(water, 3)
+	for y := 1; y < 4; y++ {
+		w[Vec3{X: 2, Y: float32(y), Z: 2}] = block.GetBlock(block.StoneID)
+	}
+	w[Vec3{X: 4, Y: 1, Z: 2}] = block.GetBlock(block.GlassID)
+	w[Vec3{X: 2, Y: 1, Z: 5}] = block.GetBlock(block.LeavesID)
+	w[Vec3{X: 4, Y: 1, Z: 5}] = block.GetBlock(block.IceID)
+	w[Vec3{X: 9, Y: 1, Z: 3}] = block.GetBlock(block.DandelionID)
+	w[Vec3{X: 10, Y: 1, Z: 10}] = block.GetBlock(block.LampID)
+	return w
+}
+
+// renderGolden draws world w into an offscreen framebuffer,
+// seen from camera looking at target
+func renderGolden(t *testing.T, w testWorld, camera, target mgl32.Vec3) *image.NRGBA {
+	var err error
+	mainthread.Call(func() {
+		err = offscreen.Init()
+	})
+	if err != nil {
+		t.Skip(err)
+	}
+
+	path := *texture.TexturePath
+	*texture.TexturePath = "../../texture.png"
+	defer func() { *texture.TexturePath = path }()
+	r, err := NewChunkRenderer(nil)
+	require.NoError(t, err)
+
+	c := NewChunk(Vec3{})
+	for id, b := range w {
+		c.Add(id, b)
+	}
+	data := new(ChunkData)
+	(&Mesher{AO: true, Smooth: true}).Mesh(c, w, data)
+
+	n := float32(settings.Current().RenderRadius * ChunkWidth)
+	mat := mgl32.Perspective(Radian(45), float32(goldenWidth)/goldenHeight, 0.01, n)
+	mat = mat.Mul4(mgl32.LookAtV(camera, target, mgl32.Vec3{0, 1, 0}))
+	f := frame{mat: mat, camera: camera, clock: clock.NewClock(clock.Noon)}
+
+	var img *image.NRGBA
+	mainthread.Call(func() {
+		fbo, ferr := types.NewFramebuffer(goldenWidth, goldenHeight)
+		if ferr != nil {
+			err = ferr
+			return
+		}
+		defer fbo.Release()
+		mesh := newMesh(c.ID(), r.shader, data)
+		defer mesh.Release()
+		r.meshcache.Store(c.ID(), mesh)
+
+		fbo.Begin()
+		gl.Enable(gl.DEPTH_TEST)
+		gl.Enable(gl.CULL_FACE)
+		fog := f.clock.FogColor()
+		gl.ClearColor(fog.X(), fog.Y(), fog.Z(), 1)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		r.shader.Begin()
+		r.texture.Begin()
+		r.drawChunks(f)
+		r.texture.End()
+		r.shader.End()
+		fbo.End()
+		img = fbo.Image()
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, r.State().Faces)
+	return img
+}
+
+// assertGolden compares img to testdata/name.png, or rewrites it with -update
+func assertGolden(t *testing.T, name string, img *image.NRGBA) {
+	fname := filepath.Join("testdata", name+".png")
+	if *updateGolden {
+		require.NoError(t, texture.SavePNG(fname, img))
+		return
+	}
+
+	f, err := os.Open(fname)
+	require.NoError(t, err, "run the test with -update to create the golden image")
+	defer f.Close()
+	golden, err := png.Decode(f)
+	require.NoError(t, err)
+	require.Equal(t, golden.Bounds(), img.Bounds())
+
+	var differ int
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			r1, g1, b1, _ := golden.At(x, y).RGBA()
+			c := img.NRGBAAt(x, y)
+			if channelDelta(r1, c.R) > goldenChannelDelta ||
+				channelDelta(g1, c.G) > goldenChannelDelta ||
+				channelDelta(b1, c.B) > goldenChannelDelta {
+				differ++
+			}
+		}
+	}
+	if differ > goldenMaxPixels {
+		actual := filepath.Join(os.TempDir(), name+".png")
+		_ = texture.SavePNG(actual, img)
+		t.Errorf("%d pixels differ from %s, the frame is saved in %s", differ, fname, actual)
+	}
+}
+
+func channelDelta(golden uint32, v uint8) int {
+	d := int(golden>>8) - int(v)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func TestRenderChunksGolden(t *testing.T) {
+	img := renderGolden(t, goldenScene(), mgl32.Vec3{-4, 9, -4}, mgl32.Vec3{6, 0, 6})
+	assertGolden(t, "render_chunks", img)
+}
+
+func TestRenderChunksGoldenUnderwater(t *testing.T) {
+	img := renderGolden(t, goldenScene(), mgl32.Vec3{7.2, 0.1, 6.2}, mgl32.Vec3{7, 3, 14})
+	assertGolden(t, "render_chunks_underwater", img)
+}
@@ -1,24 +1,21 @@
 package chunk
 
 import (
-	"flag"
 	"github.com/artheus/go-minecraft/core/block"
 	"github.com/artheus/go-minecraft/core/chunk/state"
 	"github.com/artheus/go-minecraft/core/ctx"
-	"github.com/artheus/go-minecraft/core/item"
+	"github.com/artheus/go-minecraft/core/game/settings"
 	"github.com/artheus/go-minecraft/core/texture"
 	"github.com/artheus/go-minecraft/core/types"
 	. "github.com/artheus/go-minecraft/math/f32"
 	"github.com/faiface/glhf"
 	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"log"
 	"sort"
 	"sync"
-)
-
-var (
-	RenderRadius = flag.Int("r", 16, "render radius")
+	"time"
 )
 
 type ChunkRenderer struct {
@@ -27,13 +24,20 @@ type ChunkRenderer struct {
 	texture *glhf.Texture
 
 	facePool *sync.Pool
+	dataPool *sync.Pool
 
 	sigch     chan struct{}
-	meshcache sync.Map //map[Vec3]*Mesh
+	meshcache sync.Map           //map[Vec3]*Mesh
+	updateMx  sync.Mutex         // serializes updateMeshCache
+	queued    map[Vec3]time.Time // when missing meshes were first seen, held by updateMx
 
 	state state.State
 
-	item *types.Mesh
+	queueMx sync.Mutex
+	queue   state.State // QueuedChunks and MeshLatency of updateMeshCache
+
+	mesher *Mesher
+	item   *types.Mesh
 }
 
 func NewChunkRenderer(ctx *ctx.Context) (*ChunkRenderer, error) {
@@ -46,20 +50,14 @@ func NewChunkRenderer(ctx *ctx.Context) (*ChunkRenderer, error) {
 	}
 
 	r := &ChunkRenderer{
-		ctx:   ctx,
-		sigch: make(chan struct{}, 4),
+		ctx:    ctx,
+		sigch:  make(chan struct{}, 4),
+		queued: make(map[Vec3]time.Time),
+		mesher: NewMesher(),
 	}
 
 	mainthread.Call(func() {
-		r.shader, err = glhf.NewShader(glhf.AttrFormat{
-			glhf.Attr{Name: "pos", Type: glhf.Vec3},
-			glhf.Attr{Name: "tex", Type: glhf.Vec2},
-			glhf.Attr{Name: "normal", Type: glhf.Vec3},
-		}, glhf.AttrFormat{
-			glhf.Attr{Name: "matrix", Type: glhf.Mat4},
-			glhf.Attr{Name: "camera", Type: glhf.Vec3},
-			glhf.Attr{Name: "fogdis", Type: glhf.Float},
-		}, blockVertexSource, blockFragmentSource)
+		r.shader, err = glhf.NewShader(blockVertexFormat, blockUniformFormat, blockVertexSource, blockFragmentSource)
 
 		if err != nil {
 			return
@@ -75,146 +73,81 @@ func NewChunkRenderer(ctx *ctx.Context) (*ChunkRenderer, error) {
 			return make([]float32, 0, r.shader.VertexFormat().Size()/4*6*6)
 		},
 	}
+	r.dataPool = &sync.Pool{
+		New: func() interface{} {
+			return new(ChunkData)
+		},
+	}
 
 	return r, nil
 }
 
-func (r *ChunkRenderer) makeChunkMesh(c types.IChunk, onmainthread bool) *types.Mesh {
-	facedata := r.facePool.Get().([]float32)
-	defer r.facePool.Put(facedata[:0])
+func (r *ChunkRenderer) makeChunkMesh(c types.IChunk, onmainthread bool) *Mesh {
+	data := r.dataPool.Get().(*ChunkData)
+	defer func() {
+		data.Reset()
+		r.dataPool.Put(data)
+	}()
 
-	c.RangeBlocks(func(pos Vec3, w *block.Block) {
-		if w == nil {
-			return
-		}
-		if w.ID == block.AirID {
-			return
-		}
-		if r.ctx.Game().World().Block(pos).ID == block.AirID {
-			return
-		}
-		if !r.ctx.Game().World().Block(pos).Visible {
-			return
-		}
-
-		show := block.Sides(
-			r.ctx.Game().World().Block(pos.Left()).Transparent || !r.ctx.Game().World().Block(pos.Left()).Visible,
-			r.ctx.Game().World().Block(pos.Right()).Transparent || !r.ctx.Game().World().Block(pos.Right()).Visible,
-			r.ctx.Game().World().Block(pos.Up()).Transparent || !r.ctx.Game().World().Block(pos.Up()).Visible,
-			(pos.Y > 0 && r.ctx.Game().World().Block(pos.Down()).Transparent) || !r.ctx.Game().World().Block(pos.Down()).Visible,
-			r.ctx.Game().World().Block(pos.Front()).Transparent || !r.ctx.Game().World().Block(pos.Front()).Visible,
-			r.ctx.Game().World().Block(pos.Back()).Transparent || !r.ctx.Game().World().Block(pos.Back()).Visible,
-		)
-		if r.ctx.Game().World().Block(pos).Plant {
-			facedata = block.PlantData(facedata, show, pos, item.Tex.Texture(w.ID))
-		} else {
-			facedata = block.BlockData(facedata, show, pos, item.Tex.Texture(w.ID))
-		}
-	})
-	//n := len(facedata) / (r.shader.VertexFormat().Size() / 4)
-	//log.Printf("chunk faces:%d", n/6)
-	var mesh *types.Mesh
+	r.mesher.Mesh(c, r.ctx.Game().World(), data)
+	var mesh *Mesh
 	if onmainthread {
-		mesh = types.NewMesh(r.shader, facedata)
+		mesh = newMesh(c.ID(), r.shader, data)
 	} else {
 		mainthread.Call(func() {
-			mesh = types.NewMesh(r.shader, facedata)
+			mesh = newMesh(c.ID(), r.shader, data)
 		})
 	}
-	mesh.Id = c.ID()
 	return mesh
 }
 
 // call on mainthread
 func (r *ChunkRenderer) UpdateItem(w string) {
-	vertices := r.facePool.Get().([]float32)
-	defer r.facePool.Put(vertices[:0])
-	texture := item.Tex.Texture(w)
-	show := block.Sides(true, true, true, true, true, true)
-	pos := Vec3{0, 0, 0}
-	if block.GetBlock(w).Plant {
-		vertices = block.PlantData(vertices, show, pos, texture)
-	} else {
-		vertices = block.BlockData(vertices, show, pos, texture)
-	}
-	item := types.NewMesh(r.shader, vertices)
 	if r.item != nil {
 		r.item.Release()
+		r.item = nil
 	}
-	r.item = item
-}
-
-func frustumPlanes(mat *mgl32.Mat4) []mgl32.Vec4 {
-	c1, c2, c3, c4 := mat.Rows()
-	return []mgl32.Vec4{
-		c4.Add(c1),          // left
-		c4.Sub(c1),          // right
-		c4.Sub(c2),          // top
-		c4.Add(c2),          // bottom
-		c4.Mul(0.1).Add(c3), // front
-		c4.Mul(320).Sub(c3), // back
-	}
-}
-
-func isChunkVisiable(planes []mgl32.Vec4, id Vec3) bool {
-	p := mgl32.Vec3{float32(id.X * ChunkWidth), 0, float32(id.Z * ChunkWidth)}
-	const m = ChunkWidth
-
-	points := []mgl32.Vec3{
-		{p.X(), p.Y(), p.Z()},
-		{p.X() + m, p.Y(), p.Z()},
-		{p.X() + m, p.Y(), p.Z() + m},
-		{p.X(), p.Y(), p.Z() + m},
-
-		{p.X(), p.Y() + 256, p.Z()},
-		{p.X() + m, p.Y() + 256, p.Z()},
-		{p.X() + m, p.Y() + 256, p.Z() + m},
-		{p.X(), p.Y() + 256, p.Z() + m},
-	}
-	for _, plane := range planes {
-		var in, out int
-		for _, point := range points {
-			if plane.Dot(point.Vec4(1)) < 0 {
-				out++
-			} else {
-				in++
-			}
-			if in != 0 && out != 0 {
-				break
-			}
-		}
-		if in == 0 {
-			return false
-		}
+	b := block.GetBlock(w)
+	if b == nil {
+		return
 	}
-	return true
+	vertices := r.facePool.Get().([]float32)
+	defer r.facePool.Put(vertices[:0])
+	vertices = r.mesher.Item(b, vertices)
+	r.item = types.NewMesh(r.shader, vertices)
 }
 
+// Get3dMat returns the perspective projection times the camera matrix,
+// it sees as far as the render radius
 func (r *ChunkRenderer) Get3dMat() mgl32.Mat4 {
-	n := float32(*RenderRadius * ChunkWidth)
+	s := settings.Current()
+	n := float32(s.RenderRadius * ChunkWidth)
 	width, height := r.ctx.Game().Window().GetSize()
-	mat := mgl32.Perspective(Radian(45), float32(width)/float32(height), 0.01, n)
-	mat = mat.Mul4(r.ctx.Game().Camera().Matrix())
+	camera := r.ctx.Game().Camera()
+	mat := mgl32.Perspective(Radian(camera.FOV()), float32(width)/float32(height), 0.01, n)
+	mat = mat.Mul4(camera.Matrix())
 	return mat
 }
 
+// Get2dMat returns an orthographic projection looking straight down
+// at the camera, with north (-z) up and east (+x) right. It sees as
+// far as the render radius on every side
 func (r *ChunkRenderer) Get2dMat() mgl32.Mat4 {
-	n := float32(*RenderRadius * ChunkWidth)
-	mat := mgl32.Ortho(-n, n, -n, n, -1, n)
-	mat = mat.Mul4(r.ctx.Game().Camera().Matrix())
-	return mat
+	n := float32(settings.Current().RenderRadius * ChunkWidth)
+	pos := r.ctx.Game().Camera().Pos()
+	view := mgl32.LookAtV(pos.Add(mgl32.Vec3{0, n, 0}), pos, mgl32.Vec3{0, 0, -1})
+	return mgl32.Ortho(-n, n, -n, n, 0, 2*n).Mul4(view)
 }
 
 func (r *ChunkRenderer) sortChunks(chunks []Vec3) []Vec3 {
 	nb := NearBlock(r.ctx.Game().Camera().Pos())
 	cid := nb.ChunkID()
 	x, z := cid.X, cid.Z
-	mat := r.Get3dMat()
-	planes := frustumPlanes(&mat)
+	frustum := NewFrustum(r.Get3dMat())
 
 	sort.Slice(chunks, func(i, j int) bool {
-		v1 := isChunkVisiable(planes, chunks[i])
-		v2 := isChunkVisiable(planes, chunks[j])
+		v1 := frustum.IntersectsBox(columnBox(chunks[i]))
+		v2 := frustum.IntersectsBox(columnBox(chunks[j]))
 		if v1 && !v2 {
 			return true
 		}
@@ -228,14 +161,20 @@ func (r *ChunkRenderer) sortChunks(chunks []Vec3) []Vec3 {
 	return chunks
 }
 
-func (r *ChunkRenderer) updateMeshCache() {
+// updateMeshCache builds a batch of the missing and dirty meshes around
+// the camera and drops the ones out of range, it returns how many
+// meshes were missing or dirty
+func (r *ChunkRenderer) updateMeshCache() int {
+	r.updateMx.Lock()
+	defer r.updateMx.Unlock()
+
 	// Get chunk camera is currently in
 	block := NearBlock(r.ctx.Game().Camera().Pos())
 	chunk := block.ChunkID()
 	x, z := chunk.X, chunk.Z
 
 	// Get which chunks to render (camera culling)
-	n := *RenderRadius
+	n := settings.Current().RenderRadius
 	needed := make(map[Vec3]bool)
 
 	for dx := -n; dx < n; dx++ {
@@ -266,7 +205,7 @@ func (r *ChunkRenderer) updateMeshCache() {
 		if !ok {
 			added = append(added, id)
 		} else {
-			if mesh.(*types.Mesh).Dirty {
+			if mesh.(*Mesh).Dirty {
 				log.Printf("update cache %v", id)
 				added = append(added, id)
 				removed = append(removed, id)
@@ -277,24 +216,49 @@ func (r *ChunkRenderer) updateMeshCache() {
 	// Number of chunks constructed in batch
 	const batchBuildChunk = 4
 	r.sortChunks(added)
-	if len(added) > 4 {
+	missing := len(added)
+	now := time.Now()
+	for _, id := range added {
+		if _, ok := r.queued[id]; !ok {
+			r.queued[id] = now
+		}
+	}
+	if len(added) > batchBuildChunk {
 		added = added[:batchBuildChunk]
 	}
 
 	// Delete any removed mesh from meshcache
-	var removedMesh []*types.Mesh
+	var removedMesh []*Mesh
 	for _, id := range removed {
 		log.Printf("remove cache %v", id)
 		mesh, _ := r.meshcache.Load(id)
 		r.meshcache.Delete(id)
-		removedMesh = append(removedMesh, mesh.(*types.Mesh))
+		removedMesh = append(removedMesh, mesh.(*Mesh))
 	}
 
 	newChunks := r.ctx.Game().World().Chunks(added)
+	var latency time.Duration
 	for _, c := range newChunks {
 		//log.Printf("add cache %v", c.ID())
 		r.meshcache.Store(c.ID(), r.makeChunkMesh(c, false))
+		if since, ok := r.queued[c.ID()]; ok {
+			latency += time.Since(since)
+			delete(r.queued, c.ID())
+		}
+	}
+	// chunks out of range are no longer waited for
+	for id := range r.queued {
+		if !needed[id] {
+			delete(r.queued, id)
+		}
+	}
+
+	r.queueMx.Lock()
+	r.queue.QueuedChunks = missing - len(newChunks)
+	if len(newChunks) > 0 {
+		r.queue.MeshLatency = latency / time.Duration(len(newChunks))
 	}
+	r.queueMx.Unlock()
 
 	// Release any removed mesh from VRAM
 	mainthread.CallNonBlock(func() {
@@ -303,12 +267,23 @@ func (r *ChunkRenderer) updateMeshCache() {
 		}
 	})
 
+	return missing
+}
+
+// LoadChunks builds all meshes around the camera before returning,
+// must not be called on the main thread. It gives up after as many
+// batches as there are chunks in range, in case some never load
+func (r *ChunkRenderer) LoadChunks() {
+	n := settings.Current().RenderRadius
+	limit := n * n * 4
+	for i := 0; i < limit && r.updateMeshCache() > 0; i++ {
+	}
 }
 
 // forceChunks forces any removed mesh from chunks to be released from VRAM
 // must be called on main-thread
 func (r *ChunkRenderer) forceChunks(ids []Vec3) {
-	var removedMesh []*types.Mesh
+	var removedMesh []*Mesh
 
 	// Get requested chunks
 	chunks := r.ctx.Game().World().Chunks(ids)
@@ -317,9 +292,9 @@ func (r *ChunkRenderer) forceChunks(ids []Vec3) {
 	for _, chunk := range chunks {
 		id := chunk.ID()
 		imesh, ok := r.meshcache.Load(id)
-		var mesh *types.Mesh
+		var mesh *Mesh
 		if ok {
-			mesh = imesh.(*types.Mesh)
+			mesh = imesh.(*Mesh)
 		}
 		if ok && !mesh.Dirty {
 			continue
@@ -372,7 +347,7 @@ func (r *ChunkRenderer) DirtyChunk(id Vec3) {
 	if !ok {
 		return
 	}
-	mesh.(*types.Mesh).Dirty = true
+	mesh.(*Mesh).Dirty = true
 }
 
 // UpdateLoop runs a loop for updating meshcache whenever a signal
@@ -392,24 +367,107 @@ func (r *ChunkRenderer) UpdateLoop() {
 func (r *ChunkRenderer) renderChunks() {
 	r.forcePlayerChunks()
 	r.checkChunks()
-	mat := r.Get3dMat()
+	r.drawChunks(frame{
+		mat:    r.Get3dMat(),
+		camera: r.ctx.Game().Camera().Pos(),
+		clock:  r.ctx.Game().Clock(),
+	})
+}
 
-	r.shader.SetUniformAttr(0, mat)
-	r.shader.SetUniformAttr(1, r.ctx.Game().Camera().Pos())
-	r.shader.SetUniformAttr(2, float32(*RenderRadius)*ChunkWidth)
+// frame is what the chunks are drawn from
+type frame struct {
+	mat    mgl32.Mat4 // projection times camera matrix
+	camera mgl32.Vec3
+	clock  types.IClock
+}
+
+// drawChunks draws the cached meshes seen in frame f
+func (r *ChunkRenderer) drawChunks(f frame) {
+	r.shader.SetUniformAttr(0, f.mat)
+	r.shader.SetUniformAttr(1, f.camera)
+	r.shader.SetUniformAttr(2, settings.Current().Fog()*ChunkWidth)
+	r.setSkyUniforms(f.clock)
 
-	planes := frustumPlanes(&mat)
+	frustum := NewFrustum(f.mat)
 	r.state = state.State{}
 	r.meshcache.Range(func(k, v interface{}) bool {
-		id, mesh := k.(Vec3), v.(*types.Mesh)
 		r.state.CacheChunks++
-		if isChunkVisiable(planes, id) {
-			r.state.RendingChunks++
-			r.state.Faces += mesh.Faces()
-			mesh.Render()
-		}
 		return true
 	})
+
+	visible := r.visibleSections(&frustum, f.camera)
+	chunks := make(map[Vec3]bool)
+	for _, s := range visible {
+		chunks[Vec3{X: s.Id.X, Z: s.Id.Z}] = true
+		r.state.Faces += s.Faces()
+	}
+	r.state.RendingChunks = len(chunks)
+
+	r.renderLayer(visible, block.LayerOpaque)
+	r.renderLayer(visible, block.LayerCutout)
+	r.renderTranslucent(visible, f.camera)
+}
+
+// visibleSections returns the sections with faces that can be seen from
+// the camera, near to far. Sections hidden behind opaque blocks, like
+// closed caves, are left out, as are sections outside of the frustum
+func (r *ChunkRenderer) visibleSections(frustum *Frustum, camera mgl32.Vec3) []*Section {
+	section := func(id Vec3) *Section {
+		mesh, ok := r.meshcache.Load(Vec3{X: id.X, Z: id.Z})
+		if !ok {
+			return nil
+		}
+		return mesh.(*Mesh).Section(int(id.Y))
+	}
+	// chunks not meshed yet don't hide anything behind them
+	vis := func(id Vec3) Visibility {
+		if s := section(id); s != nil {
+			return s.Visibility
+		}
+		return AllVisible
+	}
+	// the walk passes through the whole section, even the empty parts
+	inView := func(id Vec3) bool {
+		return frustum.IntersectsBox(sectionBox(id))
+	}
+
+	start := SectionID(NearBlock(camera))
+
+	var sections []*Section
+	for _, id := range visibleSections(start, settings.Current().RenderRadius, vis, inView) {
+		if s := section(id); s != nil && s.Faces() > 0 && frustum.IntersectsBox(s.Bounds) {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+func (r *ChunkRenderer) renderLayer(sections []*Section, layer block.Layer) {
+	r.shader.SetUniformAttr(6, int32(layer))
+	for _, s := range sections {
+		s.Render(layer)
+	}
+}
+
+// renderTranslucent blends the translucent faces over the other layers,
+// sections and their faces are drawn back to front without writing depth
+func (r *ChunkRenderer) renderTranslucent(sections []*Section, camera mgl32.Vec3) {
+	sorted := append([]*Section(nil), sections...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Center().Sub(camera).LenSqr() > sorted[j].Center().Sub(camera).LenSqr()
+	})
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+
+	for _, s := range sorted {
+		s.SortTranslucent(camera)
+	}
+	r.renderLayer(sorted, block.LayerTranslucent)
+
+	gl.DepthMask(true)
+	gl.Disable(gl.BLEND)
 }
 
 // renderItem will draw the HUD block item, currently selected
@@ -426,8 +484,23 @@ func (r *ChunkRenderer) renderItem() {
 	mat := projection.Mul4(model)
 	r.shader.SetUniformAttr(0, mat)
 	r.shader.SetUniformAttr(1, mgl32.Vec3{0, 0, 0})
-	r.shader.SetUniformAttr(2, float32(*RenderRadius)*ChunkWidth)
+	r.shader.SetUniformAttr(2, settings.Current().Fog()*ChunkWidth)
+	// the held item is always shown in daylight
+	r.shader.SetUniformAttr(3, float32(1))
+	r.shader.SetUniformAttr(4, mgl32.Vec3{-1, 1, -1}.Normalize())
+	r.shader.SetUniformAttr(6, int32(block.LayerTranslucent))
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 	r.item.Render()
+	gl.Disable(gl.BLEND)
+}
+
+// setSkyUniforms passes the light and fog of the current time of day to the shader
+func (r *ChunkRenderer) setSkyUniforms(clock types.IClock) {
+	r.shader.SetUniformAttr(3, clock.Daylight())
+	r.shader.SetUniformAttr(4, clock.LightDirection())
+	r.shader.SetUniformAttr(5, clock.FogColor())
 }
 
 // Render will render all chunks and HUD block items to screen
@@ -443,5 +516,9 @@ func (r *ChunkRenderer) Render() {
 }
 
 func (r *ChunkRenderer) State() state.State {
-	return r.state
+	s := r.state
+	r.queueMx.Lock()
+	s.QueuedChunks, s.MeshLatency = r.queue.QueuedChunks, r.queue.MeshLatency
+	r.queueMx.Unlock()
+	return s
 }
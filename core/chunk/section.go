@@ -0,0 +1,164 @@
+package chunk
+
+import (
+	"math/bits"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+const (
+	// WorldHeight is the number of blocks a chunk is high
+	WorldHeight = 256
+	// SectionHeight is the height of the cubes chunks are split into
+	// for meshing and visibility culling
+	SectionHeight = ChunkWidth
+	SectionCount  = WorldHeight / SectionHeight
+
+	sectionVolume = ChunkWidth * SectionHeight * ChunkWidth
+)
+
+// sectionIndex returns the index of the section block y lies in,
+// blocks outside of the world height go to the bottom or top section
+func sectionIndex(y float32) int {
+	s := int(Floor(y / SectionHeight))
+	switch {
+	case s < 0:
+		return 0
+	case s >= SectionCount:
+		return SectionCount - 1
+	}
+	return s
+}
+
+// SectionID returns the id of the section block id lies in,
+// the chunk id with Y set to the section index
+func SectionID(id Vec3) Vec3 {
+	cid := id.ChunkID()
+	cid.Y = float32(sectionIndex(id.Y))
+	return cid
+}
+
+// Visibility tells which faces of a section see each other through it.
+// Bit from*6+to is set when non opaque blocks connect the two faces,
+// faces are indexed like cubeFaces
+type Visibility uint64
+
+// AllVisible is the Visibility of a section without opaque blocks
+const AllVisible Visibility = 1<<36 - 1
+
+// Connected tells if face from can be seen from face to
+func (v Visibility) Connected(from, to int) bool {
+	return v&(1<<uint(from*6+to)) != 0
+}
+
+// connect marks all faces in the face mask as seeing each other
+func (v *Visibility) connect(faces uint8) {
+	for from := 0; from < 6; from++ {
+		if faces&(1<<uint(from)) == 0 {
+			continue
+		}
+		for to := 0; to < 6; to++ {
+			if faces&(1<<uint(to)) != 0 {
+				*v |= 1 << uint(from*6+to)
+			}
+		}
+	}
+}
+
+// opaqueSet has a bit per block of a section
+type opaqueSet [sectionVolume / 64]uint64
+
+func (s *opaqueSet) set(i int) {
+	s[i/64] |= 1 << uint(i%64)
+}
+
+func (s *opaqueSet) has(i int) bool {
+	return s[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (s *opaqueSet) count() int {
+	var n int
+	for _, w := range s {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// sectionVisibility computes the Visibility of every section of chunk c
+func sectionVisibility(c types.IChunk) [SectionCount]Visibility {
+	var opaque [SectionCount]*opaqueSet
+	origin := c.ID()
+	c.RangeBlocks(func(pos Vec3, w *block.Block) {
+		if w == nil || !w.Opaque() || pos.Y < 0 || pos.Y >= WorldHeight {
+			return
+		}
+		s := sectionIndex(pos.Y)
+		if opaque[s] == nil {
+			opaque[s] = new(opaqueSet)
+		}
+		x, z := int(pos.X-origin.X*ChunkWidth), int(pos.Z-origin.Z*ChunkWidth)
+		opaque[s].set(cellIndex(x, int(pos.Y)-s*SectionHeight, z))
+	})
+
+	var vis [SectionCount]Visibility
+	for s, set := range opaque {
+		if set == nil {
+			vis[s] = AllVisible
+			continue
+		}
+		vis[s] = floodVisibility(set)
+	}
+	return vis
+}
+
+// floodVisibility flood fills every open region of a section and
+// connects the faces each region touches. filled is used up
+func floodVisibility(filled *opaqueSet) Visibility {
+	var vis Visibility
+	if filled.count() == sectionVolume {
+		return vis
+	}
+	var stack []int
+	for start := 0; start < sectionVolume; start++ {
+		if filled.has(start) {
+			continue
+		}
+		var faces uint8
+		filled.set(start)
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y, z := cellPos(i)
+			for f := range cubeFaces {
+				n := cubeFaces[f].normal
+				nx, ny, nz := x+int(n.X), y+int(n.Y), z+int(n.Z)
+				if nx < 0 || nx >= ChunkWidth || ny < 0 || ny >= SectionHeight || nz < 0 || nz >= ChunkWidth {
+					faces |= 1 << uint(f)
+					continue
+				}
+				if j := cellIndex(nx, ny, nz); !filled.has(j) {
+					filled.set(j)
+					stack = append(stack, j)
+				}
+			}
+		}
+		vis.connect(faces)
+	}
+	return vis
+}
+
+func cellIndex(x, y, z int) int {
+	return (y*ChunkWidth+z)*ChunkWidth + x
+}
+
+func cellPos(i int) (x, y, z int) {
+	return i % ChunkWidth, i / (ChunkWidth * ChunkWidth), i / ChunkWidth % ChunkWidth
+}
+
+// oppositeFace returns the index of the face on the other side of face f
+func oppositeFace(f int) int {
+	return f ^ 1
+}
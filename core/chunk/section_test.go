@@ -0,0 +1,158 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/stretchr/testify/assert"
+)
+
+// solidChunk returns chunk 0,0 with the lowest section filled with stone
+func solidChunk() *Chunk {
+	stone := block.GetBlock(block.StoneID)
+	c := NewChunk(Vec3{})
+	for x := 0; x < ChunkWidth; x++ {
+		for y := 0; y < SectionHeight; y++ {
+			for z := 0; z < ChunkWidth; z++ {
+				c.Add(Vec3{X: float32(x), Y: float32(y), Z: float32(z)}, stone)
+			}
+		}
+	}
+	return c
+}
+
+func TestSectionVisibilityOfEmptyAndSolidSections(t *testing.T) {
+	vis := sectionVisibility(solidChunk())
+
+	assert.Equal(t, Visibility(0), vis[0])
+	for _, v := range vis[1:] {
+		assert.Equal(t, AllVisible, v)
+	}
+}
+
+func TestSectionVisibilityFollowsTunnels(t *testing.T) {
+	c := solidChunk()
+	// a tunnel from the left to the right face
+	for x := 0; x < ChunkWidth; x++ {
+		c.Del(Vec3{X: float32(x), Y: 5, Z: 5})
+	}
+	// a closed cave
+	c.Del(Vec3{X: 10, Y: 20, Z: 10})
+	// a shaft from the top face down into the middle of the tunnel
+	for y := 5; y < SectionHeight; y++ {
+		c.Del(Vec3{X: 16, Y: float32(y), Z: 5})
+	}
+
+	v := sectionVisibility(c)[0]
+
+	assert.True(t, v.Connected(faceLeft, faceRight))
+	assert.True(t, v.Connected(faceRight, faceLeft))
+	assert.True(t, v.Connected(faceUp, faceLeft))
+	assert.False(t, v.Connected(faceUp, faceDown))
+	assert.False(t, v.Connected(faceFront, faceBack))
+	assert.False(t, v.Connected(faceLeft, faceFront))
+}
+
+func TestMeshSplitsChunkIntoSections(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	w := testWorld{
+		{X: 1, Y: 1, Z: 1}:                 stone,
+		{X: 1, Y: SectionHeight + 1, Z: 1}: stone,
+	}
+
+	data := meshChunk(&Mesher{}, w, w)
+
+	assert.Len(t, vertices(data.Sections[0][block.LayerOpaque]), 6*6)
+	assert.Len(t, vertices(data.Sections[1][block.LayerOpaque]), 6*6)
+	for _, v := range vertices(data.Sections[1][block.LayerOpaque]) {
+		assert.Greater(t, v.pos.Y, float32(SectionHeight))
+	}
+	assert.Empty(t, data.Sections[2][block.LayerOpaque])
+}
+
+// sectionGraph is a set of section visibilities, missing sections are open
+type sectionGraph map[Vec3]Visibility
+
+func (g sectionGraph) vis(id Vec3) Visibility {
+	if v, ok := g[id]; ok {
+		return v
+	}
+	return AllVisible
+}
+
+func everywhere(Vec3) bool {
+	return true
+}
+
+func TestVisibleSectionsSkipsClosedCaves(t *testing.T) {
+	// the ground is solid below section 2, except for a tunnel
+	// starting below the camera, reaching sideways two chunks
+	g := sectionGraph{}
+	for x := -3; x <= 3; x++ {
+		for z := -3; z <= 3; z++ {
+			g[Vec3{X: float32(x), Y: 0, Z: float32(z)}] = 0
+			g[Vec3{X: float32(x), Y: 1, Z: float32(z)}] = 0
+		}
+	}
+	var tunnel Visibility
+	tunnel.connect(1<<faceUp | 1<<faceRight)
+	g[Vec3{Y: 1}] = tunnel
+	var straight Visibility
+	straight.connect(1<<faceLeft | 1<<faceRight)
+	g[Vec3{X: 1, Y: 1}] = straight
+
+	sections := visibleSections(Vec3{Y: 3}, 3, g.vis, everywhere)
+
+	assert.Equal(t, Vec3{Y: 3}, sections[0])
+	// the surface sections are seen, their faces are visible from above
+	assert.Contains(t, sections, Vec3{X: 2, Y: 1, Z: 2})
+	// the tunnel is seen down the shaft
+	assert.Contains(t, sections, Vec3{X: 1, Y: 1})
+	assert.Contains(t, sections, Vec3{X: 2, Y: 1})
+	// nothing below the solid surface
+	for _, s := range sections {
+		assert.NotEqual(t, float32(0), s.Y, "%v", s)
+	}
+}
+
+func TestVisibleSectionsRespectsRadiusAndFrustum(t *testing.T) {
+	inFront := func(id Vec3) bool {
+		return id.X >= 0
+	}
+
+	sections := visibleSections(Vec3{Y: 2}, 2, sectionGraph{}.vis, inFront)
+
+	for _, s := range sections {
+		assert.GreaterOrEqual(t, s.X, float32(0))
+		assert.LessOrEqual(t, s.X*s.X+s.Z*s.Z, float32(4))
+	}
+	assert.Contains(t, sections, Vec3{X: 2, Y: 7})
+	assert.Contains(t, sections, Vec3{X: 1, Y: 0, Z: -1})
+}
+
+func TestVisibleSectionsNeverTurnsBack(t *testing.T) {
+	// a U shaped cave going right, up and back left again, above
+	// the camera section is solid
+	g := sectionGraph{}
+	for x := -3; x <= 3; x++ {
+		for y := 0; y < SectionCount; y++ {
+			g[Vec3{X: float32(x), Y: float32(y)}] = 0
+		}
+	}
+	var v Visibility
+	v.connect(1<<faceLeft | 1<<faceRight)
+	g[Vec3{X: 1, Y: 1}] = v
+	v = 0
+	v.connect(1<<faceLeft | 1<<faceUp)
+	g[Vec3{X: 2, Y: 1}] = v
+	v = 0
+	v.connect(1<<faceDown | 1<<faceLeft)
+	g[Vec3{X: 2, Y: 2}] = v
+	g[Vec3{X: 1, Y: 2}] = AllVisible
+
+	sections := visibleSections(Vec3{Y: 1}, 2, g.vis, everywhere)
+
+	assert.Contains(t, sections, Vec3{X: 2, Y: 2})
+	assert.NotContains(t, sections, Vec3{X: 1, Y: 2})
+}
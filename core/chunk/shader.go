@@ -1,22 +1,52 @@
 package chunk
 
+import "github.com/faiface/glhf"
+
 var (
+	// blockVertexFormat is the vertex layout written by the Mesher
+	blockVertexFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "pos", Type: glhf.Vec3},
+		glhf.Attr{Name: "tex", Type: glhf.Vec2},
+		glhf.Attr{Name: "normal", Type: glhf.Vec3},
+		glhf.Attr{Name: "ao", Type: glhf.Float},
+		glhf.Attr{Name: "sky", Type: glhf.Float},
+		glhf.Attr{Name: "torch", Type: glhf.Float},
+	}
+
+	// blockUniformFormat are the uniforms of the block shader, in the
+	// order of the indexes passed to SetUniformAttr
+	blockUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "camera", Type: glhf.Vec3},
+		glhf.Attr{Name: "fogdis", Type: glhf.Float},
+		glhf.Attr{Name: "daylight", Type: glhf.Float},
+		glhf.Attr{Name: "lightdir", Type: glhf.Vec3},
+		glhf.Attr{Name: "fogcolor", Type: glhf.Vec3},
+		glhf.Attr{Name: "layer", Type: glhf.Int},
+	}
+
 	blockVertexSource = `
 #version 330 core
 
 in vec3 pos;
 in vec2 tex;
 in vec3 normal;
+in float ao;
+in float sky;
+in float torch;
 
 uniform mat4 matrix;
 uniform vec3 camera;
 uniform float fogdis;
+uniform float daylight;
+uniform vec3 lightdir;
 
 out vec2 Tex;
 out float diff;
 out float fog_factor;
+out vec3 shade;
 
-const vec3 lightdir = normalize(vec3(-1, 1, -1));
+const vec3 torch_color = vec3(1.0, 0.88, 0.7);
 
 void main() {
     gl_Position = matrix *  vec4(pos, 1.0);
@@ -25,6 +55,8 @@ void main() {
     fog_factor = pow(clamp(camera_distance/fogdis, 0, 1), 4);
     Tex = tex;
     diff = max(0, dot(normal, lightdir));
+    vec3 skylight = vec3(mix(0.3, 1.0, sky) * daylight);
+    shade = ao * max(skylight, torch * torch_color);
 }
 `
 
@@ -34,26 +66,38 @@ void main() {
 in vec2 Tex;
 in float diff;
 in float fog_factor;
+in vec3 shade;
 uniform sampler2D tex;
+uniform vec3 fogcolor;
+uniform int layer;
 
 out vec4 FragColor;
 
-const vec3 sky_color = vec3(0.57, 0.71, 0.77);
+const int cutout = 1;
+const int translucent = 2;
 
 void main() {
-    vec3 color = vec3(texture(tex, vec2(Tex.x, 1-Tex.y)));
-    if (color == vec3(1,0,1)) {
+    vec4 texel = texture(tex, vec2(Tex.x, 1-Tex.y));
+    vec3 color = texel.rgb;
+    float alpha = 1;
+    if (layer == cutout && texel.a < 0.5) {
         discard;
     }
+    if (layer == translucent) {
+        if (texel.a == 0) {
+            discard;
+        }
+        alpha = texel.a;
+    }
     float df = diff;
     if (color == vec3(1,1,1)) {
         df = 1- diff * 0.2;
     }
     vec3 ambient = 0.05 * vec3(1, 1, 1);
     vec3 diffcolor = df * 0.5 * vec3(1,1,1);
-    color = (ambient * 8 + diffcolor) * color;
-    color = mix(color, sky_color, fog_factor/2);
-    FragColor = vec4(color, 1);
+    color = (ambient * 8 + diffcolor) * color * shade;
+    color = mix(color, fogcolor, fog_factor/2);
+    FragColor = vec4(color, alpha);
 }
 `
 )
\ No newline at end of file
@@ -1,7 +1,11 @@
 package state
 
+import "time"
+
 type State struct {
 	Faces         int
 	CacheChunks   int
 	RendingChunks int
+	QueuedChunks  int           // meshes missing or dirty
+	MeshLatency   time.Duration // how long the last built meshes were queued
 }
@@ -30,15 +30,7 @@ func (t *Thread) Init() types.InitFunc {
 			return err
 		}
 
-		t.shader, err = glhf.NewShader(glhf.AttrFormat{
-			glhf.Attr{Name: "pos", Type: glhf.Vec3},
-			glhf.Attr{Name: "tex", Type: glhf.Vec2},
-			glhf.Attr{Name: "normal", Type: glhf.Vec3},
-		}, glhf.AttrFormat{
-			glhf.Attr{Name: "matrix", Type: glhf.Mat4},
-			glhf.Attr{Name: "camera", Type: glhf.Vec3},
-			glhf.Attr{Name: "fogdis", Type: glhf.Float},
-		}, blockVertexSource, blockFragmentSource)
+		t.shader, err = glhf.NewShader(blockVertexFormat, blockUniformFormat, blockVertexSource, blockFragmentSource)
 
 		if err != nil {
 			return err
@@ -0,0 +1,323 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+)
+
+// errMoreWords is returned by Type.Parse when the argument goes on
+// past the words given
+var errMoreWords = errors.New("more words needed")
+
+// Type parses and completes the words of a kind of argument
+type Type interface {
+	// Parse parses the argument at the start of words, it returns the
+	// value and how many words it took
+	Parse(src types.CommandSource, words []string) (interface{}, int, error)
+	// Complete returns the words the word being typed can become
+	Complete(src types.CommandSource, word string) []string
+}
+
+// Arg is an argument of a command, optional arguments can only be
+// followed by other optional arguments
+type Arg struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+func (a Arg) usage() string {
+	if a.Optional {
+		return "[" + a.Name + "]"
+	}
+	return "<" + a.Name + ">"
+}
+
+// Args are the parsed arguments of a command by name, the getters
+// return the zero value for arguments left out
+type Args map[string]interface{}
+
+// Has tells if the argument name was given
+func (a Args) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+func (a Args) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+func (a Args) Int(name string) int {
+	i, _ := a[name].(int)
+	return i
+}
+
+func (a Args) Float(name string) float32 {
+	f, _ := a[name].(float32)
+	return f
+}
+
+func (a Args) Vec3(name string) mgl32.Vec3 {
+	v, _ := a[name].(mgl32.Vec3)
+	return v
+}
+
+func (a Args) Block(name string) *block.Block {
+	b, _ := a[name].(*block.Block)
+	return b
+}
+
+func (a Args) Player(name string) types.RemotePlayer {
+	p, _ := a[name].(types.RemotePlayer)
+	return p
+}
+
+// word is a Type of one word
+type word struct {
+	parse   func(src types.CommandSource, s string) (interface{}, error)
+	options func(src types.CommandSource) []string
+}
+
+func (w word) Parse(src types.CommandSource, words []string) (interface{}, int, error) {
+	if len(words) == 0 {
+		return nil, 0, errMoreWords
+	}
+	v, err := w.parse(src, words[0])
+	return v, 1, err
+}
+
+func (w word) Complete(src types.CommandSource, _ string) []string {
+	if w.options == nil {
+		return nil
+	}
+	return w.options(src)
+}
+
+// Word is one word completed with options, parse returns its value
+func Word(parse func(s string) (interface{}, error), options ...string) Type {
+	return word{
+		parse:   func(_ types.CommandSource, s string) (interface{}, error) { return parse(s) },
+		options: func(types.CommandSource) []string { return options },
+	}
+}
+
+// Choice is one of options, its value is a string
+func Choice(options ...string) Type {
+	return Word(func(s string) (interface{}, error) {
+		for _, o := range options {
+			if strings.EqualFold(s, o) {
+				return o, nil
+			}
+		}
+		return nil, errors.Errorf("%q is not one of %s", s, strings.Join(options, ", "))
+	}, options...)
+}
+
+// Int is a whole number from min to max, its value is an int
+func Int(min, max int) Type {
+	return Word(func(s string) (interface{}, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.Errorf("%q is not a whole number", s)
+		}
+		if i < min || i > max {
+			return nil, errors.Errorf("%d is not within %d and %d", i, min, max)
+		}
+		return i, nil
+	})
+}
+
+// Float is a number, its value is a float32
+func Float() Type {
+	return Word(func(s string) (interface{}, error) {
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, errors.Errorf("%q is not a number", s)
+		}
+		return float32(f), nil
+	})
+}
+
+// blockNamespace is the namespace of the blocks of the game, it can
+// be left out of block ids
+const blockNamespace = "core:"
+
+// Block is the id of a block of the registry, its value is the
+// *block.Block
+func Block() Type {
+	return word{
+		parse: func(_ types.CommandSource, s string) (interface{}, error) {
+			b := block.GetBlock(s)
+			if b == nil && !strings.Contains(s, ":") {
+				b = block.GetBlock(blockNamespace + s)
+			}
+			if b == nil {
+				return nil, errors.Errorf("unknown block %q", s)
+			}
+			return b, nil
+		},
+		options: func(types.CommandSource) []string {
+			var ids []string
+			block.RangeBlocks(func(b *block.Block) bool {
+				// flowing liquid follows from the sources
+				if !b.Liquid || b.IsSource() {
+					ids = append(ids, strings.TrimPrefix(b.ID, blockNamespace))
+				}
+				return true
+			})
+			return ids
+		},
+	}
+}
+
+// Player is the name or the id of a player of players, its value is
+// the types.RemotePlayer
+func Player(players func() []types.RemotePlayer) Type {
+	return word{
+		parse: func(_ types.CommandSource, s string) (interface{}, error) {
+			return findPlayer(players(), s)
+		},
+		options: func(types.CommandSource) []string {
+			return playerNames(players())
+		},
+	}
+}
+
+func findPlayer(players []types.RemotePlayer, s string) (types.RemotePlayer, error) {
+	for _, p := range players {
+		if strings.EqualFold(p.Name, s) {
+			return p, nil
+		}
+	}
+	if id, err := strconv.Atoi(s); err == nil {
+		for _, p := range players {
+			if p.ID == int32(id) {
+				return p, nil
+			}
+		}
+	}
+	return types.RemotePlayer{}, errors.Errorf("no player %q", s)
+}
+
+// playerNames returns the words naming players, their id when they
+// have no name of one word
+func playerNames(players []types.RemotePlayer) []string {
+	names := make([]string, 0, len(players))
+	for _, p := range players {
+		if p.Name != "" && !strings.Contains(p.Name, " ") {
+			names = append(names, p.Name)
+		} else {
+			names = append(names, strconv.Itoa(int(p.ID)))
+		}
+	}
+	return names
+}
+
+// coords is the Type of Coords
+type coords struct{}
+
+// Coords is three words x y z, each a number or ~ followed by an
+// optional offset from the position of the source. Its value is the
+// mgl32.Vec3
+func Coords() Type {
+	return coords{}
+}
+
+func (coords) Parse(src types.CommandSource, words []string) (interface{}, int, error) {
+	var v mgl32.Vec3
+	for i := range v {
+		if i >= len(words) {
+			return nil, 0, errMoreWords
+		}
+		c, err := coordinate(words[i], src.Pos[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		v[i] = c
+	}
+	return v, 3, nil
+}
+
+func (coords) Complete(types.CommandSource, string) []string {
+	return []string{"~"}
+}
+
+// coordinate parses a coordinate, relative to origin when it starts
+// with ~
+func coordinate(s string, origin float32) (float32, error) {
+	offset := s
+	relative := strings.HasPrefix(s, "~")
+	if relative {
+		offset = s[1:]
+		if offset == "" {
+			return origin, nil
+		}
+	}
+	f, err := strconv.ParseFloat(offset, 32)
+	if err != nil {
+		return 0, errors.Errorf("%q is not a coordinate", s)
+	}
+	if relative {
+		return origin + float32(f), nil
+	}
+	return float32(f), nil
+}
+
+// target is the Type of Target
+type target struct {
+	players func() []types.RemotePlayer
+}
+
+// Target is coordinates or a player of players, its value is the
+// mgl32.Vec3 of the coordinates or of the player
+func Target(players func() []types.RemotePlayer) Type {
+	return target{players: players}
+}
+
+func (t target) Parse(src types.CommandSource, words []string) (interface{}, int, error) {
+	if len(words) == 0 {
+		return nil, 0, errMoreWords
+	}
+	v, n, err := coords{}.Parse(src, words)
+	if err == nil {
+		return v, n, nil
+	}
+	// players without a name go by their id, which looks like a
+	// coordinate
+	if p, perr := findPlayer(t.players(), words[0]); perr == nil {
+		return p.Pos, 1, nil
+	}
+	if _, cerr := coordinate(words[0], 0); cerr == nil {
+		return nil, 0, err
+	}
+	return nil, 0, errors.Errorf("no player %q", words[0])
+}
+
+func (t target) Complete(src types.CommandSource, _ string) []string {
+	return append(playerNames(t.players()), "~")
+}
+
+// text is the Type of Text
+type text struct{}
+
+// Text is the rest of the line, its value is a string
+func Text() Type {
+	return text{}
+}
+
+func (text) Parse(_ types.CommandSource, words []string) (interface{}, int, error) {
+	if len(words) == 0 {
+		return nil, 0, errMoreWords
+	}
+	return strings.Join(words, " "), len(words), nil
+}
+
+func (text) Complete(types.CommandSource, string) []string {
+	return nil
+}
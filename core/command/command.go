@@ -0,0 +1,287 @@
+// Package command has the registry of slash commands, their typed
+// arguments, permissions, usage and tab completion
+package command
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/pkg/errors"
+)
+
+// Command is a slash command, or a subcommand selected by its name
+// after the words of its parent. A command with subcommands may also
+// have Args and Run, used when no subcommand name follows
+type Command struct {
+	Name string
+	// Help is a line about what the command does
+	Help       string
+	Permission types.Permission
+	Args       []Arg
+	Sub        []*Command
+	// Run runs the command with its parsed arguments, it returns the
+	// message to show the source
+	Run func(src types.CommandSource, args Args) (string, error)
+}
+
+// Usage returns the usage of c and its subcommands, prefix is what
+// comes before its name, like "/" or "/time ". Alternatives are
+// separated by |
+func (c *Command) Usage(prefix string) string {
+	name := prefix + c.Name
+	var forms []string
+	if c.Run != nil {
+		form := name
+		for _, a := range c.Args {
+			form += " " + a.usage()
+		}
+		forms = append(forms, form)
+	}
+	for _, sub := range c.Sub {
+		forms = append(forms, sub.Usage(name+" "))
+	}
+	return strings.Join(forms, " | ")
+}
+
+func (c *Command) sub(name string) *Command {
+	for _, sub := range c.Sub {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// resolve returns the subcommand of c the words select, the words
+// after its name and its name with those of its parents, like
+// "/time set"
+func (c *Command) resolve(words []string) (*Command, []string, string) {
+	prefix := "/" + c.Name
+	for len(words) > 0 {
+		sub := c.sub(words[0])
+		if sub == nil {
+			break
+		}
+		prefix += " " + sub.Name
+		c, words = sub, words[1:]
+	}
+	return c, words, prefix
+}
+
+// allowed tells if src may run c or any of its subcommands
+func (c *Command) allowed(src types.CommandSource) bool {
+	if c.Run != nil && src.Permission >= c.Permission {
+		return true
+	}
+	for _, sub := range c.Sub {
+		if sub.allowed(src) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry is the commands that can be run, by name
+type Registry struct {
+	mx       sync.Mutex
+	commands map[string]*Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]*Command)}
+}
+
+// Register adds c, replacing a command of the same name
+func (r *Registry) Register(c *Command) {
+	r.mx.Lock()
+	r.commands[c.Name] = c
+	r.mx.Unlock()
+}
+
+// Get returns the command name, or nil
+func (r *Registry) Get(name string) *Command {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.commands[name]
+}
+
+// Commands returns the commands src may run, sorted by name
+func (r *Registry) Commands(src types.CommandSource) []*Command {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	var list []*Command
+	for _, c := range r.commands {
+		if c.allowed(src) {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Run runs line, the leading / is optional
+func (r *Registry) Run(src types.CommandSource, line string) (string, error) {
+	words := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "/"))
+	if len(words) == 0 {
+		return "", errors.New("empty command")
+	}
+	root := r.Get(words[0])
+	if root == nil || !root.allowed(src) {
+		return "", errors.Errorf("unknown command %q, see /help", words[0])
+	}
+
+	c, rest, prefix := root.resolve(words[1:])
+	if c.Run == nil {
+		return "", errors.Errorf("usage: %s", root.Usage("/"))
+	}
+	if src.Permission < c.Permission {
+		return "", errors.Errorf("you may not use %s", prefix)
+	}
+	args, err := parseArgs(src, c.Args, rest)
+	if err != nil {
+		return "", errors.Errorf("%s, usage: %s", err, c.Usage(strings.TrimSuffix(prefix, c.Name)))
+	}
+	return c.Run(src, args)
+}
+
+// Permission returns the permission needed to run line,
+// PermissionPlayer when it isn't a command
+func (r *Registry) Permission(line string) types.Permission {
+	words := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "/"))
+	if len(words) == 0 {
+		return types.PermissionPlayer
+	}
+	root := r.Get(words[0])
+	if root == nil {
+		return types.PermissionPlayer
+	}
+	c, _, _ := root.resolve(words[1:])
+	return c.Permission
+}
+
+// Complete returns the lines line can be completed to by completing
+// its last word, the word being typed
+func (r *Registry) Complete(src types.CommandSource, line string) []string {
+	if !strings.HasPrefix(line, "/") {
+		return nil
+	}
+	words := strings.Fields(line[1:])
+	// a line ending in a space starts a new word
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	done, word := words[:len(words)-1], words[len(words)-1]
+	head := line[:len(line)-len(word)]
+
+	var options []string
+	if len(done) == 0 {
+		for _, c := range r.Commands(src) {
+			options = append(options, c.Name)
+		}
+	} else if c := r.Get(done[0]); c != nil && c.allowed(src) {
+		options = c.complete(src, done[1:], word)
+	}
+
+	var lines []string
+	for _, o := range options {
+		if strings.HasPrefix(o, word) {
+			lines = append(lines, head+o)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// complete returns the options for the word after the words done
+func (c *Command) complete(src types.CommandSource, done []string, word string) []string {
+	if len(done) > 0 {
+		if sub := c.sub(done[0]); sub != nil {
+			return sub.complete(src, done[1:], word)
+		}
+	}
+	var options []string
+	if len(done) == 0 {
+		for _, sub := range c.Sub {
+			if sub.allowed(src) {
+				options = append(options, sub.Name)
+			}
+		}
+	}
+	if c.Run == nil || src.Permission < c.Permission {
+		return options
+	}
+
+	// the word is in the first argument the words done don't fill
+	rest := done
+	for _, a := range c.Args {
+		_, n, err := a.Type.Parse(src, rest)
+		if err == errMoreWords {
+			return append(options, a.Type.Complete(src, word)...)
+		}
+		if err != nil {
+			return options
+		}
+		rest = rest[n:]
+	}
+	return options
+}
+
+// parseArgs parses words into args, missing optional arguments are
+// left out
+func parseArgs(src types.CommandSource, spec []Arg, words []string) (Args, error) {
+	args := make(Args)
+	for _, a := range spec {
+		if len(words) == 0 && a.Optional {
+			break
+		}
+		v, n, err := a.Type.Parse(src, words)
+		if err == errMoreWords {
+			return nil, errors.Errorf("missing %s", a.Name)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", a.Name)
+		}
+		args[a.Name] = v
+		words = words[n:]
+	}
+	if len(words) > 0 {
+		return nil, errors.Errorf("too many arguments")
+	}
+	return args, nil
+}
+
+// Help returns the /help command, listing the commands of r the source
+// may run or showing the usage of one
+func Help(r *Registry) *Command {
+	names := func(src types.CommandSource) []string {
+		var names []string
+		for _, c := range r.Commands(src) {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+	return &Command{
+		Name: "help",
+		Help: "lists the commands or shows how to use one",
+		Args: []Arg{{
+			Name: "command",
+			Type: word{
+				parse:   func(_ types.CommandSource, s string) (interface{}, error) { return strings.TrimPrefix(s, "/"), nil },
+				options: names,
+			},
+			Optional: true,
+		}},
+		Run: func(src types.CommandSource, args Args) (string, error) {
+			if !args.Has("command") {
+				return "commands: /" + strings.Join(names(src), ", /"), nil
+			}
+			c := r.Get(args.String("command"))
+			if c == nil || !c.allowed(src) {
+				return "", errors.Errorf("unknown command %q", args.String("command"))
+			}
+			return c.Help + ", usage: " + c.Usage("/"), nil
+		},
+	}
+}
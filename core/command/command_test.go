@@ -0,0 +1,191 @@
+package command
+
+import (
+	"os"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	block.InitRegister()
+	os.Exit(m.Run())
+}
+
+var (
+	player   = types.CommandSource{Name: "steve", Pos: mgl32.Vec3{10, 20, 30}}
+	operator = types.CommandSource{Name: "op", Permission: types.PermissionOperator, Pos: mgl32.Vec3{10, 20, 30}}
+	others   = func() []types.RemotePlayer {
+		return []types.RemotePlayer{
+			{ID: 1, Name: "alex", Pos: mgl32.Vec3{1, 2, 3}},
+			{ID: 2, Name: "Player 2", Pos: mgl32.Vec3{4, 5, 6}},
+		}
+	}
+)
+
+// newTestRegistry has /echo, /tp and /set with a subcommand
+func newTestRegistry(got *Args) *Registry {
+	run := func(_ types.CommandSource, args Args) (string, error) {
+		*got = args
+		return "ok", nil
+	}
+	r := NewRegistry()
+	r.Register(Help(r))
+	r.Register(&Command{
+		Name: "echo",
+		Help: "says something",
+		Args: []Arg{{Name: "message", Type: Text(), Optional: true}},
+		Run:  run,
+	})
+	r.Register(&Command{
+		Name:       "tp",
+		Help:       "moves",
+		Permission: types.PermissionOperator,
+		Args:       []Arg{{Name: "destination", Type: Target(others)}},
+		Run:        run,
+	})
+	r.Register(&Command{
+		Name: "set",
+		Help: "changes things",
+		Sub: []*Command{{
+			Name:       "block",
+			Permission: types.PermissionOperator,
+			Args: []Arg{
+				{Name: "x y z", Type: Coords()},
+				{Name: "block", Type: Block()},
+				{Name: "count", Type: Int(1, 64), Optional: true},
+			},
+			Run: run,
+		}, {
+			Name: "mode",
+			Args: []Arg{{Name: "mode", Type: Choice("fast", "fancy")}},
+			Run:  run,
+		}},
+	})
+	return r
+}
+
+func TestRun(t *testing.T) {
+	var args Args
+	r := newTestRegistry(&args)
+
+	msg, err := r.Run(player, "/echo hello  world")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", msg)
+	assert.Equal(t, "hello world", args.String("message"))
+	_, err = r.Run(player, "echo")
+	require.NoError(t, err, "the slash is optional")
+	assert.False(t, args.Has("message"))
+
+	_, err = r.Run(operator, "/set block ~ ~1 5.5 stone 3")
+	require.NoError(t, err)
+	assert.Equal(t, mgl32.Vec3{10, 21, 5.5}, args.Vec3("x y z"))
+	assert.Equal(t, block.StoneID, args.Block("block").ID)
+	assert.Equal(t, 3, args.Int("count"))
+
+	_, err = r.Run(player, "/set mode FANCY")
+	require.NoError(t, err)
+	assert.Equal(t, "fancy", args.String("mode"))
+
+	_, err = r.Run(operator, "/tp alex")
+	require.NoError(t, err)
+	assert.Equal(t, mgl32.Vec3{1, 2, 3}, args.Vec3("destination"))
+	_, err = r.Run(operator, "/tp 2")
+	require.NoError(t, err, "players are found by id too")
+	assert.Equal(t, mgl32.Vec3{4, 5, 6}, args.Vec3("destination"))
+	_, err = r.Run(operator, "/tp ~-10 0 ~")
+	require.NoError(t, err)
+	assert.Equal(t, mgl32.Vec3{0, 0, 30}, args.Vec3("destination"))
+}
+
+func TestRunErrors(t *testing.T) {
+	var args Args
+	r := newTestRegistry(&args)
+
+	for line, want := range map[string]string{
+		"":                       "empty command",
+		"/nope":                  `unknown command "nope", see /help`,
+		"/tp alex":               `unknown command "tp", see /help`,
+		"/set block 1 2 3 stone": "you may not use /set block",
+		"/set":                   "usage: /set block <x y z> <block> [count] | /set mode <mode>",
+		"/set mode slow":         `invalid mode: "slow" is not one of fast, fancy, usage: /set mode <mode>`,
+		"/set mode fast now":     "too many arguments, usage: /set mode <mode>",
+	} {
+		_, err := r.Run(player, line)
+		if assert.Error(t, err, line) {
+			assert.Equal(t, want, err.Error(), line)
+		}
+	}
+
+	for line, want := range map[string]string{
+		"/set block 1 2":           "missing x y z",
+		"/set block 1 2 a stone":   `invalid x y z: "a" is not a coordinate`,
+		"/set block 1 2 3 cheese":  `invalid block: unknown block "cheese"`,
+		"/set block 1 2 3 dirt 65": "invalid count: 65 is not within 1 and 64",
+		"/tp bob":                  `invalid destination: no player "bob"`,
+	} {
+		_, err := r.Run(operator, line)
+		if assert.Error(t, err, line) {
+			assert.Contains(t, err.Error(), want, line)
+		}
+	}
+}
+
+func TestPermission(t *testing.T) {
+	var args Args
+	r := newTestRegistry(&args)
+	assert.Equal(t, types.PermissionOperator, r.Permission("/set block 1 2 3 stone"))
+	assert.Equal(t, types.PermissionOperator, r.Permission("tp"))
+	assert.Equal(t, types.PermissionPlayer, r.Permission("/set mode fast"))
+	assert.Equal(t, types.PermissionPlayer, r.Permission("/nope"))
+	assert.Equal(t, types.PermissionPlayer, r.Permission(""))
+}
+
+func TestComplete(t *testing.T) {
+	var args Args
+	r := newTestRegistry(&args)
+
+	assert.Equal(t, []string{"/echo", "/help", "/set"}, r.Complete(player, "/"))
+	assert.Equal(t, []string{"/echo", "/help", "/set", "/tp"}, r.Complete(operator, "/"))
+	assert.Equal(t, []string{"/set mode"}, r.Complete(player, "/set "), "subcommands need permission")
+	assert.Equal(t, []string{"/set block", "/set mode"}, r.Complete(operator, "/set "))
+	assert.Equal(t, []string{"/set mode fancy", "/set mode fast"}, r.Complete(player, "/set mode fa"))
+	assert.Equal(t, []string{"/set block 1 ~"}, r.Complete(operator, "/set block 1 "))
+	assert.Equal(t, []string{"/set block 1 2 3 stone"}, r.Complete(operator, "/set block 1 2 3 ston"))
+	assert.Equal(t, []string{"/tp 2", "/tp alex", "/tp ~"}, r.Complete(operator, "/tp "))
+	assert.Equal(t, []string{"/help echo"}, r.Complete(player, "/help e"))
+	assert.Empty(t, r.Complete(player, "/echo hi"))
+	assert.Empty(t, r.Complete(player, "/set mode fast "))
+	assert.Empty(t, r.Complete(player, "hello"), "chat lines are not completed")
+}
+
+func TestHelp(t *testing.T) {
+	var args Args
+	r := newTestRegistry(&args)
+
+	msg, err := r.Run(player, "/help")
+	require.NoError(t, err)
+	assert.Equal(t, "commands: /echo, /help, /set", msg)
+
+	msg, err = r.Run(player, "/help /set")
+	require.NoError(t, err)
+	assert.Equal(t, "changes things, usage: /set block <x y z> <block> [count] | /set mode <mode>", msg)
+
+	_, err = r.Run(player, "/help tp")
+	assert.Error(t, err)
+}
+
+func TestPlayer(t *testing.T) {
+	p := Player(others)
+	v, n, err := p.Parse(player, []string{"ALEX", "rest"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, int32(1), v.(types.RemotePlayer).ID)
+	_, _, err = p.Parse(player, []string{"3"})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"alex", "2"}, p.Complete(player, ""), "players without a name of one word go by id")
+}
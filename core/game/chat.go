@@ -0,0 +1,125 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/artheus/go-minecraft/core/chat"
+	"github.com/artheus/go-minecraft/core/game/rpc"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// chatPage is how many messages PageUp and PageDown scroll the log
+const chatPage = 10
+
+func (g *Application) Chat() types.IChat {
+	return g.chat
+}
+
+// openChat opens the chat console with the cursor free, skip drops
+// the character typed by the key that opened it
+func (g *Application) openChat(skip bool) {
+	g.chat.Open("")
+	g.skipChar = skip
+	g.setExclusiveMouse(false)
+}
+
+func (g *Application) closeChat() {
+	g.chat.Close()
+	g.setExclusiveMouse(true)
+}
+
+// onCharCallback types the characters into the open chat console
+func (g *Application) onCharCallback(_ *glfw.Window, char rune) {
+	if g.skipChar {
+		g.skipChar = false
+		return
+	}
+	if g.chat.Visible() {
+		g.chat.Insert(char)
+	}
+}
+
+// onChatKey edits the line of the open chat console, keys held down
+// repeat
+func (g *Application) onChatKey(key glfw.Key, action glfw.Action) {
+	if action == glfw.Release {
+		return
+	}
+	switch key {
+	case glfw.KeyEscape:
+		g.closeChat()
+		g.escClosed = true
+	case glfw.KeyEnter, glfw.KeyKPEnter:
+		line := g.chat.Submit()
+		g.setExclusiveMouse(true)
+		g.sendChat(line)
+	case glfw.KeyTab:
+		src := g.chatSource()
+		g.chat.Complete(func(line string) []string {
+			return g.commands.Complete(src, line)
+		})
+	case glfw.KeyBackspace:
+		g.chat.Backspace()
+	case glfw.KeyDelete:
+		g.chat.Delete()
+	case glfw.KeyLeft:
+		g.chat.Move(-1)
+	case glfw.KeyRight:
+		g.chat.Move(1)
+	case glfw.KeyHome:
+		g.chat.Home()
+	case glfw.KeyEnd:
+		g.chat.End()
+	case glfw.KeyUp:
+		g.chat.Up()
+	case glfw.KeyDown:
+		g.chat.Down()
+	case glfw.KeyPageUp:
+		g.chat.ScrollBy(chatPage)
+	case glfw.KeyPageDown:
+		g.chat.ScrollBy(-chatPage)
+	}
+}
+
+// sendChat runs a line starting with / as a command, its result is
+// shown in the chat, other lines are sent to the other players. On a
+// server the commands of operators go through the server, which runs
+// them back here if the player is one
+func (g *Application) sendChat(line string) {
+	if line == "" {
+		return
+	}
+	if strings.HasPrefix(line, "/") {
+		src := g.chatSource()
+		if rpc.Client != nil && g.commands.Permission(line) > src.Permission {
+			go func() {
+				g.showResult(rpc.ClientRunCommand(src.Name, line))
+			}()
+			return
+		}
+		g.showResult(g.commands.Run(src, line))
+		return
+	}
+	name := g.camera.State().Name
+	shown := name
+	if shown == "" {
+		shown = "you"
+	}
+	g.chat.Add(chat.Format(shown, line))
+	go func() {
+		if err := rpc.ClientSendChat(name, line); err != nil {
+			g.chat.Add("§cnot sent: " + err.Error())
+		}
+	}()
+}
+
+// showResult shows the message of a command in the chat, or why it
+// failed
+func (g *Application) showResult(msg string, err error) {
+	if err != nil {
+		g.chat.Add("§c" + err.Error())
+		return
+	}
+	g.chat.Add(msg)
+}
@@ -0,0 +1,26 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendChat(t *testing.T) {
+	g := newTestGame(t)
+	g.RestorePlayer(types.PlayerState{Name: "steve"})
+
+	g.sendChat("hello")
+	g.sendChat("")
+	g.sendChat("/time set noon")
+	g.sendChat("/nope")
+
+	msgs := g.Chat().Messages()
+	require.Len(t, msgs, 3)
+	assert.Equal(t, "<steve> hello", msgs[0].Text)
+	assert.NotEmpty(t, msgs[1].Text, "the result of a command is shown")
+	assert.Contains(t, msgs[2].Text, "unknown command")
+	assert.InDelta(t, 6000, g.clock.TimeOfDay(), 20)
+}
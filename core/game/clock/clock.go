@@ -0,0 +1,164 @@
+package clock
+
+import (
+	"flag"
+	"github.com/go-gl/mathgl/mgl32"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	StartTime = flag.String("time", "", "world time at start (sunrise, day, noon, sunset, night, midnight or ticks), the saved time if empty")
+)
+
+const (
+	// DayLength is the number of ticks in a full day
+	DayLength = 24000
+	// TickRate is the number of ticks per second
+	TickRate = 20
+)
+
+// Times of day, in ticks after sunrise
+const (
+	Sunrise  = 0
+	Day      = 1000
+	Noon     = 6000
+	Sunset   = 12000
+	Night    = 13000
+	Midnight = 18000
+)
+
+var namedTimes = map[string]int64{
+	"sunrise":  Sunrise,
+	"day":      Day,
+	"noon":     Noon,
+	"sunset":   Sunset,
+	"night":    Night,
+	"midnight": Midnight,
+}
+
+var (
+	dayColor    = mgl32.Vec3{0.57, 0.71, 0.77}
+	nightColor  = mgl32.Vec3{0.02, 0.03, 0.07}
+	sunsetColor = mgl32.Vec3{0.93, 0.52, 0.32}
+)
+
+// nightLight is the sky light left at midnight
+const nightLight = 0.15
+
+// Clock is the world time, counted in ticks since the world was created
+type Clock struct {
+	mx    sync.RWMutex
+	ticks int64
+}
+
+func NewClock(ticks int64) *Clock {
+	return &Clock{ticks: ticks}
+}
+
+// Tick advances the clock by one tick
+func (c *Clock) Tick() {
+	c.Add(1)
+}
+
+func (c *Clock) Time() int64 {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return c.ticks
+}
+
+func (c *Clock) Set(ticks int64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.ticks = ticks
+}
+
+func (c *Clock) Add(ticks int64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.ticks += ticks
+}
+
+// TimeOfDay returns the ticks since the last sunrise
+func (c *Clock) TimeOfDay() int64 {
+	t := c.Time() % DayLength
+	if t < 0 {
+		t += DayLength
+	}
+	return t
+}
+
+// SunDirection returns the unit vector pointing towards the sun, it rises
+// in the east (+x), is highest at noon and sets in the west
+func (c *Clock) SunDirection() mgl32.Vec3 {
+	a := 2 * math.Pi * float64(c.TimeOfDay()) / DayLength
+	return mgl32.Vec3{float32(math.Cos(a)), float32(math.Sin(a)), 0.2}.Normalize()
+}
+
+// MoonDirection returns the unit vector pointing towards the moon,
+// which is always opposite of the sun
+func (c *Clock) MoonDirection() mgl32.Vec3 {
+	sun := c.SunDirection()
+	return mgl32.Vec3{-sun.X(), -sun.Y(), sun.Z()}
+}
+
+// LightDirection returns the direction of the sun during the day
+// and the direction of the moon during the night
+func (c *Clock) LightDirection() mgl32.Vec3 {
+	if sun := c.SunDirection(); sun.Y() >= 0 {
+		return sun
+	}
+	return c.MoonDirection()
+}
+
+// Daylight returns the multiplier (0-1) for the sky light
+func (c *Clock) Daylight() float32 {
+	return nightLight + (1-nightLight)*smoothstep(-0.15, 0.3, c.SunDirection().Y())
+}
+
+// SkyColor returns the color of the sky overhead
+func (c *Clock) SkyColor() mgl32.Vec3 {
+	return mix(nightColor, dayColor, smoothstep(-0.2, 0.3, c.SunDirection().Y()))
+}
+
+// FogColor returns the color of the sky at the horizon, which
+// is tinted by the sun while it rises and sets
+func (c *Clock) FogColor() mgl32.Vec3 {
+	h := c.SunDirection().Y()
+	glow := 1 - smoothstep(0, 0.35, float32(math.Abs(float64(h))))
+	return mix(c.SkyColor(), sunsetColor, glow*0.6)
+}
+
+// ParseTime parses a time of day name or a number of ticks
+func ParseTime(s string) (int64, error) {
+	if t, ok := namedTimes[strings.ToLower(s)]; ok {
+		return t, nil
+	}
+	t, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q", s)
+	}
+	return t, nil
+}
+
+func smoothstep(e0, e1, x float32) float32 {
+	t := (x - e0) / (e1 - e0)
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	return t * t * (3 - 2*t)
+}
+
+func mix(a, b mgl32.Vec3, t float32) mgl32.Vec3 {
+	return a.Mul(1 - t).Add(b.Mul(t))
+}
@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	ticks, err := ParseTime("noon")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(Noon), ticks)
+
+	ticks, err = ParseTime("Midnight")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(Midnight), ticks)
+
+	ticks, err = ParseTime("1234")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234), ticks)
+
+	_, err = ParseTime("teatime")
+	assert.Error(t, err)
+}
+
+func TestTimeOfDayWraps(t *testing.T) {
+	c := NewClock(3*DayLength + 42)
+	assert.Equal(t, int64(42), c.TimeOfDay())
+
+	c.Set(-1)
+	assert.Equal(t, int64(DayLength-1), c.TimeOfDay())
+
+	c.Tick()
+	assert.Equal(t, int64(0), c.Time())
+}
+
+func TestSunAndMoon(t *testing.T) {
+	c := NewClock(Noon)
+	assert.Greater(t, c.SunDirection().Y(), float32(0.9))
+	assert.Less(t, c.MoonDirection().Y(), float32(-0.9))
+	assert.Equal(t, c.SunDirection(), c.LightDirection())
+	assert.InDelta(t, 1, c.Daylight(), 1e-6)
+
+	c.Set(Midnight)
+	assert.Less(t, c.SunDirection().Y(), float32(-0.9))
+	assert.Equal(t, c.MoonDirection(), c.LightDirection())
+	assert.InDelta(t, nightLight, c.Daylight(), 1e-6)
+}
+
+func TestSkyColor(t *testing.T) {
+	c := NewClock(Noon)
+	assert.Equal(t, dayColor, c.SkyColor())
+	assert.Equal(t, dayColor, c.FogColor())
+
+	c.Set(Midnight)
+	assert.Equal(t, nightColor, c.SkyColor())
+
+	// the horizon glows while the sun sets
+	c.Set(Sunset)
+	fog, sky := c.FogColor(), c.SkyColor()
+	assert.Greater(t, fog.X()-fog.Z(), sky.X()-sky.Z())
+}
+
+func TestStepper(t *testing.T) {
+	var s Stepper
+	start := time.Unix(1000, 0)
+	n, alpha := s.Advance(start)
+	assert.Equal(t, 0, n, "the first frame starts the clock")
+	assert.Zero(t, alpha)
+
+	n, alpha = s.Advance(start.Add(Step / 4))
+	assert.Equal(t, 0, n)
+	assert.InDelta(t, 0.25, alpha, 1e-6)
+	assert.Equal(t, Step*3/4, s.Wait(start.Add(Step/4)))
+
+	n, alpha = s.Advance(start.Add(Step * 5 / 2))
+	assert.Equal(t, 2, n, "the ticks due since the last frame")
+	assert.InDelta(t, 0.5, alpha, 1e-6)
+
+	// a stalled game runs a bounded number of ticks
+	n, alpha = s.Advance(start.Add(Step*100 + Step*3/4))
+	assert.Equal(t, maxTicks, n)
+	assert.InDelta(t, 0.75, alpha, 1e-6)
+}
+
+func TestStepperFrameRate(t *testing.T) {
+	// the same time runs the same ticks at any frame rate
+	for _, fps := range []int{30, 60, 144, 1000} {
+		var s Stepper
+		start := time.Unix(1000, 0)
+		ticks := 0
+		for i := 0; i <= fps; i++ {
+			n, _ := s.Advance(start.Add(time.Duration(i) * time.Second / time.Duration(fps)))
+			ticks += n
+		}
+		assert.Equal(t, TickRate, ticks, "at %d fps", fps)
+	}
+}
@@ -0,0 +1,39 @@
+package clock
+
+import "time"
+
+// Step is the game time between two ticks
+const Step = time.Second / TickRate
+
+// maxTicks is the most ticks run for a single frame, a game which
+// stalled for longer falls behind instead of racing to catch up
+const maxTicks = 10
+
+// Stepper runs the game in fixed ticks at TickRate whatever the frame
+// rate, every frame runs the ticks due since the one before
+type Stepper struct {
+	last time.Time
+	lag  time.Duration // real time not run as ticks yet
+}
+
+// Advance returns how many ticks are due at now, and how far now is
+// into the next tick from 0 to 1, which frames are interpolated by
+func (s *Stepper) Advance(now time.Time) (int, float32) {
+	if !s.last.IsZero() {
+		s.lag += now.Sub(s.last)
+	}
+	s.last = now
+
+	n := int(s.lag / Step)
+	if n > maxTicks {
+		s.lag -= time.Duration(n-maxTicks) * Step
+		n = maxTicks
+	}
+	s.lag -= time.Duration(n) * Step
+	return n, float32(s.lag) / float32(Step)
+}
+
+// Wait returns how long after now the next tick is due
+func (s *Stepper) Wait(now time.Time) time.Duration {
+	return Step - s.lag - now.Sub(s.last)
+}
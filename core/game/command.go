@@ -0,0 +1,189 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chunk"
+	"github.com/artheus/go-minecraft/core/command"
+	"github.com/artheus/go-minecraft/core/game/rpc"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/inventory"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+)
+
+// maxFill is the most blocks /fill changes at once
+const maxFill = 32768
+
+func (g *Application) Commands() types.ICommands {
+	return g.commands
+}
+
+// registerCommands registers the commands of the game
+func (g *Application) registerCommands() {
+	g.commands.Register(command.Help(g.commands))
+	g.commands.Register(timeCommand(g.clock, func() {
+		go rpc.ClientSetTime(g.clock.Time())
+	}))
+	g.commands.Register(settings.Command(g.applySettings))
+
+	players := g.playerRenderer.Players
+	g.commands.Register(&command.Command{
+		Name:       "tp",
+		Help:       "teleports you to a position or to another player",
+		Permission: types.PermissionOperator,
+		Args:       []command.Arg{{Name: "destination", Type: command.Target(players)}},
+		Run: func(_ types.CommandSource, args command.Args) (string, error) {
+			pos := args.Vec3("destination")
+			g.camera.SetPos(pos)
+			return fmt.Sprintf("teleported to %.1f %.1f %.1f", pos.X(), pos.Y(), pos.Z()), nil
+		},
+	})
+	g.commands.Register(&command.Command{
+		Name:       "give",
+		Help:       "puts blocks in your inventory",
+		Permission: types.PermissionOperator,
+		Args: []command.Arg{
+			{Name: "block", Type: command.Block()},
+			{Name: "count", Type: command.Int(1, inventory.Slots*inventory.MaxStack), Optional: true},
+		},
+		Run: func(_ types.CommandSource, args command.Args) (string, error) {
+			return g.give(args.Block("block"), args.Int("count"))
+		},
+	})
+	g.commands.Register(&command.Command{
+		Name:       "setblock",
+		Help:       "changes a block",
+		Permission: types.PermissionOperator,
+		Args: []command.Arg{
+			{Name: "x y z", Type: command.Coords()},
+			{Name: "block", Type: command.Block()},
+		},
+		Run: func(_ types.CommandSource, args command.Args) (string, error) {
+			pos := args.Vec3("x y z")
+			return g.fill(pos, pos, args.Block("block"))
+		},
+	})
+	g.commands.Register(&command.Command{
+		Name:       "fill",
+		Help:       "fills a box with a block",
+		Permission: types.PermissionOperator,
+		Args: []command.Arg{
+			{Name: "from", Type: command.Coords()},
+			{Name: "to", Type: command.Coords()},
+			{Name: "block", Type: command.Block()},
+		},
+		Run: func(_ types.CommandSource, args command.Args) (string, error) {
+			return g.fill(args.Vec3("from"), args.Vec3("to"), args.Block("block"))
+		},
+	})
+}
+
+// give adds count of block b to the inventory, a stack when count is 0
+func (g *Application) give(b *block.Block, count int) (string, error) {
+	if !g.carried(b.ID) {
+		return "", errors.Errorf("%s can't be carried", b.ID)
+	}
+	if count == 0 {
+		count = inventory.MaxStackOf(b.ID)
+	}
+	rest := g.inventory.Add(types.ItemStack{ID: b.ID, Count: count})
+	if rest.Count == count {
+		return "", errors.New("the inventory is full")
+	}
+	return fmt.Sprintf("gave %d %s", count-rest.Count, b.ID), nil
+}
+
+// carried tells if block id can be in the inventory
+func (g *Application) carried(id string) bool {
+	for _, key := range g.itemKeys {
+		if key == id {
+			return true
+		}
+	}
+	return false
+}
+
+// fill sets the blocks of the box from the block at from to the one at
+// to to b, both included. They are changed together, and sent to the
+// server one by one in the background
+func (g *Application) fill(from, to mgl32.Vec3, b *block.Block) (string, error) {
+	p0, p1 := chunk.NearBlock(from), chunk.NearBlock(to)
+	min := Vec3{X: Min(p0.X, p1.X), Y: Min(p0.Y, p1.Y), Z: Min(p0.Z, p1.Z)}
+	max := Vec3{X: Max(p0.X, p1.X), Y: Max(p0.Y, p1.Y), Z: Max(p0.Z, p1.Z)}
+	size := (max.X - min.X + 1) * (max.Y - min.Y + 1) * (max.Z - min.Z + 1)
+	if size > maxFill {
+		return "", errors.Errorf("%.0f blocks is more than %d", size, maxFill)
+	}
+	blocks := make(map[Vec3]*block.Block, int(size))
+	for x := min.X; x <= max.X; x++ {
+		for y := min.Y; y <= max.Y; y++ {
+			for z := min.Z; z <= max.Z; z++ {
+				blocks[Vec3{X: x, Y: y, Z: z}] = b
+			}
+		}
+	}
+	g.world.UpdateBlocks(blocks)
+	go rpc.ClientUpdateBlocks(blocks)
+	return fmt.Sprintf("changed %.0f blocks to %s", size, b.ID), nil
+}
+
+// chatSource is the player typing commands in the chat, an operator
+// when playing alone. On a server the server tells who is one
+func (g *Application) chatSource() types.CommandSource {
+	src := types.CommandSource{Name: g.camera.State().Name, Pos: g.camera.Pos()}
+	if rpc.Client == nil {
+		src.Permission = types.PermissionOperator
+	}
+	return src
+}
+
+// RunCommand runs line for src at the next tick and waits for its
+// result. Commands from stdin and the server come in on goroutines of
+// their own, run there they would race the ticks
+func (g *Application) RunCommand(src types.CommandSource, line string) (msg string, err error) {
+	if err := g.RunOnTick(func() {
+		msg, err = g.commands.Run(src, line)
+	}); err != nil {
+		return "", err
+	}
+	return msg, err
+}
+
+// consoleSource is the one typing commands on stdin
+func (g *Application) consoleSource() types.CommandSource {
+	return types.CommandSource{Name: "console", Permission: types.PermissionOperator, Pos: g.camera.Pos()}
+}
+
+// commandLoop runs the slash commands read from r, one per line
+func (g *Application) commandLoop(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		msg, err := g.RunCommand(g.consoleSource(), line)
+		if err != nil {
+			log.Printf("%s: %s", line, err)
+			continue
+		}
+		log.Print(msg)
+	}
+}
+
+// applySettings resizes what depends on the settings and saves them,
+// the renderers read the others every frame
+func (g *Application) applySettings() {
+	g.world.Resize(settings.Current().RenderRadius)
+	if err := settings.SaveCurrent(); err != nil {
+		log.Printf("save settings: %s", err)
+	}
+}
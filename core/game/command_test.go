@@ -0,0 +1,78 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/game/store"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameCommands(t *testing.T) {
+	g := newTestGame(t)
+	g.RestorePlayer(types.PlayerState{X: 2, Y: 100, Z: 2, Inventory: []types.ItemStack{}})
+	require.Len(t, g.World().Chunks([]Vec3{{}}), 1)
+	src := g.consoleSource()
+
+	msg, err := g.Commands().Run(src, "/give glass 3")
+	require.NoError(t, err)
+	assert.Equal(t, "gave 3 core:glass", msg)
+	assert.Equal(t, types.ItemStack{ID: block.GlassID, Count: 3}, g.inventory.Slot(0))
+	_, err = g.Commands().Run(src, "/give cloud")
+	assert.Error(t, err, "clouds can't be carried")
+
+	_, err = g.Commands().Run(src, "/setblock ~ ~ ~ stone")
+	require.NoError(t, err)
+	assert.Equal(t, block.StoneID, g.World().Block(Vec3{X: 2, Y: 100, Z: 2}).ID)
+
+	msg, err = g.Commands().Run(src, "/fill 0 101 0 ~1 ~2 1 core:lamp")
+	require.NoError(t, err)
+	assert.Equal(t, "changed 16 blocks to core:lamp", msg)
+	assert.Equal(t, block.LampID, g.World().Block(Vec3{X: 3, Y: 102, Z: 1}).ID)
+	_, torch := g.World().Light(Vec3{X: 3, Y: 103, Z: 1})
+	assert.InDelta(t, float32(block.GetBlock(block.LampID).LightLevel-1)/block.MaxLightLevel, torch, 1e-6, "lit once filled")
+	saved := 0
+	require.NoError(t, store.Storage.RangeBlocks(Vec3{}, func(_ Vec3, w *block.Block) {
+		if w.ID == block.LampID {
+			saved++
+		}
+	}))
+	assert.Equal(t, 16, saved)
+	_, err = g.Commands().Run(src, "/fill 0 0 0 100 100 100 air")
+	assert.Error(t, err, "too many blocks")
+
+	_, err = g.Commands().Run(src, "/tp ~ 120 ~-2")
+	require.NoError(t, err)
+	assert.Equal(t, mgl32.Vec3{2, 120, 0}, g.Camera().Pos())
+
+	_, err = g.Commands().Run(types.CommandSource{}, "/tp 0 0 0")
+	assert.Error(t, err, "players may not teleport")
+	assert.Contains(t, g.Commands().Complete(src, "/ti"), "/time")
+}
+
+func TestRunCommandAtTick(t *testing.T) {
+	g := newTestGame(t)
+	g.RestorePlayer(types.PlayerState{Y: 100})
+
+	done := make(chan error)
+	go func() {
+		_, err := g.RunCommand(g.consoleSource(), "/tp 5 100 5")
+		done <- err
+	}()
+	for {
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+			pos := g.Camera().Pos()
+			assert.Equal(t, []float32{5, 5}, []float32{pos.X(), pos.Z()}, "run by a tick")
+			return
+		case <-time.After(time.Millisecond):
+			g.tick()
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package game
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chunk"
+	"github.com/artheus/go-minecraft/core/game/clock"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// memStatsEvery is how often the memory stats shown are read, reading
+// them stops the world
+const memStatsEvery = time.Second / 2
+
+// registerDebugLines adds the lines of the game to the debug overlay
+func (g *Application) registerDebugLines() {
+	g.debugOverlay.Register("game", types.DebugLeft, g.debugGame)
+	g.debugOverlay.Register("position", types.DebugLeft, g.debugPosition)
+	g.debugOverlay.Register("chunks", types.DebugLeft, g.debugChunks)
+
+	var (
+		mem  runtime.MemStats
+		read time.Time
+	)
+	g.debugOverlay.Register("runtime", types.DebugRight, func() []string {
+		if time.Since(read) > memStatsEvery {
+			runtime.ReadMemStats(&mem)
+			read = time.Now()
+		}
+		return debugRuntime(&mem)
+	})
+	g.debugOverlay.Register("target", types.DebugRight, g.debugTarget)
+}
+
+func (g *Application) debugGame() []string {
+	return []string{
+		fmt.Sprintf("gocraft (%d fps)", g.fps.Fps()),
+		fmt.Sprintf("Time: %d (day %d)", g.clock.TimeOfDay(), g.clock.Time()/clock.DayLength+1),
+	}
+}
+
+func (g *Application) debugPosition() []string {
+	p := g.camera.Pos()
+	s := g.camera.State()
+	id := chunk.NearBlock(p)
+	cid := id.ChunkID()
+	sid := chunk.SectionID(id)
+	sky, torch := g.world.Light(id)
+	return []string{
+		fmt.Sprintf("XYZ: %.3f / %.3f / %.3f", p.X(), p.Y(), p.Z()),
+		fmt.Sprintf("Block: %v %v %v", id.X, id.Y, id.Z),
+		fmt.Sprintf("Chunk: %v %v, section %v", cid.X, cid.Z, sid.Y),
+		fmt.Sprintf("Facing: %s (%.1f / %.1f)", facing(g.camera.Front()), s.Rx, s.Ry),
+		fmt.Sprintf("Light: sky %d, block %d", lightLevel(sky), lightLevel(torch)),
+	}
+}
+
+func (g *Application) debugChunks() []string {
+	stat := g.chunkRenderer.State()
+	return []string{
+		fmt.Sprintf("Chunks: %d loaded, %d meshed, %d drawn", g.world.Loaded(), stat.CacheChunks, stat.RendingChunks),
+		fmt.Sprintf("Mesh queue: %d, latency %v", stat.QueuedChunks, stat.MeshLatency.Round(time.Millisecond)),
+		fmt.Sprintf("Faces: %d", stat.Faces),
+	}
+}
+
+// debugTarget shows the block the player is looking at
+func (g *Application) debugTarget() []string {
+	target, _ := g.world.HitTest(g.camera.Pos(), g.camera.Front())
+	if target == nil {
+		return nil
+	}
+	b := g.world.Block(*target)
+	if b == nil {
+		return nil
+	}
+	lines := []string{
+		fmt.Sprintf("Targeted block: %v %v %v", target.X, target.Y, target.Z),
+		b.ID,
+	}
+	return append(lines, blockProperties(b)...)
+}
+
+func debugRuntime(mem *runtime.MemStats) []string {
+	var pause time.Duration
+	if mem.NumGC > 0 {
+		pause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+	}
+	return []string{
+		fmt.Sprintf("%s %d cpus", runtime.Version(), runtime.NumCPU()),
+		fmt.Sprintf("Heap: %d / %d MB", mem.HeapAlloc>>20, mem.HeapSys>>20),
+		fmt.Sprintf("GC: %d, last pause %v", mem.NumGC, pause),
+		fmt.Sprintf("Goroutines: %d", runtime.NumGoroutine()),
+	}
+}
+
+// blockProperties lists the properties of b that are set, sorted
+func blockProperties(b *block.Block) []string {
+	flags := map[string]bool{
+		"breakable":   b.Breakable,
+		"liquid":      b.Liquid,
+		"transparent": b.Transparent,
+		"translucent": b.Translucent,
+		"obstacle":    b.Obstacle,
+		"plant":       b.Plant,
+	}
+	var set []string
+	for name, ok := range flags {
+		if ok {
+			set = append(set, name)
+		}
+	}
+	sort.Strings(set)
+	var lines []string
+	if len(set) > 0 {
+		lines = append(lines, strings.Join(set, ", "))
+	}
+	if b.Material != "" {
+		lines = append(lines, "material: "+b.Material)
+	}
+	if b.Hardness > 0 {
+		lines = append(lines, fmt.Sprintf("hardness: %v", b.Hardness))
+	}
+	if b.Liquid {
+		lines = append(lines, fmt.Sprintf("level: %d", b.LiquidLevel))
+	}
+	if b.LightLevel > 0 {
+		lines = append(lines, fmt.Sprintf("light: %d", b.LightLevel))
+	}
+	return lines
+}
+
+// facing returns the compass direction of front, north is towards -z
+func facing(front mgl32.Vec3) string {
+	x, z := front.X(), front.Z()
+	if Abs(x) > Abs(z) {
+		if x > 0 {
+			return "east (+x)"
+		}
+		return "west (-x)"
+	}
+	if z > 0 {
+		return "south (+z)"
+	}
+	return "north (-z)"
+}
+
+// lightLevel turns a light of 0-1 into a level of 0-15
+func lightLevel(l float32) int {
+	return int(Round(l * block.MaxLightLevel))
+}
@@ -0,0 +1,22 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFacing(t *testing.T) {
+	assert.Equal(t, "north (-z)", facing(mgl32.Vec3{0.1, 0, -1}))
+	assert.Equal(t, "south (+z)", facing(mgl32.Vec3{-0.1, 0.5, 1}))
+	assert.Equal(t, "east (+x)", facing(mgl32.Vec3{1, -1, 0.5}))
+	assert.Equal(t, "west (-x)", facing(mgl32.Vec3{-1, 0, 0}))
+}
+
+func TestBlockProperties(t *testing.T) {
+	b := &block.Block{ID: "glass", Transparent: true, Breakable: true, Obstacle: true, Hardness: 0.3}
+	assert.Equal(t, []string{"breakable, obstacle, transparent", "hardness: 0.3"}, blockProperties(b))
+	assert.Empty(t, blockProperties(&block.Block{ID: "air"}))
+}
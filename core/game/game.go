@@ -1,26 +1,36 @@
 package game
 
 import (
-	"fmt"
-	"github.com/artheus/go-events"
-	evttypes "github.com/artheus/go-events/types"
+	"flag"
 	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chat"
 	"github.com/artheus/go-minecraft/core/chunk"
+	"github.com/artheus/go-minecraft/core/command"
 	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/clock"
 	"github.com/artheus/go-minecraft/core/game/rpc"
 	"github.com/artheus/go-minecraft/core/game/world"
 	"github.com/artheus/go-minecraft/core/hud"
+	"github.com/artheus/go-minecraft/core/minimap"
+	"github.com/artheus/go-minecraft/core/inventory"
+	"github.com/artheus/go-minecraft/core/particle"
 	"github.com/artheus/go-minecraft/core/player"
+	"github.com/artheus/go-minecraft/core/sky"
 	"github.com/artheus/go-minecraft/core/types"
 	. "github.com/artheus/go-minecraft/math/f32"
 	"github.com/faiface/mainthread"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
 	"log"
+	"os"
 	"time"
 )
 
+// MaxFPS caps the frame rate, for drivers that don't wait for vsync
+var MaxFPS = flag.Int("maxfps", 240, "most frames drawn per second, 0 to leave it to vsync")
+
 func InitGL(w, h int) *glfw.Window {
 	err := glfw.Init()
 	if err != nil {
@@ -31,6 +41,10 @@ func InitGL(w, h int) *glfw.Window {
 	glfw.WindowHint(glfw.ContextVersionMinor, 3)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, gl.TRUE)
+	// a single frame for -screenshot is rendered offscreen
+	if *ScreenshotPath != "" {
+		glfw.WindowHint(glfw.Visible, glfw.False)
+	}
 
 	win, err := glfw.CreateWindow(w, h, "gocraft", nil, nil)
 	if err != nil {
@@ -50,7 +64,6 @@ func InitGL(w, h int) *glfw.Window {
 
 type Application struct {
 	Ctx          *ctx.Context
-	evtPublisher evttypes.Publisher
 	window       *glfw.Window
 
 	camera   *player.Camera
@@ -58,22 +71,60 @@ type Application struct {
 	vy       float32
 	prevtime float64
 
-	lineRenderer   *hud.LineRenderer
-	chunkRenderer  *chunk.ChunkRenderer
-	playerRenderer *player.PlayerRenderer
+	lineRenderer   types.ILineRenderer
+	textRenderer   types.ITextRenderer
+	debugOverlay   types.IDebugOverlay
+	chunkRenderer  types.IChunkRenderer
+	playerRenderer types.IPlayerRenderer
+	skyRenderer    types.ISkyRenderer
+	inventoryRenderer types.IInventoryRenderer
+	chatRenderer      types.IRenderer
+	minimap           types.IMinimap
+
+	particleRenderer types.IParticleRenderer
 
 	world    *world.World
-	itemidx  int
-	itemKeys []string
-	item     *block.Block
+	clock    *clock.Clock
+	stepper  clock.Stepper // runs the ticks due every frame
+	itemKeys  []string // blocks that can be carried
+	inventory *inventory.Inventory
+	hotbar    *inventory.Hotbar
+	heldItem  string // block the held item is drawn for
+	chat      *chat.Console
+	commands  *command.Registry
+	queued    chan func() // run at the next tick, see RunOnTick
+	skipChar  bool // the key opening the chat also types a character
+	escClosed bool // Escape closed the chat and is still held
+	forwardTap float64 // when forward was last pressed
 	fps      hud.FPS
 
+	fbo            *types.Framebuffer // the frame is rendered into
+	takeScreenshot bool
+
 	exclusiveMouse bool
 	closed         bool
+	headless       bool // no window and no renderers
 }
 
 func NewGame(w, h int) (game *Application, err error) {
-	game = new(Application)
+	game = newApplication()
+
+	mainthread.Call(func() {
+		win := InitGL(w, h)
+		win.SetMouseButtonCallback(game.onMouseButtonCallback)
+		win.SetCursorPosCallback(game.onCursorPosCallback)
+		win.SetFramebufferSizeCallback(game.onFrameBufferSizeCallback)
+		win.SetKeyCallback(game.onKeyCallback)
+		win.SetCharCallback(game.onCharCallback)
+		win.SetScrollCallback(game.onScrollCallback)
+		game.window = win
+	})
+
+	return
+}
+
+func newApplication() *Application {
+	game := new(Application)
 
 	block.InitRegister()
 
@@ -81,59 +132,108 @@ func NewGame(w, h int) (game *Application, err error) {
 		if b.ID == block.AirID {
 			return true
 		}
+		// only liquid sources can be placed, flowing liquid follows from them
+		if b.Liquid && !b.IsSource() {
+			return true
+		}
+		// clouds are drawn by the sky, the block is kept for saved worlds
+		if b.ID == block.CloudID {
+			return true
+		}
 
 		game.itemKeys = append(game.itemKeys, b.ID)
 		return true
 	})
 
-	game.itemidx = 0
-	game.item = block.GetBlock(game.itemKeys[game.itemidx])
-
-	mainthread.Call(func() {
-		win := InitGL(w, h)
-		win.SetMouseButtonCallback(game.onMouseButtonCallback)
-		win.SetCursorPosCallback(game.onCursorPosCallback)
-		win.SetFramebufferSizeCallback(game.onFrameBufferSizeCallback)
-		win.SetKeyCallback(game.onKeyCallback)
-		game.window = win
-	})
-
-	return
+	game.inventory = inventory.New()
+	game.hotbar = inventory.NewHotbar(game.inventory)
+	game.inventory.Restore(game.starterKit())
+	game.chat = chat.New()
+	game.commands = command.NewRegistry()
+	game.queued = make(chan func())
+	return game
 }
 
 func (g *Application) Init(ctx *ctx.Context) (err error) {
 	g.Ctx = ctx
 
-	g.evtPublisher = ctx.EventPipe().Publisher()
+	if g.headless {
+		g.initHeadless(ctx)
+	} else if err = g.initRenderers(ctx); err != nil {
+		return err
+	}
+
+	go g.chunkRenderer.UpdateLoop()
 
+	g.world = world.NewWorld(ctx)
+	g.clock = clock.NewClock(clock.Day)
+	g.camera = player.NewCamera(ctx, mgl32.Vec3{0, 16, 0})
+	go g.minimap.UpdateLoop()
+
+	go g.syncPlayerLoop()
+
+	g.registerCommands()
+	if g.headless || *StdinCommands {
+		go g.commandLoop(os.Stdin)
+	}
+
+	g.registerDebugLines()
+
+	return nil
+}
+
+func (g *Application) initRenderers(ctx *ctx.Context) (err error) {
 	g.chunkRenderer, err = chunk.NewChunkRenderer(ctx)
 	if err != nil {
 		return err
 	}
 
 	mainthread.Call(func() {
-		g.chunkRenderer.UpdateItem(g.itemKeys[g.itemidx])
+		g.updateItem()
+		g.fbo, err = types.NewFramebuffer(g.window.GetFramebufferSize())
 	})
+	if err != nil {
+		return err
+	}
 
 	g.lineRenderer, err = hud.NewLineRenderer(ctx)
 	if err != nil {
 		return err
 	}
 
-	g.playerRenderer, err = player.NewPlayerRenderer(ctx)
+	g.textRenderer, err = hud.NewTextRenderer(ctx)
 	if err != nil {
 		return err
 	}
+	g.debugOverlay = hud.NewDebugOverlay(ctx)
+	g.chatRenderer = hud.NewChatRenderer(ctx)
 
-	go g.chunkRenderer.UpdateLoop()
+	g.minimap, err = minimap.NewRenderer(ctx)
+	if err != nil {
+		return err
+	}
 
-	g.world = world.NewWorld(ctx)
-	g.camera = player.NewCamera(ctx, mgl32.Vec3{0, 16, 0})
+	g.inventoryRenderer, err = hud.NewInventoryRenderer(ctx, g.itemKeys)
+	if err != nil {
+		return err
+	}
 
-	go g.camera.MovementEventLoop()
+	g.playerRenderer, err = player.NewPlayerRenderer(ctx)
+	if err != nil {
+		return err
+	}
 
-	go g.syncPlayerLoop()
+	g.skyRenderer, err = sky.NewSkyRenderer(ctx)
+	if err != nil {
+		return err
+	}
 
+	particles, err := particle.NewParticleRenderer(ctx)
+	if err != nil {
+		return err
+	}
+	g.particleRenderer = particles
+	go particles.EventLoop()
 	return nil
 }
 
@@ -158,10 +258,26 @@ func (g *Application) Window() *glfw.Window {
 	return g.window
 }
 
+func (g *Application) Clock() types.IClock {
+	return g.clock
+}
+
 func (g *Application) LineRenderer() types.ILineRenderer {
 	return g.lineRenderer
 }
 
+func (g *Application) TextRenderer() types.ITextRenderer {
+	return g.textRenderer
+}
+
+func (g *Application) DebugOverlay() types.IDebugOverlay {
+	return g.debugOverlay
+}
+
+func (g *Application) Minimap() types.IMinimap {
+	return g.minimap
+}
+
 func (g *Application) PlayerRenderer() types.IPlayerRenderer {
 	return g.playerRenderer
 }
@@ -170,38 +286,35 @@ func (g *Application) ChunkRenderer() types.IChunkRenderer {
 	return g.chunkRenderer
 }
 
-func (g *Application) dirtyBlock(id Vec3) {
-	cid := id.ChunkID()
-	g.chunkRenderer.DirtyChunk(cid)
-	neighbors := []Vec3{id.Left(), id.Right(), id.Front(), id.Back()}
-	for _, neighbor := range neighbors {
-		chunkid := neighbor.ChunkID()
-		if chunkid != cid {
-			g.chunkRenderer.DirtyChunk(chunkid)
-		}
-	}
+func (g *Application) ParticleRenderer() types.IParticleRenderer {
+	return g.particleRenderer
 }
 
 func (g *Application) onMouseButtonCallback(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, _ glfw.ModifierKey) {
+	if g.chat.Visible() {
+		return
+	}
+	if g.inventoryRenderer.Visible() {
+		if action == glfw.Press {
+			g.inventory.Click(g.inventoryRenderer.HoveredSlot(), button == glfw.MouseButton2)
+			g.updateItem()
+		}
+		return
+	}
 	if !g.exclusiveMouse {
 		g.setExclusiveMouse(true)
 		return
 	}
-	head := chunk.NearBlock(g.camera.Pos())
-	foot := head.Down()
 	blockInWorld, prev := g.world.HitTest(g.camera.Pos(), g.camera.Front())
 	if button == glfw.MouseButton2 && action == glfw.Press {
-		if prev != nil && *prev != head && *prev != foot {
-			g.world.UpdateBlock(*prev, g.item)
-			g.dirtyBlock(*prev)
-			go rpc.ClientUpdateBlock(*prev, g.item)
+		// blocks aren't placed where the player stands
+		if prev != nil && !g.inPlayer(*prev) {
+			g.placeBlock(*prev)
 		}
 	}
 	if button == glfw.MouseButton1 && action == glfw.Press {
 		if blockInWorld != nil {
-			g.world.UpdateBlock(*blockInWorld, block.GetBlock(block.AirID))
-			g.dirtyBlock(*blockInWorld)
-			go rpc.ClientUpdateBlock(*blockInWorld, block.GetBlock(block.AirID))
+			g.breakBlock(*blockInWorld)
 		}
 	}
 }
@@ -211,6 +324,10 @@ func (g *Application) onFrameBufferSizeCallback(window *glfw.Window, width, heig
 }
 
 func (g *Application) onCursorPosCallback(win *glfw.Window, xpos float64, ypos float64) {
+	if g.inventoryRenderer.Visible() {
+		g.inventoryRenderer.SetCursor(xpos, ypos)
+		return
+	}
 	if !g.exclusiveMouse {
 		return
 	}
@@ -223,69 +340,124 @@ func (g *Application) onCursorPosCallback(win *glfw.Window, xpos float64, ypos f
 	g.camera.OnAngleChange(float32(dx), float32(dy))
 }
 
+// sprintTap is the most seconds between two presses of forward which
+// start sprinting
+const sprintTap = 0.3
+
 func (g *Application) onKeyCallback(win *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if g.chat.Visible() {
+		g.onChatKey(key, action)
+		return
+	}
 	if action != glfw.Press {
 		return
 	}
 	switch key {
+	case glfw.KeyT, glfw.KeyEnter:
+		if !g.inventoryRenderer.Visible() {
+			g.openChat(key == glfw.KeyT)
+		}
+	case glfw.KeySlash:
+		// the slash typed by the key starts the command
+		if !g.inventoryRenderer.Visible() {
+			g.openChat(false)
+		}
+	case glfw.KeyW:
+		// tapping forward twice quickly starts sprinting
+		now := glfw.GetTime()
+		if now-g.forwardTap < sprintTap {
+			g.camera.Move(player.MoveSprint)
+		}
+		g.forwardTap = now
 	case glfw.KeyTab:
 		g.camera.FlipFlying()
+	case glfw.KeyF3:
+		g.debugOverlay.Toggle()
+	case glfw.KeyF2:
+		g.takeScreenshot = true
 	case glfw.KeySpace:
-		block := g.CurrentBlockid()
-		if g.world.HasBlock(Vec3{block.X, block.Y - 2, block.Z}) {
-			_ = g.evtPublisher.Publish(events.Event(time.Now(), &player.EventMove{
-				Move: player.MoveJump,
-				Delta: 8,
-			}))
-		}
+		// the camera jumps on the next tick if it stands on a block
+		g.camera.Move(player.MoveJump)
 	case glfw.KeyE:
-		g.itemidx = (1 + g.itemidx) % len(g.itemKeys)
-		g.item = block.GetBlock(g.itemKeys[g.itemidx])
-		g.chunkRenderer.UpdateItem(g.itemKeys[g.itemidx])
-	case glfw.KeyR:
-		g.itemidx--
-		if g.itemidx < 0 {
-			g.itemidx = len(g.itemKeys) - 1
-		}
-		g.item = block.GetBlock(g.itemKeys[g.itemidx])
-		g.chunkRenderer.UpdateItem(g.itemKeys[g.itemidx])
+		g.toggleInventory()
+	case glfw.KeyM:
+		g.minimap.Zoom()
+	case glfw.KeyN:
+		g.minimap.Rotate()
+	case glfw.Key1, glfw.Key2, glfw.Key3, glfw.Key4, glfw.Key5, glfw.Key6, glfw.Key7, glfw.Key8, glfw.Key9:
+		g.hotbar.Select(int(key - glfw.Key1))
+		g.updateItem()
+	}
+}
+
+// onScrollCallback moves the hotbar selection, scrolling up selects
+// the slot to the left
+func (g *Application) onScrollCallback(_ *glfw.Window, _, yoff float64) {
+	if g.chat.Visible() {
+		g.chat.ScrollBy(int(yoff))
+		return
+	}
+	switch {
+	case yoff > 0:
+		g.hotbar.Scroll(-1)
+	case yoff < 0:
+		g.hotbar.Scroll(1)
+	default:
+		return
+	}
+	g.updateItem()
+}
+
+// updateItem shows the block in the selected slot as the held item,
+// must be called on the main thread
+func (g *Application) updateItem() {
+	if id := g.hotbar.Item(); id != g.heldItem {
+		g.heldItem = id
+		g.chunkRenderer.UpdateItem(id)
 	}
 }
 
 func (g *Application) handleKeyInput() {
-	speed := float32(0.1)
-	if g.camera.Flying() {
-		speed = 0.2
+	// the Escape closing the chat doesn't also free the cursor
+	if g.escClosed && g.window.GetKey(glfw.KeyEscape) == glfw.Release {
+		g.escClosed = false
 	}
-	if g.window.GetKey(glfw.KeyEscape) == glfw.Press {
+	if !g.escClosed && g.window.GetKey(glfw.KeyEscape) == glfw.Press {
+		if g.inventoryRenderer.Visible() {
+			g.toggleInventory()
+		}
 		g.setExclusiveMouse(false)
 	}
+	// the player stands still while moving stacks or typing
+	if g.inventoryRenderer.Visible() || g.chat.Visible() {
+		return
+	}
 	if g.window.GetKey(glfw.KeyW) == glfw.Press {
-		_ = g.evtPublisher.Publish(events.Event(time.Now(), &player.EventMove{
-			Move:  player.MoveForward,
-			Delta: speed,
-		}))
+		g.camera.Move(player.MoveForward)
 	}
 	if g.window.GetKey(glfw.KeyS) == glfw.Press {
-		_ = g.evtPublisher.Publish(events.Event(time.Now(), &player.EventMove{
-			Move:  player.MoveBackward,
-			Delta: speed,
-		}))
+		g.camera.Move(player.MoveBackward)
 	}
 	if g.window.GetKey(glfw.KeyA) == glfw.Press {
-		_ = g.evtPublisher.Publish(events.Event(time.Now(), &player.EventMove{
-			Move:  player.MoveLeft,
-			Delta: speed,
-		}))
+		g.camera.Move(player.MoveLeft)
 	}
 	if g.window.GetKey(glfw.KeyD) == glfw.Press {
-		_ = g.evtPublisher.Publish(events.Event(time.Now(), &player.EventMove{
-			Move:  player.MoveRight,
-			Delta: speed,
-		}))
+		g.camera.Move(player.MoveRight)
+	}
+	if g.window.GetKey(glfw.KeyLeftShift) == glfw.Press {
+		g.camera.Move(player.MoveSneak)
+	}
+	if g.window.GetKey(glfw.KeyLeftControl) == glfw.Press {
+		g.camera.Move(player.MoveSprint)
 	}
 }
 
+// inPlayer tells if the block at id would be in the box of the player
+func (g *Application) inPlayer(id Vec3) bool {
+	box := block.FullCuboid.Offset(mgl32.Vec3{id.X, id.Y, id.Z})
+	return box.Overlaps(g.camera.Box())
+}
+
 func (g *Application) CurrentBlockid() Vec3 {
 	pos := g.camera.Pos()
 	return chunk.NearBlock(pos)
@@ -295,17 +467,6 @@ func (g *Application) ShouldClose() bool {
 	return g.closed
 }
 
-func (g *Application) renderStat() {
-	g.fps.Update()
-	p := g.camera.Pos()
-	nb := chunk.NearBlock(p)
-	cid := nb.ChunkID()
-	stat := g.chunkRenderer.State()
-	title := fmt.Sprintf("[%.2f %.2f %.2f] %v [%d/%d %d] %d", p.X(), p.Y(), p.Z(),
-		cid, stat.RendingChunks, stat.CacheChunks, stat.Faces, g.fps.Fps())
-	g.window.SetTitle(title)
-}
-
 func (g *Application) syncPlayerLoop() {
 	tick := time.NewTicker(time.Second / 10)
 	for range tick.C {
@@ -313,21 +474,132 @@ func (g *Application) syncPlayerLoop() {
 	}
 }
 
-func (g *Application) Update() {
-	mainthread.Call(func() {
-		g.handleKeyInput()
+// RunOnTick runs f at the next tick and waits for it to return. What
+// comes in from stdin or the server on other goroutines goes through
+// here, so it doesn't race the ticks changing the world and the player
+func (g *Application) RunOnTick(f func()) error {
+	done := make(chan struct{})
+	run := func() {
+		f()
+		close(done)
+	}
+	stopped := g.Ctx.Context().Done()
+	select {
+	case g.queued <- run:
+	case <-stopped:
+		return errors.New("the game stopped")
+	}
+	select {
+	case <-done:
+		return nil
+	case <-stopped:
+		return errors.New("the game stopped")
+	}
+}
 
-		gl.ClearColor(0.57, 0.71, 0.77, 1)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+// runQueued runs the functions queued by RunOnTick
+func (g *Application) runQueued() {
+	for {
+		select {
+		case run := <-g.queued:
+			run()
+		default:
+			return
+		}
+	}
+}
 
+// tick runs one game tick, the player physics and the world advance
+// by clock.Step whatever the frame rate
+func (g *Application) tick() {
+	g.runQueued()
+	g.camera.Tick()
+	g.clock.Tick()
+	g.world.Tick()
+}
+
+// advance runs the ticks due since the last frame, and has the camera
+// show the player between the last two ticks
+func (g *Application) advance() {
+	n, alpha := g.stepper.Advance(time.Now())
+	for i := 0; i < n; i++ {
+		g.tick()
+	}
+	g.camera.Interpolate(alpha)
+}
+
+// renderFrame renders the world and the hud into the framebuffer,
+// must be called on the main thread
+func (g *Application) renderFrame() {
+	if err := g.fbo.Resize(g.window.GetFramebufferSize()); err != nil {
+		log.Print(err)
+	}
+	g.fbo.Begin()
+	defer g.fbo.End()
+
+	fog := g.clock.FogColor()
+	gl.ClearColor(fog.X(), fog.Y(), fog.Z(), 1)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	g.skyRenderer.Render()
+	// particles are opaque, drawn first the chunks hide them by depth
+	// and water is blended over them
+	g.particleRenderer.Render()
+	g.chunkRenderer.Render()
+	g.skyRenderer.RenderClouds()
+	g.playerRenderer.Render()
+	g.lineRenderer.Render()
+	g.minimap.Render()
+	g.inventoryRenderer.Render()
+	g.chatRenderer.Render()
+	g.debugOverlay.Render()
+	g.textRenderer.Render()
+}
+
+func (g *Application) Update() {
+	if g.headless {
+		g.advance()
 		g.chunkRenderer.Render()
-		g.playerRenderer.Render()
-		g.lineRenderer.Render()
+		// nothing is drawn, so the loop waits for the next tick
+		time.Sleep(g.stepper.Wait(time.Now()))
+		return
+	}
+	start := time.Now()
+	iconified := false
+	mainthread.Call(func() {
+		// a minimized window shows nothing, the game only ticks
+		if g.window.GetAttrib(glfw.Iconified) == glfw.True {
+			iconified = true
+			g.advance()
+			glfw.PollEvents()
+			g.closed = g.window.ShouldClose()
+			return
+		}
+		g.handleKeyInput()
+		g.advance()
+		// commands from stdin and rpc change the hotbar too
+		g.updateItem()
+		g.renderFrame()
+
+		if g.takeScreenshot {
+			g.takeScreenshot = false
+			saveScreenshot(g.fbo.Image())
+		}
+		width, height := g.window.GetFramebufferSize()
+		g.fbo.Blit(width, height)
 
-		g.renderStat()
+		// counted for the debug overlay
+		g.fps.Update()
 
 		g.window.SwapBuffers()
 		glfw.PollEvents()
 		g.closed = g.window.ShouldClose()
 	})
+	if iconified {
+		time.Sleep(g.stepper.Wait(time.Now()))
+		return
+	}
+	if *MaxFPS > 0 {
+		time.Sleep(time.Second/time.Duration(*MaxFPS) - time.Since(start))
+	}
 }
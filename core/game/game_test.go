@@ -0,0 +1,33 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/game/store"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGame returns a headless game with a new store, loading the
+// chunks next to the player only
+func newTestGame(t *testing.T) *Application {
+	var err error
+	store.Storage, err = store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Storage.Close() })
+
+	s := settings.Current()
+	t.Cleanup(func() { settings.Set(s) })
+	s.RenderRadius = 1
+	settings.Set(s)
+
+	g, err := NewHeadlessGame()
+	require.NoError(t, err)
+	appCtx, err := ctx.NewContext(g)
+	require.NoError(t, err)
+	t.Cleanup(appCtx.Cancel)
+	require.NoError(t, g.Init(appCtx))
+	return g
+}
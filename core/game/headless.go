@@ -0,0 +1,35 @@
+package game
+
+import (
+	"flag"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/headless"
+)
+
+var Headless = flag.Bool("headless", false, "run the world, player physics, rpc and store without a window")
+
+// StdinCommands reads slash commands from stdin with a window too, a
+// game started from a terminal leaves it alone otherwise
+var StdinCommands = flag.Bool("stdin", false, "read slash commands from stdin, which -headless always does")
+
+// NewHeadlessGame makes a game with no window and no OpenGL, the
+// renderers only keep the chunks around the camera loaded
+func NewHeadlessGame() (*Application, error) {
+	game := newApplication()
+	game.headless = true
+	return game, nil
+}
+
+func (g *Application) initHeadless(ctx *ctx.Context) {
+	g.chunkRenderer = headless.NewChunkRenderer(ctx)
+	g.playerRenderer = headless.PlayerRenderer{}
+	g.lineRenderer = headless.LineRenderer{}
+	g.textRenderer = headless.TextRenderer{}
+	g.debugOverlay = headless.DebugOverlay{}
+	g.chatRenderer = headless.ChatRenderer{}
+	g.minimap = headless.Minimap{}
+	g.inventoryRenderer = headless.InventoryRenderer{}
+	g.skyRenderer = headless.SkyRenderer{}
+	g.particleRenderer = headless.ParticleRenderer{}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/game/store"
+	"github.com/artheus/go-minecraft/core/game/world"
+	"github.com/artheus/go-minecraft/core/player"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadlessGame(t *testing.T) {
+	var err error
+	store.Storage, err = store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Storage.Close()
+
+	s := settings.Current()
+	defer settings.Set(s)
+	s.RenderRadius = 2
+	settings.Set(s)
+
+	g, err := NewHeadlessGame()
+	require.NoError(t, err)
+	appCtx, err := ctx.NewContext(g)
+	require.NoError(t, err)
+	defer appCtx.Cancel()
+	require.NoError(t, g.Init(appCtx))
+	assert.Nil(t, g.Window())
+
+	g.Camera().Restore(types.PlayerState{X: 3, Y: 60, Z: 3, Rx: -90})
+	g.Update()
+	require.Eventually(t, func() bool {
+		return g.ChunkRenderer().State().CacheChunks > 0
+	}, 5*time.Second, 10*time.Millisecond, "chunks around the camera are loaded")
+
+	// gravity runs without a window, the camera lands on the ground
+	require.Eventually(t, func() bool {
+		g.Update()
+		feet := g.CurrentBlockid().Down()
+		b := g.World().Block(feet.Down())
+		return g.Camera().Pos().Y() < 60 && b != nil && b.Obstacle
+	}, 10*time.Second, 10*time.Millisecond, "camera falls to the ground")
+
+	// a tick moves the player the same way at any frame rate, the
+	// frames between two ticks are interpolated
+	g.camera.FlipFlying()
+	g.Camera().SetPos(mgl32.Vec3{3, 100, 3})
+	g.camera.Move(player.MoveRight)
+	g.tick()
+	g.camera.Interpolate(0.5)
+	assert.InDelta(t, 4.5, g.Camera().Pos().X(), 1e-4)
+	g.camera.Interpolate(1)
+	assert.InDelta(t, 6, g.Camera().Pos().X(), 1e-4)
+	g.tick()
+	assert.InDelta(t, 6, g.Camera().Pos().X(), 1e-4, "moves only last a tick")
+	g.camera.FlipFlying()
+
+	// blocks can be changed with no renderer to dirty, the
+	// changes are published for the particles
+	sub := appCtx.EventPipe().Subscriber()
+	id := g.CurrentBlockid().Up()
+	stone := block.GetBlock(block.StoneID)
+	g.World().UpdateBlock(id, stone)
+	assert.True(t, g.World().HasBlock(id))
+	g.World().UpdateBlock(id, block.GetBlock(block.AirID))
+
+	next := func() interface{} {
+		select {
+		case evt := <-sub.Get():
+			return evt.Object()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+	assert.Equal(t, &world.EventBlockPlaced{ID: id, Block: stone}, next())
+	assert.Equal(t, &world.EventBlockBroken{ID: id, Block: stone}, next())
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/game/rpc"
+	"github.com/artheus/go-minecraft/core/inventory"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/mainthread"
+	"log"
+)
+
+func (g *Application) Inventory() types.IInventory {
+	return g.inventory
+}
+
+func (g *Application) Hotbar() types.IHotbar {
+	return g.hotbar
+}
+
+// starterKit is the inventory of a new player, a stack of each block
+// that can be carried
+func (g *Application) starterKit() []types.ItemStack {
+	var stacks []types.ItemStack
+	for _, id := range g.itemKeys {
+		stacks = append(stacks, types.ItemStack{ID: id, Count: inventory.MaxStackOf(id)})
+	}
+	return stacks
+}
+
+// PlayerState returns the state of the camera and the inventory, a
+// stack held in the inventory screen is saved as if it was put back
+func (g *Application) PlayerState() types.PlayerState {
+	state := g.camera.State()
+	saved := inventory.New()
+	saved.Restore(g.inventory.Stacks())
+	lostHeld(saved.Add(g.inventory.Held()))
+	state.Inventory = saved.Stacks()
+	state.Slot = g.hotbar.Selected()
+	return state
+}
+
+// lostHeld logs the part of a held stack there was no room for
+// in the inventory
+func lostHeld(rest types.ItemStack) {
+	if !rest.Empty() {
+		log.Printf("the inventory is full, %d %s held in it are lost", rest.Count, rest.ID)
+	}
+}
+
+// RestorePlayer restores the camera and the inventory, blocks that are
+// no longer known are dropped. A player without a saved inventory gets
+// the starter kit
+func (g *Application) RestorePlayer(state types.PlayerState) {
+	g.camera.Restore(state)
+	stacks := state.Inventory
+	if stacks == nil {
+		stacks = g.starterKit()
+	}
+	stacks = append([]types.ItemStack(nil), stacks...)
+	for i, s := range stacks {
+		if block.GetBlock(s.ID) == nil {
+			stacks[i] = types.ItemStack{}
+		}
+	}
+	g.inventory.Restore(stacks)
+	g.hotbar.Select(state.Slot)
+	if !g.headless {
+		mainthread.Call(g.updateItem)
+	}
+}
+
+// placeBlock places a block of the selected hotbar slot at id
+func (g *Application) placeBlock(id Vec3) {
+	item, ok := g.hotbar.Consume()
+	if !ok {
+		return
+	}
+	b := block.GetBlock(item)
+	g.world.UpdateBlock(id, b)
+	go rpc.ClientUpdateBlock(id, b)
+	g.updateItem()
+}
+
+// breakBlock removes the block at id, its drop is added to the
+// inventory, and lost when the inventory is full
+func (g *Application) breakBlock(id Vec3) {
+	b := g.world.Block(id)
+	air := block.GetBlock(block.AirID)
+	g.world.UpdateBlock(id, air)
+	go rpc.ClientUpdateBlock(id, air)
+	if b == nil {
+		return
+	}
+	if drop := b.Drops(); drop != "" {
+		g.inventory.Add(types.ItemStack{ID: drop, Count: 1})
+		g.updateItem()
+	}
+}
+
+// toggleInventory opens or closes the inventory screen, the cursor is
+// free while it is open. A held stack is put back when it closes
+func (g *Application) toggleInventory() {
+	g.inventoryRenderer.Toggle()
+	if g.inventoryRenderer.Visible() {
+		g.setExclusiveMouse(false)
+		x, y := g.window.GetCursorPos()
+		g.inventoryRenderer.SetCursor(x, y)
+		return
+	}
+	lostHeld(g.inventory.ReturnHeld())
+	g.updateItem()
+	g.setExclusiveMouse(true)
+}
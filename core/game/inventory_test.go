@@ -0,0 +1,72 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/inventory"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestorePlayerInventory(t *testing.T) {
+	g := newTestGame(t)
+
+	g.RestorePlayer(types.PlayerState{Y: 10})
+	stacks := g.inventory.Stacks()
+	assert.Equal(t, types.ItemStack{ID: g.itemKeys[0], Count: inventory.MaxStack}, stacks[0], "a new player gets the starter kit")
+	assert.NotContains(t, g.itemKeys, block.AirID)
+
+	g.RestorePlayer(types.PlayerState{Y: 10, Slot: 2, Inventory: []types.ItemStack{
+		{ID: block.StoneID, Count: 3}, {ID: "core:removed", Count: 1}, {ID: block.GlassID, Count: 1},
+	}})
+	state := g.PlayerState()
+	assert.Equal(t, float32(10), state.Y)
+	assert.Len(t, state.Inventory, inventory.Slots)
+	assert.Equal(t, types.ItemStack{}, state.Inventory[1], "unknown blocks are dropped")
+	assert.Equal(t, 2, state.Slot)
+	assert.Equal(t, block.GlassID, g.hotbar.Item())
+
+	// a stack held in the screen is saved
+	g.inventory.Click(0, false)
+	assert.Equal(t, types.ItemStack{ID: block.StoneID, Count: 3}, g.PlayerState().Inventory[0])
+	assert.Equal(t, types.ItemStack{ID: block.StoneID, Count: 3}, g.inventory.Held(), "still held after saving")
+}
+
+func TestPlayerStateMergesHeldStack(t *testing.T) {
+	g := newTestGame(t)
+
+	full := make([]types.ItemStack, inventory.Slots)
+	for i := range full {
+		full[i] = types.ItemStack{ID: block.DirtID, Count: inventory.MaxStack}
+	}
+	full[3] = types.ItemStack{ID: block.StoneID, Count: 10}
+	full[7] = types.ItemStack{ID: block.StoneID, Count: 20}
+	g.RestorePlayer(types.PlayerState{Inventory: full})
+
+	// the whole stack of slot 7 is held, its slot is empty
+	g.inventory.Click(7, false)
+	state := g.PlayerState()
+	assert.Equal(t, types.ItemStack{ID: block.StoneID, Count: 30}, state.Inventory[3], "merged into the partial stack")
+	assert.Equal(t, types.ItemStack{}, state.Inventory[7])
+}
+
+func TestPlaceAndBreakBlock(t *testing.T) {
+	g := newTestGame(t)
+	g.RestorePlayer(types.PlayerState{Inventory: []types.ItemStack{{ID: block.GrassBlockID, Count: 1}}})
+
+	id := Vec3{X: 2, Y: 100, Z: 2}
+	require.Len(t, g.World().Chunks([]Vec3{id.ChunkID()}), 1)
+	g.placeBlock(id)
+	assert.Equal(t, block.GrassBlockID, g.World().Block(id).ID)
+	assert.Equal(t, "", g.hotbar.Item(), "placing uses up the stack")
+
+	g.placeBlock(id.Up())
+	assert.False(t, g.World().HasBlock(id.Up()), "nothing is placed from an empty slot")
+
+	g.breakBlock(id)
+	assert.False(t, g.World().HasBlock(id))
+	assert.Equal(t, block.DirtID, g.hotbar.Item(), "grass drops dirt")
+}
@@ -2,12 +2,16 @@ package rpc
 
 import (
 	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chat"
 	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/rpc/wire"
 	"github.com/artheus/go-minecraft/core/game/store"
 	"github.com/artheus/go-minecraft/core/types"
 	. "github.com/artheus/go-minecraft/math/f32"
 
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"net"
 	"net/rpc"
@@ -38,6 +42,9 @@ func InitClient(ctx *ctx.Context) error {
 	Client = gocraft.NewClient()
 	Client.RegisterService("Block", &BlockService{ctx: ctx})
 	Client.RegisterService("Player", &PlayerService{ctx: ctx})
+	Client.RegisterService("Time", &TimeService{ctx: ctx})
+	Client.RegisterService("Chat", &ChatService{ctx: ctx})
+	Client.RegisterService("Command", &CommandService{ctx: ctx})
 	Client.Start(conn)
 	return nil
 }
@@ -59,9 +66,14 @@ func ClientFetchChunk(id Vec3, f func(bid Vec3, w *block.Block)) {
 	if err != nil {
 		log.Panic(err)
 	}
-	/*for _, b := range rep.Blocks {
-		f(Vec3{X: float32(b[0]), Y: float32(b[1]), Z: float32(b[2])}, b[3])
-	}*/
+	for _, b := range rep.Blocks {
+		w := block.Decode(b[3])
+		if w == nil {
+			log.Printf("unknown block %d in chunk %v", b[3], id)
+			continue
+		}
+		f(Vec3{X: float32(b[0]), Y: float32(b[1]), Z: float32(b[2])}, w)
+	}
 	if req.Version != rep.Version {
 		store.Storage.UpdateChunkVersion(id, rep.Version)
 	}
@@ -71,6 +83,41 @@ func ClientUpdateBlock(id Vec3, w *block.Block) {
 	if Client == nil {
 		return
 	}
+	version, err := updateBlock(id, w)
+	if err == rpc.ErrShutdown {
+		return
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	store.Storage.UpdateChunkVersion(id.ChunkID(), version)
+}
+
+// ClientUpdateBlocks sends many changed blocks to the server one after
+// the other, the version of each chunk is saved once they are sent
+func ClientUpdateBlocks(blocks map[Vec3]*block.Block) {
+	if Client == nil {
+		return
+	}
+	versions := make(map[Vec3]string)
+	for id, w := range blocks {
+		version, err := updateBlock(id, w)
+		if err == rpc.ErrShutdown {
+			return
+		}
+		if err != nil {
+			log.Panic(err)
+		}
+		versions[id.ChunkID()] = version
+	}
+	for cid, version := range versions {
+		store.Storage.UpdateChunkVersion(cid, version)
+	}
+}
+
+// updateBlock sends a changed block and returns the new version of its
+// chunk
+func updateBlock(id Vec3, w *block.Block) (string, error) {
 	cid := id.ChunkID()
 	req := &proto.UpdateBlockRequest{
 		Id: Client.ClientId,
@@ -79,17 +126,11 @@ func ClientUpdateBlock(id Vec3, w *block.Block) {
 		X:  int(id.X),
 		Y:  int(id.Y),
 		Z:  int(id.Z),
-		//W:  w,
+		W:  block.Encode(w),
 	}
 	rep := new(proto.UpdateBlockResponse)
 	err := Client.Call("Block.UpdateBlock", req, rep)
-	if err == rpc.ErrShutdown {
-		return
-	}
-	if err != nil {
-		log.Panic(err)
-	}
-	store.Storage.UpdateChunkVersion(cid, rep.Version)
+	return rep.Version, err
 }
 
 func ClientUpdatePlayerState(ctx *ctx.Context, state types.PlayerState) {
@@ -113,6 +154,122 @@ func ClientUpdatePlayerState(ctx *ctx.Context, state types.PlayerState) {
 	for id, player := range rep.Players {
 		ctx.Game().PlayerRenderer().UpdateOrAdd(id, player)
 	}
+	clientSetMotion(state)
+}
+
+var (
+	motion        wire.PlayerMotionRequest // last sent to the server
+	motionSkipped bool                     // the server doesn't serve it
+)
+
+// clientSetMotion tells the server when the player starts or stops
+// sneaking or sprinting, which pushes it to the other clients. A
+// server without motions, like gocraft-server, is logged once and the
+// other players see the player walk, other errors are sent again
+func clientSetMotion(state types.PlayerState) {
+	req := wire.PlayerMotionRequest{Id: Client.ClientId, Sneaking: state.Sneaking, Sprinting: state.Sprinting}
+	if motionSkipped || req == motion {
+		return
+	}
+	err := Client.Call("Player.SetMotion", &req, new(wire.PlayerMotionResponse))
+	if err == rpc.ErrShutdown {
+		return
+	}
+	if err != nil {
+		log.Printf("set player motion: %s", err)
+		_, motionSkipped = err.(rpc.ServerError)
+		return
+	}
+	motion = req
+}
+
+// ClientSetPlayerName tells the server the display name of this
+// player, which pushes it to the other clients, and fetches the names
+// of the players already there. Servers without names are logged and
+// their players keep showing their ids
+func ClientSetPlayerName(ctx *ctx.Context, name string) {
+	if Client == nil {
+		return
+	}
+	if name != "" {
+		err := Client.Call("Player.SetName", &wire.PlayerNameRequest{Id: Client.ClientId, Name: name}, new(wire.PlayerNameResponse))
+		if err != nil {
+			log.Printf("set player name: %s", err)
+			return
+		}
+	}
+	rep := new(wire.PlayerNamesResponse)
+	err := Client.Call("Player.GetNames", &wire.PlayerNamesRequest{}, rep)
+	if err != nil {
+		log.Printf("fetch player names: %s", err)
+		return
+	}
+	for id, name := range rep.Names {
+		if id != Client.ClientId {
+			ctx.Game().PlayerRenderer().SetName(id, name)
+		}
+	}
+}
+
+// ClientFetchTime asks the server for the world time, ok is false
+// when there is no server or it doesn't keep the time
+func ClientFetchTime() (ticks int64, ok bool) {
+	if Client == nil {
+		return 0, false
+	}
+	rep := new(wire.TimeResponse)
+	err := Client.Call("Time.GetTime", &wire.TimeRequest{}, rep)
+	if err != nil {
+		log.Printf("fetch time: %s", err)
+		return 0, false
+	}
+	return rep.Time, true
+}
+
+// ClientSetTime sends a changed world time to the server,
+// which pushes it to the other clients
+func ClientSetTime(ticks int64) {
+	if Client == nil {
+		return
+	}
+	rep := new(wire.TimeResponse)
+	err := Client.Call("Time.SetTime", &wire.TimeRequest{Time: ticks}, rep)
+	if err == rpc.ErrShutdown {
+		return
+	}
+	if err != nil {
+		log.Printf("set time: %s", err)
+	}
+}
+
+// ClientSendChat sends a chat message of player name to the server,
+// which pushes it to the other clients. It returns why the message
+// wasn't sent, nil when playing alone
+func ClientSendChat(name, text string) error {
+	if Client == nil {
+		return nil
+	}
+	err := Client.Call("Chat.Send", &wire.ChatRequest{Id: Client.ClientId, Name: name, Text: text}, new(wire.ChatResponse))
+	if err == rpc.ErrShutdown {
+		return nil
+	}
+	if err != nil {
+		log.Printf("send chat: %s", err)
+	}
+	return err
+}
+
+// ClientRunCommand sends a command needing an operator to the server,
+// which runs it back on this game when the player is one
+func ClientRunCommand(name, line string) (string, error) {
+	rep := new(wire.CommandResponse)
+	if err := Client.Call("Command.Run", &wire.CommandRequest{Name: name, Line: line}, rep); err != nil {
+		return "", err
+	}
+	if rep.Error != "" {
+		return "", errors.New(rep.Error)
+	}
+	return rep.Message, nil
 }
 
 type BlockService struct {
@@ -121,10 +278,17 @@ type BlockService struct {
 
 func (s *BlockService) UpdateBlock(req *proto.UpdateBlockRequest, rep *proto.UpdateBlockResponse) error {
 	log.Printf("rpc::UpdateBlock:%v", *req)
-	bid := Vec3{float32(req.X), float32(req.Y), float32(req.Z)}
-	//s.ctx.Game().World().UpdateBlock(bid, req.W)
-	s.ctx.Game().ChunkRenderer().DirtyChunk(bid.ChunkID())
-	return nil
+	bid := Vec3{X: float32(req.X), Y: float32(req.Y), Z: float32(req.Z)}
+	w := block.Decode(req.W)
+	if w == nil {
+		log.Printf("unknown block %d at %v", req.W, bid)
+		return nil
+	}
+	// the change waits for the tick, like the player's own changes
+	game := s.ctx.Game()
+	return game.RunOnTick(func() {
+		game.World().UpdateBlock(bid, w)
+	})
 }
 
 type PlayerService struct {
@@ -135,3 +299,64 @@ func (s *PlayerService) RemovePlayer(req *proto.RemovePlayerRequest, rep *proto.
 	s.ctx.Game().PlayerRenderer().Remove(req.Id)
 	return nil
 }
+
+func (s *PlayerService) SetName(req *wire.PlayerNameRequest, rep *wire.PlayerNameResponse) error {
+	s.ctx.Game().PlayerRenderer().SetName(req.Id, req.Name)
+	return nil
+}
+
+func (s *PlayerService) SetMotion(req *wire.PlayerMotionRequest, rep *wire.PlayerMotionResponse) error {
+	s.ctx.Game().PlayerRenderer().SetMotion(req.Id, req.Sneaking, req.Sprinting)
+	return nil
+}
+
+type TimeService struct {
+	ctx *ctx.Context
+}
+
+func (s *TimeService) SetTime(req *wire.TimeRequest, rep *wire.TimeResponse) error {
+	s.ctx.Game().Clock().Set(req.Time)
+	rep.Time = req.Time
+	return nil
+}
+
+type ChatService struct {
+	ctx *ctx.Context
+}
+
+// Message adds a message of another player to the chat, players
+// without a name are shown by their id, like on their name tags
+func (s *ChatService) Message(req *wire.ChatRequest, rep *wire.ChatResponse) error {
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("Player %d", req.Id)
+	}
+	s.ctx.Game().Chat().Add(chat.Format(name, req.Text))
+	return nil
+}
+
+type CommandService struct {
+	ctx *ctx.Context
+}
+
+// Run runs a command with the permission of an operator. Only the
+// server calls it, for the lines of players it checked are operators
+func (s *CommandService) Run(req *wire.CommandRequest, rep *wire.CommandResponse) error {
+	game := s.ctx.Game()
+	name := req.Name
+	if name == "" {
+		name = "server"
+	}
+	src := types.CommandSource{
+		Name:       name,
+		Permission: types.PermissionOperator,
+		Pos:        game.Camera().Pos(),
+	}
+	msg, err := game.RunCommand(src, req.Line)
+	if err != nil {
+		rep.Error = err.Error()
+		return nil
+	}
+	rep.Message = msg
+	return nil
+}
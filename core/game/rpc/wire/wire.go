@@ -0,0 +1,64 @@
+// Package wire has the requests and responses of the rpc calls this
+// game adds to the gocraft-server protocol, they are served by the
+// server in ./server
+package wire
+
+// TimeRequest and TimeResponse carry the world time in ticks
+type TimeRequest struct {
+	Time int64
+}
+
+type TimeResponse struct {
+	Time int64
+}
+
+// PlayerNameRequest carries the display name of a player
+type PlayerNameRequest struct {
+	Id   int32
+	Name string
+}
+
+type PlayerNameResponse struct {
+}
+
+type PlayerNamesRequest struct {
+}
+
+// PlayerNamesResponse has the display names of the connected players
+type PlayerNamesResponse struct {
+	Names map[int32]string
+}
+
+// PlayerMotionRequest carries if a player sneaks or sprints
+type PlayerMotionRequest struct {
+	Id        int32
+	Sneaking  bool
+	Sprinting bool
+}
+
+type PlayerMotionResponse struct {
+}
+
+// ChatRequest carries a chat message and the display name of the
+// player who sent it
+type ChatRequest struct {
+	Id   int32
+	Name string
+	Text string
+}
+
+type ChatResponse struct {
+}
+
+// CommandRequest carries a slash command and the display name of the
+// player who sent it
+type CommandRequest struct {
+	Name string
+	Line string
+}
+
+// CommandResponse has the message of the command, or why it failed
+type CommandResponse struct {
+	Message string
+	Error   string
+}
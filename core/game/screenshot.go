@@ -0,0 +1,75 @@
+package game
+
+import (
+	"flag"
+	"image"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/faiface/mainthread"
+	"github.com/pkg/errors"
+)
+
+var (
+	ScreenshotPath   = flag.String("screenshot", "", "render a single frame to this png file and exit")
+	ScreenshotCamera = flag.String("camera", "", "camera position x,y,z[,yaw,pitch] of -screenshot")
+	ScreenshotDir    = flag.String("screenshots", "screenshots", "directory of the screenshots taken with F2")
+)
+
+// ParseCamera parses a camera position given as x,y,z[,yaw,pitch]
+func ParseCamera(s string) (types.PlayerState, error) {
+	var state types.PlayerState
+	fields := strings.Split(s, ",")
+	if len(fields) != 3 && len(fields) != 5 {
+		return state, errors.Errorf("camera %q is not x,y,z[,yaw,pitch]", s)
+	}
+	values := make([]float32, 5)
+	values[3] = -90 // looking north, like a new camera
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 32)
+		if err != nil {
+			return state, errors.Wrapf(err, "camera %q", s)
+		}
+		values[i] = float32(v)
+	}
+	return types.PlayerState{
+		X: values[0], Y: values[1], Z: values[2],
+		Rx: values[3], Ry: values[4],
+	}, nil
+}
+
+// screenshotName returns the file a screenshot taken at t is saved to
+func screenshotName(t time.Time) string {
+	return filepath.Join(*ScreenshotDir, t.Format("2006-01-02_15.04.05.000")+".png")
+}
+
+// saveScreenshot writes the frame in the background, encoding
+// a large png takes longer than a frame
+func saveScreenshot(img image.Image) {
+	fname := screenshotName(time.Now())
+	go func() {
+		if err := texture.SavePNG(fname, img); err != nil {
+			log.Printf("save screenshot: %s", err)
+			return
+		}
+		log.Printf("saved screenshot %s", fname)
+	}()
+}
+
+// Screenshot renders a single frame, once all chunks around the camera
+// are meshed, and saves it to fname
+func (g *Application) Screenshot(fname string) error {
+	g.chunkRenderer.LoadChunks()
+
+	var img *image.NRGBA
+	mainthread.Call(func() {
+		g.renderFrame()
+		img = g.fbo.Image()
+	})
+	return texture.SavePNG(fname, img)
+}
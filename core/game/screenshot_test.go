@@ -0,0 +1,23 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCamera(t *testing.T) {
+	state, err := ParseCamera("1.5, 30,-2")
+	assert.NoError(t, err)
+	assert.Equal(t, types.PlayerState{X: 1.5, Y: 30, Z: -2, Rx: -90}, state)
+
+	state, err = ParseCamera("0,20,0,45,-30")
+	assert.NoError(t, err)
+	assert.Equal(t, types.PlayerState{Y: 20, Rx: 45, Ry: -30}, state)
+
+	_, err = ParseCamera("0,20")
+	assert.Error(t, err)
+	_, err = ParseCamera("0,up,0")
+	assert.Error(t, err)
+}
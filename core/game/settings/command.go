@@ -0,0 +1,78 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/artheus/go-minecraft/core/command"
+	"github.com/artheus/go-minecraft/core/types"
+)
+
+// names are the settings in /settings, in the order they are listed
+var names = []string{"fov", "distance", "sensitivity", "fog", "clouds", "coverage", "windspeed"}
+
+// fields are the settings by their name in /settings
+var fields = map[string]struct {
+	get func(s *Settings) float32
+	set func(s *Settings, v float32)
+}{
+	"fov": {
+		func(s *Settings) float32 { return s.FOV },
+		func(s *Settings, v float32) { s.FOV = v },
+	},
+	"distance": {
+		func(s *Settings) float32 { return float32(s.RenderRadius) },
+		func(s *Settings, v float32) { s.RenderRadius = int(v) },
+	},
+	"sensitivity": {
+		func(s *Settings) float32 { return s.Sensitivity },
+		func(s *Settings, v float32) { s.Sensitivity = v },
+	},
+	"fog": {
+		func(s *Settings) float32 { return s.FogDistance },
+		func(s *Settings, v float32) { s.FogDistance = v },
+	},
+	"clouds": {
+		func(s *Settings) float32 { return s.CloudHeight },
+		func(s *Settings, v float32) { s.CloudHeight = v },
+	},
+	"coverage": {
+		func(s *Settings) float32 { return s.CloudCoverage },
+		func(s *Settings, v float32) { s.CloudCoverage = v },
+	},
+	"windspeed": {
+		func(s *Settings) float32 { return s.CloudSpeed },
+		func(s *Settings, v float32) { s.CloudSpeed = v },
+	},
+}
+
+// Command returns the /settings command, changed is called after a
+// value is given. The settings are the player's own, anyone can change
+// them
+func Command(changed func()) *command.Command {
+	return &command.Command{
+		Name: "settings",
+		Help: "shows or changes the settings",
+		Args: []command.Arg{
+			{Name: "setting", Type: command.Choice(names...), Optional: true},
+			{Name: "value", Type: command.Float(), Optional: true},
+		},
+		Run: func(_ types.CommandSource, args command.Args) (string, error) {
+			s := Current()
+			if !args.Has("setting") {
+				return fmt.Sprintf("fov %g, distance %d, sensitivity %g, fog %g, clouds %g, coverage %g, windspeed %g",
+					s.FOV, s.RenderRadius, s.Sensitivity, s.FogDistance,
+					s.CloudHeight, s.CloudCoverage, s.CloudSpeed), nil
+			}
+			name := args.String("setting")
+			field := fields[name]
+			if args.Has("value") {
+				field.set(&s, args.Float("value"))
+				s = Set(s)
+				if changed != nil {
+					changed()
+				}
+			}
+			return fmt.Sprintf("%s is %g", name, field.get(&s)), nil
+		},
+	}
+}
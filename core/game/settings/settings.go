@@ -0,0 +1,187 @@
+// Package settings holds the player's settings, they can change while
+// playing and are saved to a file per user
+package settings
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	Path         = flag.String("settings", "", "settings file, gocraft/settings.json in the user config directory if empty")
+	RenderRadius = flag.Int("r", 0, "render radius in chunks, the saved setting if 0")
+)
+
+// Settings are the values the player can change
+type Settings struct {
+	// FOV is the vertical field of view in degrees
+	FOV float32 `json:"fov"`
+	// RenderRadius is how many chunks around the camera are drawn
+	RenderRadius int `json:"render_radius"`
+	// Sensitivity is the degrees the camera turns per pixel of mouse movement
+	Sensitivity float32 `json:"sensitivity"`
+	// FogDistance is the distance in chunks where the fog is thickest,
+	// 0 follows the render radius
+	FogDistance float32 `json:"fog_distance"`
+	// CloudHeight is the height of the bottom of the cloud layer
+	CloudHeight float32 `json:"cloud_height"`
+	// CloudCoverage is the part of the sky covered by clouds, 0-1
+	CloudCoverage float32 `json:"cloud_coverage"`
+	// CloudSpeed is how many blocks per second the clouds move
+	CloudSpeed float32 `json:"cloud_speed"`
+}
+
+// Limits of the settings, values outside are clamped
+const (
+	MinFOV          = 30
+	MaxFOV          = 110
+	MinRenderRadius = 2
+	MaxRenderRadius = 32
+	MinSensitivity  = 0.01
+	MaxSensitivity  = 1
+	MinCloudHeight  = 32
+	MaxCloudHeight  = 256
+	MaxCloudSpeed   = 20
+)
+
+func Default() Settings {
+	return Settings{
+		FOV:          45,
+		RenderRadius: 16,
+		Sensitivity:  0.14,
+
+		CloudHeight:   96,
+		CloudCoverage: 0.4,
+		CloudSpeed:    1,
+	}
+}
+
+// Fog returns the distance in chunks where the fog is thickest
+func (s Settings) Fog() float32 {
+	if s.FogDistance <= 0 || s.FogDistance > float32(s.RenderRadius) {
+		return float32(s.RenderRadius)
+	}
+	return s.FogDistance
+}
+
+// clamp moves all values within their limits
+func (s Settings) clamp() Settings {
+	s.FOV = clampf(s.FOV, MinFOV, MaxFOV)
+	if s.RenderRadius < MinRenderRadius {
+		s.RenderRadius = MinRenderRadius
+	}
+	if s.RenderRadius > MaxRenderRadius {
+		s.RenderRadius = MaxRenderRadius
+	}
+	s.Sensitivity = clampf(s.Sensitivity, MinSensitivity, MaxSensitivity)
+	s.FogDistance = clampf(s.FogDistance, 0, MaxRenderRadius)
+	s.CloudHeight = clampf(s.CloudHeight, MinCloudHeight, MaxCloudHeight)
+	s.CloudCoverage = clampf(s.CloudCoverage, 0, 1)
+	s.CloudSpeed = clampf(s.CloudSpeed, 0, MaxCloudSpeed)
+	return s
+}
+
+func clampf(v, min, max float32) float32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+var (
+	mx      sync.RWMutex
+	current = Default()
+)
+
+// Current returns the settings in use
+func Current() Settings {
+	mx.RLock()
+	defer mx.RUnlock()
+	return current
+}
+
+// Set changes the settings in use, clamped to their limits, and
+// returns them
+func Set(s Settings) Settings {
+	s = s.clamp()
+	mx.Lock()
+	current = s
+	mx.Unlock()
+	return s
+}
+
+// FilePath returns the file the settings are saved to
+func FilePath() (string, error) {
+	if *Path != "" {
+		return *Path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gocraft", "settings.json"), nil
+}
+
+// Load reads the settings in fname, a missing file or setting gives
+// the default
+func Load(fname string) (Settings, error) {
+	s := Default()
+	b, err := os.ReadFile(fname)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err = json.Unmarshal(b, &s); err != nil {
+		return Default(), errors.Wrapf(err, "settings %s", fname)
+	}
+	return s.clamp(), nil
+}
+
+// Save writes s to fname, creating its directory
+func Save(fname string, s Settings) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(fname, append(b, '\n'), 0644)
+}
+
+// Init loads the settings file of the user and makes it current,
+// -r takes precedence over the saved render radius
+func Init() error {
+	fname, err := FilePath()
+	if err != nil {
+		return err
+	}
+	s, err := Load(fname)
+	if err != nil {
+		return err
+	}
+	if *RenderRadius > 0 {
+		s.RenderRadius = *RenderRadius
+	}
+	Set(s)
+	return nil
+}
+
+// SaveCurrent writes the settings in use to the settings file of the user
+func SaveCurrent() error {
+	fname, err := FilePath()
+	if err != nil {
+		return err
+	}
+	return Save(fname, Current())
+}
@@ -0,0 +1,107 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/command"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMissingFileGivesDefaults(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "settings.json"))
+	require.NoError(t, err)
+	assert.Equal(t, Default(), s)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "gocraft", "settings.json")
+	s := Settings{FOV: 70, RenderRadius: 8, Sensitivity: 0.3, FogDistance: 5,
+		CloudHeight: 120, CloudCoverage: 0.7, CloudSpeed: 3}
+	require.NoError(t, Save(fname, s))
+
+	loaded, err := Load(fname)
+	require.NoError(t, err)
+	assert.Equal(t, s, loaded)
+}
+
+func TestLoadKeepsDefaultsOfMissingSettings(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(fname, []byte(`{"fov": 500}`), 0644))
+
+	s, err := Load(fname)
+	require.NoError(t, err)
+	assert.Equal(t, float32(MaxFOV), s.FOV)
+	assert.Equal(t, Default().RenderRadius, s.RenderRadius)
+	assert.Equal(t, Default().CloudHeight, s.CloudHeight)
+
+	require.NoError(t, os.WriteFile(fname, []byte(`{"fov":`), 0644))
+	_, err = Load(fname)
+	assert.Error(t, err)
+}
+
+func TestFogFollowsRenderRadius(t *testing.T) {
+	s := Settings{RenderRadius: 10}
+	assert.Equal(t, float32(10), s.Fog())
+	s.FogDistance = 4
+	assert.Equal(t, float32(4), s.Fog())
+	s.FogDistance = 12
+	assert.Equal(t, float32(10), s.Fog())
+}
+
+func TestInitPrefersRenderRadiusFlag(t *testing.T) {
+	defer Set(Current())
+	path, radius := *Path, *RenderRadius
+	defer func() { *Path, *RenderRadius = path, radius }()
+
+	*Path = filepath.Join(t.TempDir(), "settings.json")
+	s := Default()
+	s.RenderRadius = 8
+	require.NoError(t, Save(*Path, s))
+	*RenderRadius = 4
+	require.NoError(t, Init())
+	assert.Equal(t, 4, Current().RenderRadius)
+}
+
+func TestCommand(t *testing.T) {
+	defer Set(Current())
+	Set(Default())
+	changes := 0
+	r := command.NewRegistry()
+	r.Register(Command(func() { changes++ }))
+	src := types.CommandSource{}
+
+	msg, err := r.Run(src, "/settings fov")
+	require.NoError(t, err)
+	assert.Equal(t, 0, changes)
+	assert.Equal(t, "fov is 45", msg)
+
+	msg, err = r.Run(src, "/settings fov 90")
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes)
+	assert.Equal(t, "fov is 90", msg)
+	assert.Equal(t, float32(90), Current().FOV)
+
+	// values are clamped to their limits
+	msg, err = r.Run(src, "/settings distance 100")
+	require.NoError(t, err)
+	assert.Equal(t, "distance is 32", msg)
+	assert.Equal(t, MaxRenderRadius, Current().RenderRadius)
+
+	msg, err = r.Run(src, "/settings")
+	require.NoError(t, err)
+	assert.Equal(t, "fov 90, distance 32, sensitivity 0.14, fog 0, clouds 96, coverage 0.4, windspeed 1", msg)
+
+	msg, err = r.Run(src, "/settings coverage 2")
+	require.NoError(t, err)
+	assert.Equal(t, "coverage is 1", msg)
+
+	_, err = r.Run(src, "/settings brightness 1")
+	assert.Error(t, err)
+	_, err = r.Run(src, "/settings fog thick")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"/settings clouds", "/settings coverage"}, r.Complete(src, "/settings c"))
+}
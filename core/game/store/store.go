@@ -7,6 +7,7 @@ import (
 
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"flag"
 	"log"
@@ -22,6 +23,9 @@ var (
 	blockBucket  = []byte("block")
 	chunkBucket  = []byte("chunk")
 	cameraBucket = []byte("camera")
+	worldBucket  = []byte("world")
+
+	timeKey = []byte("time")
 
 	Storage *Store
 )
@@ -61,6 +65,10 @@ func NewStore(p string) (*Store, error) {
 			return err
 		}
 		_, err = tx.CreateBucketIfNotExists(cameraBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(worldBucket)
 		return err
 	})
 	if err != nil {
@@ -74,7 +82,7 @@ func NewStore(p string) (*Store, error) {
 
 func (s *Store) UpdateBlock(id Vec3, w *block.Block) error {
 	return s.db.Update(func(tx *bolt.Tx) error {
-		log.Printf("put %v -> %d", id, w)
+		log.Printf("put %v -> %s", id, w.ID)
 		bkt := tx.Bucket(blockBucket)
 		cid := id.ChunkID()
 		key := encodeBlockDbKey(cid, id)
@@ -83,13 +91,37 @@ func (s *Store) UpdateBlock(id Vec3, w *block.Block) error {
 	})
 }
 
+// UpdateBlocks saves many blocks in one write, unlike UpdateBlock
+// they aren't logged
+func (s *Store) UpdateBlocks(blocks map[Vec3]*block.Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(blockBucket)
+		for id, w := range blocks {
+			if err := bkt.Put(encodeBlockDbKey(id.ChunkID(), id), encodeBlockDbValue(w)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// legacyPlayerState is how the player state was saved before it was json
+type legacyPlayerState struct {
+	X, Y, Z float32
+	Rx, Ry  float32
+}
+
 func (s *Store) UpdatePlayerState(state types.PlayerState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
 	return s.db.Update(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(cameraBucket)
-		buf := new(bytes.Buffer)
-		binary.Write(buf, binary.LittleEndian, &state)
-		bkt.Put(cameraBucket, buf.Bytes())
-		return nil
+		return bkt.Put(cameraBucket, value)
 	})
 }
 
@@ -102,17 +134,45 @@ func (s *Store) GetPlayerState() types.PlayerState {
 		if value == nil {
 			return nil
 		}
-		buf := bytes.NewBuffer(value)
-		binary.Read(buf, binary.LittleEndian, &state)
+		if json.Unmarshal(value, &state) == nil {
+			return nil
+		}
+		var legacy legacyPlayerState
+		if binary.Read(bytes.NewBuffer(value), binary.LittleEndian, &legacy) == nil {
+			state = types.PlayerState{X: legacy.X, Y: legacy.Y, Z: legacy.Z, Rx: legacy.Rx, Ry: legacy.Ry}
+		}
 		return nil
 	})
 	return state
 }
 
+func (s *Store) UpdateTime(ticks int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(worldBucket)
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, ticks)
+		return bkt.Put(timeKey, buf.Bytes())
+	})
+}
+
+// GetTime returns the saved world time, ok is false for a new world
+func (s *Store) GetTime() (ticks int64, ok bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(worldBucket)
+		value := bkt.Get(timeKey)
+		if value == nil {
+			return nil
+		}
+		ok = binary.Read(bytes.NewBuffer(value), binary.LittleEndian, &ticks) == nil
+		return nil
+	})
+	return ticks, ok
+}
+
 func (s *Store) RangeBlocks(id Vec3, f func(bid Vec3, w *block.Block)) error {
 	return s.db.View(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(blockBucket)
-		startkey := encodeBlockDbKey(id, Vec3{0, 0, 0})
+		startkey := encodeBlockDbKey(id, Vec3{})
 		iter := bkt.Cursor()
 		for k, v := iter.Seek(startkey); k != nil; k, v = iter.Next() {
 			cid, bid := decodeBlockDbKey(k)
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	s, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestPlayerStateDefaultsToAboveTheGround(t *testing.T) {
+	assert.Equal(t, types.PlayerState{Y: 16}, newTestStore(t).GetPlayerState())
+}
+
+func TestUpdatePlayerState(t *testing.T) {
+	s := newTestStore(t)
+	state := types.PlayerState{X: 1, Y: 2, Z: 3, Rx: -90, Ry: 10, Name: "Steve",
+		Inventory: []types.ItemStack{{ID: "core:dirt", Count: 3}, {}, {ID: "core:stone", Count: 64}}, Slot: 2}
+	require.NoError(t, s.UpdatePlayerState(state))
+	assert.Equal(t, state, s.GetPlayerState())
+
+	moving := state
+	moving.Sneaking, moving.Sprinting = true, true
+	require.NoError(t, s.UpdatePlayerState(moving))
+	assert.Equal(t, state, s.GetPlayerState(), "sneaking and sprinting aren't saved")
+}
+
+func TestGetLegacyPlayerState(t *testing.T) {
+	s := newTestStore(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, &legacyPlayerState{X: 1, Y: 2, Z: 3, Rx: 4, Ry: 5}))
+	require.NoError(t, s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cameraBucket).Put(cameraBucket, buf.Bytes())
+	}))
+	assert.Equal(t, types.PlayerState{X: 1, Y: 2, Z: 3, Rx: 4, Ry: 5}, s.GetPlayerState())
+}
+
+func TestUpdateBlocks(t *testing.T) {
+	block.InitRegister()
+	s := newTestStore(t)
+	water, stone := block.GetBlock(block.WaterID), block.GetBlock(block.StoneID)
+	require.NoError(t, s.UpdateBlocks(map[Vec3]*block.Block{
+		{X: 1, Y: 2, Z: 3}:   water,
+		{X: 2, Y: 2, Z: 3}:   stone,
+		{X: 40, Y: 2, Z: -3}: water,
+	}))
+	require.NoError(t, s.UpdateBlocks(nil))
+
+	got := map[Vec3]string{}
+	require.NoError(t, s.RangeBlocks(Vec3{}, func(id Vec3, w *block.Block) {
+		got[id] = w.ID
+	}))
+	assert.Equal(t, map[Vec3]string{{X: 1, Y: 2, Z: 3}: block.WaterID, {X: 2, Y: 2, Z: 3}: block.StoneID}, got)
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/artheus/go-minecraft/core/command"
+	"github.com/artheus/go-minecraft/core/game/clock"
+	"github.com/artheus/go-minecraft/core/types"
+)
+
+// timeOfDay is a named time of day or a number of ticks after sunrise
+var timeOfDay = command.Word(func(s string) (interface{}, error) {
+	return clock.ParseTime(s)
+}, "sunrise", "day", "noon", "sunset", "night", "midnight")
+
+// timeCommand returns the /time command on c, changed is called after set
+// and add changed the clock. Anyone can query the time
+func timeCommand(c types.IClock, changed func()) *command.Command {
+	done := func() (string, error) {
+		if changed != nil {
+			changed()
+		}
+		return fmt.Sprintf("set the time to %d", c.TimeOfDay()), nil
+	}
+	return &command.Command{
+		Name: "time",
+		Help: "shows or changes the time of day",
+		Sub: []*command.Command{{
+			Name:       "set",
+			Permission: types.PermissionOperator,
+			Args:       []command.Arg{{Name: "time", Type: timeOfDay}},
+			Run: func(_ types.CommandSource, args command.Args) (string, error) {
+				// keep the day count, only move the time of day
+				c.Set(c.Time() - c.TimeOfDay() + args["time"].(int64))
+				return done()
+			},
+		}, {
+			Name:       "add",
+			Permission: types.PermissionOperator,
+			Args:       []command.Arg{{Name: "ticks", Type: command.Int(math.MinInt32, math.MaxInt32)}},
+			Run: func(_ types.CommandSource, args command.Args) (string, error) {
+				c.Add(int64(args.Int("ticks")))
+				return done()
+			},
+		}, {
+			Name: "query",
+			Run: func(types.CommandSource, command.Args) (string, error) {
+				return fmt.Sprintf("time is %d (day %d)", c.TimeOfDay(), c.Time()/clock.DayLength), nil
+			},
+		}},
+	}
+}
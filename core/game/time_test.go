@@ -0,0 +1,41 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/command"
+	"github.com/artheus/go-minecraft/core/game/clock"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeCommand(t *testing.T) {
+	c := clock.NewClock(2*clock.DayLength + clock.Noon)
+	changes := 0
+	r := command.NewRegistry()
+	r.Register(timeCommand(c, func() { changes++ }))
+	op := types.CommandSource{Permission: types.PermissionOperator}
+
+	_, err := r.Run(op, "/time set night")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, changes)
+	assert.Equal(t, int64(2*clock.DayLength+clock.Night), c.Time())
+
+	_, err = r.Run(op, "/time add 100")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changes)
+	assert.Equal(t, int64(clock.Night+100), c.TimeOfDay())
+
+	msg, err := r.Run(types.CommandSource{}, "/time query")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changes)
+	assert.Contains(t, msg, "13100")
+
+	_, err = r.Run(op, "/time set")
+	assert.Error(t, err)
+	_, err = r.Run(op, "/time add soon")
+	assert.Error(t, err)
+	_, err = r.Run(types.CommandSource{}, "/time set noon")
+	assert.Error(t, err, "only operators change the time")
+	assert.Equal(t, []string{"/time set night"}, r.Complete(op, "/time set ni"))
+}
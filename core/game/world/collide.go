@@ -0,0 +1,220 @@
+package world
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// touch is how close boxes count as touching, so rounding errors don't
+// let a box slip into another it rests against
+const touch = 1e-4
+
+// boxSource is what boxes collide with
+type boxSource interface {
+	// boxes returns the collision cuboids of the block at id, relative
+	// to its center
+	boxes(id Vec3) []block.Cuboid
+}
+
+// blockRange returns the ids of the blocks box reaches into
+func blockRange(box block.Cuboid) (min, max Vec3) {
+	// blocks are centered on whole coordinates
+	min = Vec3{X: Round(box.Min.X()), Y: Round(box.Min.Y()), Z: Round(box.Min.Z())}
+	max = Vec3{X: Round(box.Max.X()), Y: Round(box.Max.Y()), Z: Round(box.Max.Z())}
+	return min, max
+}
+
+// obstacles returns the collision cuboids of the blocks within box, in
+// world coordinates
+func obstacles(src boxSource, box block.Cuboid) []block.Cuboid {
+	var cuboids []block.Cuboid
+	min, max := blockRange(box)
+	for x := min.X; x <= max.X; x++ {
+		for y := min.Y; y <= max.Y; y++ {
+			for z := min.Z; z <= max.Z; z++ {
+				for _, c := range src.boxes(Vec3{X: x, Y: y, Z: z}) {
+					cuboids = append(cuboids, c.Offset(mgl32.Vec3{x, y, z}))
+				}
+			}
+		}
+	}
+	return cuboids
+}
+
+// clip returns how far along axis box moves towards d before it runs
+// into one of the cuboids, those box is already in don't stop it
+func clip(cuboids []block.Cuboid, box block.Cuboid, axis int, d float32) float32 {
+	u, v := (axis+1)%3, (axis+2)%3
+	for _, c := range cuboids {
+		if box.Max[u] <= c.Min[u]+touch || box.Min[u] >= c.Max[u]-touch ||
+			box.Max[v] <= c.Min[v]+touch || box.Min[v] >= c.Max[v]-touch {
+			continue
+		}
+		switch {
+		case d > 0 && box.Max[axis] <= c.Min[axis]+touch:
+			d = Min(d, c.Min[axis]-box.Max[axis])
+		case d < 0 && box.Min[axis] >= c.Max[axis]-touch:
+			d = Max(d, c.Max[axis]-box.Min[axis])
+		}
+	}
+	return d
+}
+
+// sweep moves box by d one axis at a time, up or down first, stopping
+// against the collision cuboids of the blocks. It returns how far box
+// moved and what stopped it
+func sweep(src boxSource, box block.Cuboid, d mgl32.Vec3) (mgl32.Vec3, types.Collision) {
+	reach := block.Cuboid{Min: box.Min, Max: box.Max}
+	for i := 0; i < 3; i++ {
+		if d[i] < 0 {
+			reach.Min[i] += d[i]
+		} else {
+			reach.Max[i] += d[i]
+		}
+	}
+	cuboids := obstacles(src, reach)
+
+	var (
+		moved mgl32.Vec3
+		hit   types.Collision
+	)
+	for _, axis := range []int{1, 0, 2} {
+		if d[axis] == 0 {
+			continue
+		}
+		m := clip(cuboids, box, axis, d[axis])
+		if m != d[axis] {
+			switch {
+			case axis != 1:
+				hit.Wall = true
+			case d[axis] < 0:
+				hit.Ground = true
+			default:
+				hit.Ceiling = true
+			}
+		}
+		moved[axis] = m
+		var step mgl32.Vec3
+		step[axis] = m
+		box = box.Offset(step)
+	}
+	return moved, hit
+}
+
+// collide moves box by d like sweep, a box walking on the ground into
+// something up to step high climbs onto it
+func collide(src boxSource, box block.Cuboid, d mgl32.Vec3, step float32) (mgl32.Vec3, types.Collision) {
+	moved, hit := sweep(src, box, d)
+	if step <= 0 || !hit.Ground || !hit.Wall {
+		return moved, hit
+	}
+
+	// up, across and back down onto what was walked into
+	up, _ := sweep(src, box, mgl32.Vec3{0, step, 0})
+	across, acrossHit := sweep(src, box.Offset(up), mgl32.Vec3{d.X(), 0, d.Z()})
+	down, downHit := sweep(src, box.Offset(up.Add(across)), mgl32.Vec3{0, d.Y() - up.Y(), 0})
+	walked := mgl32.Vec2{moved.X(), moved.Z()}
+	if !downHit.Ground || across.Len() <= walked.Len() {
+		return moved, hit
+	}
+	return up.Add(across).Add(down), types.Collision{Ground: true, Wall: acrossHit.Wall}
+}
+
+// ledgeStep is how much a move off a ledge is cut short at a time
+const ledgeStep = 0.05
+
+// supported tells if there is a collision cuboid under box, less than
+// drop below it
+func supported(src boxSource, box block.Cuboid, drop float32) bool {
+	below := block.Cuboid{
+		Min: box.Min.Sub(mgl32.Vec3{0, drop, 0}),
+		Max: mgl32.Vec3{box.Max.X(), box.Min.Y(), box.Max.Z()},
+	}
+	for _, c := range obstacles(src, below) {
+		if c.Overlaps(below) {
+			return true
+		}
+	}
+	return false
+}
+
+// shorten returns v ledgeStep closer to 0
+func shorten(v float32) float32 {
+	switch {
+	case Abs(v) <= ledgeStep:
+		return 0
+	case v > 0:
+		return v - ledgeStep
+	}
+	return v + ledgeStep
+}
+
+// ledge returns the move d of box cut short, one axis and then both,
+// until box would still be supported after it
+func ledge(src boxSource, box block.Cuboid, d mgl32.Vec3, drop float32) mgl32.Vec3 {
+	fits := func(x, z float32) bool {
+		return supported(src, box.Offset(mgl32.Vec3{x, 0, z}), drop)
+	}
+	x, z := d.X(), d.Z()
+	for x != 0 && !fits(x, 0) {
+		x = shorten(x)
+	}
+	for z != 0 && !fits(0, z) {
+		z = shorten(z)
+	}
+	for x != 0 && z != 0 && !fits(x, z) {
+		x, z = shorten(x), shorten(z)
+	}
+	return mgl32.Vec3{x, d.Y(), z}
+}
+
+// maxUnstick is how many times a stuck box is lifted at most
+const maxUnstick = 256
+
+// unstick returns how far box is lifted out of the cuboids of the
+// blocks it is stuck in, like a player a block was placed on
+func unstick(src boxSource, box block.Cuboid) float32 {
+	lift := float32(0)
+	for i := 0; i < maxUnstick; i++ {
+		// boxes resting against others aren't stuck in them
+		inner := block.Cuboid{
+			Min: box.Min.Add(mgl32.Vec3{touch, touch, touch}),
+			Max: box.Max.Sub(mgl32.Vec3{touch, touch, touch}),
+		}
+		top, stuck := float32(0), false
+		for _, c := range obstacles(src, box) {
+			if c.Overlaps(inner) && (!stuck || c.Max.Y() > top) {
+				top, stuck = c.Max.Y(), true
+			}
+		}
+		if !stuck {
+			break
+		}
+		lift += top - box.Min.Y()
+		box = box.Offset(mgl32.Vec3{0, top - box.Min.Y(), 0})
+	}
+	return lift
+}
+
+// boxes returns the collision cuboids of the block at id
+func (w *World) boxes(id Vec3) []block.Cuboid {
+	return w.Block(id).Collision()
+}
+
+// Collide moves box, in world coordinates, by d through the blocks.
+// It returns how far box moved and what stopped it, a box on the
+// ground steps onto blocks up to step high and a box stuck in blocks
+// is lifted out of them first
+func (w *World) Collide(box block.Cuboid, d mgl32.Vec3, step float32) (mgl32.Vec3, types.Collision) {
+	lift := mgl32.Vec3{0, unstick(w, box), 0}
+	moved, hit := collide(w, box.Offset(lift), d, step)
+	return lift.Add(moved), hit
+}
+
+// Ledge returns the move d of box, standing on the ground, cut short so
+// it doesn't walk off a drop deeper than drop
+func (w *World) Ledge(box block.Cuboid, d mgl32.Vec3, drop float32) mgl32.Vec3 {
+	return ledge(w, box, d, drop)
+}
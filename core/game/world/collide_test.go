@@ -0,0 +1,158 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// boxMap is a boxSource of the blocks in it, empty elsewhere
+type boxMap map[Vec3][]block.Cuboid
+
+func (m boxMap) boxes(id Vec3) []block.Cuboid {
+	return m[id]
+}
+
+// slab is the bottom half of a block
+var slab = block.Cuboid{Min: block.FullCuboid.Min, Max: mgl32.Vec3{0.5, 0, 0.5}}
+
+// floor returns a world with a floor of full blocks at y 0, its top at
+// 0.5, from -n to n on x and z
+func floor(n float32) boxMap {
+	m := boxMap{}
+	for x := -n; x <= n; x++ {
+		for z := -n; z <= n; z++ {
+			m[Vec3{X: x, Z: z}] = []block.Cuboid{block.FullCuboid}
+		}
+	}
+	return m
+}
+
+// player returns the box of a player with its feet at feet
+func player(feet mgl32.Vec3) block.Cuboid {
+	return block.Cuboid{
+		Min: feet.Sub(mgl32.Vec3{0.3, 0, 0.3}),
+		Max: feet.Add(mgl32.Vec3{0.3, 1.8, 0.3}),
+	}
+}
+
+func assertMoved(t *testing.T, want, got mgl32.Vec3, msg string) {
+	for i := 0; i < 3; i++ {
+		assert.InDelta(t, want[i], got[i], 1e-4, "%s: %v moved, want %v", msg, got, want)
+	}
+}
+
+func TestSweepFalls(t *testing.T) {
+	w := floor(3)
+
+	moved, hit := sweep(w, player(mgl32.Vec3{0, 3, 0}), mgl32.Vec3{0, -1, 0})
+	assertMoved(t, mgl32.Vec3{0, -1, 0}, moved, "in the air")
+	assert.Equal(t, types.Collision{}, hit)
+
+	moved, hit = sweep(w, player(mgl32.Vec3{0, 3, 0}), mgl32.Vec3{0, -50, 0})
+	assertMoved(t, mgl32.Vec3{0, -2.5, 0}, moved, "onto the floor, however fast")
+	assert.Equal(t, types.Collision{Ground: true}, hit)
+
+	moved, hit = sweep(w, player(mgl32.Vec3{0, 0.5, 0}), mgl32.Vec3{0.2, -0.05, 0})
+	assertMoved(t, mgl32.Vec3{0.2, 0, 0}, moved, "walking on the floor")
+	assert.Equal(t, types.Collision{Ground: true}, hit)
+}
+
+func TestSweepCeiling(t *testing.T) {
+	w := floor(3)
+	w[Vec3{Y: 3}] = []block.Cuboid{block.FullCuboid}
+
+	moved, hit := sweep(w, player(mgl32.Vec3{0, 0.5, 0}), mgl32.Vec3{0, 1, 0})
+	assertMoved(t, mgl32.Vec3{0, 0.2, 0}, moved, "head against the ceiling")
+	assert.Equal(t, types.Collision{Ceiling: true}, hit)
+}
+
+func TestSweepWalls(t *testing.T) {
+	w := floor(3)
+	for y := float32(1); y <= 2; y++ {
+		for z := float32(-3); z <= 3; z++ {
+			w[Vec3{X: 2, Y: y, Z: z}] = []block.Cuboid{block.FullCuboid}
+		}
+	}
+	feet := mgl32.Vec3{1, 0.5, 0}
+
+	moved, hit := sweep(w, player(feet), mgl32.Vec3{10, 0, 0})
+	assertMoved(t, mgl32.Vec3{0.2, 0, 0}, moved, "no tunneling through the wall")
+	assert.Equal(t, types.Collision{Wall: true}, hit)
+
+	moved, hit = sweep(w, player(feet), mgl32.Vec3{0.5, 0, 0.5})
+	assertMoved(t, mgl32.Vec3{0.2, 0, 0.5}, moved, "sliding along the wall")
+	assert.True(t, hit.Wall)
+
+	// the player fits under a block 2 above the floor
+	w[Vec3{X: -1, Y: 3}] = []block.Cuboid{block.FullCuboid}
+	moved, _ = sweep(w, player(mgl32.Vec3{0, 0.5, 0}), mgl32.Vec3{-1, 0, 0})
+	assertMoved(t, mgl32.Vec3{-1, 0, 0}, moved, "under a low ceiling")
+}
+
+func TestCollideStepsUp(t *testing.T) {
+	w := floor(3)
+	w[Vec3{X: 1, Y: 1}] = []block.Cuboid{slab}
+	feet := mgl32.Vec3{0, 0.5, 0}
+	d := mgl32.Vec3{0.5, -0.05, 0}
+
+	moved, hit := collide(w, player(feet), d, 0.6)
+	assertMoved(t, mgl32.Vec3{0.5, 0.5, 0}, moved, "onto the slab")
+	assert.Equal(t, types.Collision{Ground: true}, hit)
+
+	moved, hit = collide(w, player(feet), d, 0)
+	assertMoved(t, mgl32.Vec3{0.2, 0, 0}, moved, "without stepping")
+	assert.Equal(t, types.Collision{Ground: true, Wall: true}, hit)
+
+	moved, _ = collide(w, player(feet.Add(mgl32.Vec3{0, 0.2, 0})), mgl32.Vec3{0.5, 0.1, 0}, 0.6)
+	assertMoved(t, mgl32.Vec3{0.2, 0.1, 0}, moved, "only from the ground")
+
+	w[Vec3{X: 1, Y: 1}] = []block.Cuboid{block.FullCuboid}
+	moved, hit = collide(w, player(feet), d, 0.6)
+	assertMoved(t, mgl32.Vec3{0.2, 0, 0}, moved, "not onto a whole block")
+	assert.True(t, hit.Wall)
+
+	// no room above the slab for the player
+	w[Vec3{X: 1, Y: 1}] = []block.Cuboid{slab}
+	w[Vec3{X: 1, Y: 3}] = []block.Cuboid{block.FullCuboid}
+	moved, _ = collide(w, player(feet), d, 0.6)
+	assertMoved(t, mgl32.Vec3{0.2, 0, 0}, moved, "under a ceiling")
+}
+
+func TestUnstick(t *testing.T) {
+	w := floor(3)
+	w[Vec3{Y: 1}] = []block.Cuboid{block.FullCuboid}
+
+	assert.InDelta(t, 1, unstick(w, player(mgl32.Vec3{0, 0.5, 0})), 1e-6, "out of the block placed on the player")
+	assert.InDelta(t, 1.3, unstick(w, player(mgl32.Vec3{0, 0.2, 0})), 1e-6, "out of both blocks")
+	assert.Zero(t, unstick(w, player(mgl32.Vec3{1, 0.5, 0.8})), "standing on the floor")
+	assert.Zero(t, unstick(w, player(mgl32.Vec3{0.8, 0.5, 0})), "against the block")
+}
+
+func TestLedge(t *testing.T) {
+	// the floor spans from -1.5 to 1.5, a player over its edge is
+	// still held by the part of the floor under it
+	w := floor(1)
+	edge := mgl32.Vec3{1.7, 0.5, 0}
+
+	d := ledge(w, player(edge), mgl32.Vec3{0.5, -0.05, 0.2}, 0.6)
+	assert.InDelta(t, 0.05, d.X(), 1e-5, "not off the edge of the floor")
+	assert.InDelta(t, 0.2, d.Z(), 1e-6, "along it")
+	assert.Equal(t, float32(-0.05), d.Y())
+
+	d = ledge(w, player(mgl32.Vec3{0, 0.5, 0}), mgl32.Vec3{0.5, 0, 0}, 0.6)
+	assert.InDelta(t, 0.5, d.X(), 1e-6, "on the floor")
+
+	d = ledge(w, player(mgl32.Vec3{1.7, 0.5, 1.7}), mgl32.Vec3{0.2, 0, 0.2}, 0.6)
+	assert.InDelta(t, 0.05, d.X(), 1e-5, "at the corner")
+	assert.InDelta(t, 0.05, d.Z(), 1e-5, "at the corner")
+
+	// a slab half a block below the floor is stepped down onto
+	w[Vec3{X: 2}] = []block.Cuboid{slab}
+	d = ledge(w, player(edge), mgl32.Vec3{0.5, 0, 0}, 0.6)
+	assert.InDelta(t, 0.5, d.X(), 1e-6, "down a step")
+}
@@ -0,0 +1,20 @@
+package world
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+// EventBlockBroken is published on the event pipe when a solid block
+// is removed from the world, by the player or by another player
+type EventBlockBroken struct {
+	ID    Vec3
+	Block *block.Block
+}
+
+// EventBlockPlaced is published on the event pipe when a solid block
+// is added to the world
+type EventBlockPlaced struct {
+	ID    Vec3
+	Block *block.Block
+}
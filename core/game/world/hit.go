@@ -0,0 +1,80 @@
+package world
+
+import (
+	"math"
+
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// reach is how far away blocks can be targeted
+const reach = 8
+
+// blockSource is what hit tests read
+type blockSource interface {
+	Block(id Vec3) *block.Block
+}
+
+// rayBlocks calls f with the blocks the ray from origin along dir goes
+// through, in order, until f returns false or the ray is longer than
+// length. dir must be normalized
+func rayBlocks(origin, dir mgl32.Vec3, length float32, f func(id Vec3) bool) {
+	// blocks are centered on whole coordinates
+	p := origin.Add(mgl32.Vec3{0.5, 0.5, 0.5})
+	cell := [3]float32{Floor(p[0]), Floor(p[1]), Floor(p[2])}
+	var step, next, delta [3]float32
+	for i := range cell {
+		switch {
+		case dir[i] > 0:
+			step[i], next[i], delta[i] = 1, (cell[i]+1-p[i])/dir[i], 1/dir[i]
+		case dir[i] < 0:
+			step[i], next[i], delta[i] = -1, (p[i]-cell[i])/-dir[i], -1/dir[i]
+		default:
+			next[i] = float32(math.Inf(1))
+		}
+	}
+	for t := float32(0); t <= length; {
+		if !f(Vec3{X: cell[0], Y: cell[1], Z: cell[2]}) {
+			return
+		}
+		// into the next block across the closest face
+		i := 0
+		if next[1] < next[i] {
+			i = 1
+		}
+		if next[2] < next[i] {
+			i = 2
+		}
+		t = next[i]
+		cell[i] += step[i]
+		next[i] += delta[i]
+	}
+}
+
+// hitTest returns the first block whose shape the ray from pos along
+// vec hits within reach, and the block the ray went through before it,
+// where a block placed against it goes. Liquids are gone through
+func hitTest(blocks blockSource, pos, vec mgl32.Vec3) (*Vec3, *Vec3) {
+	if vec.Len() == 0 {
+		return nil, nil
+	}
+	dir := vec.Normalize()
+	var hit, prev *Vec3
+	rayBlocks(pos, dir, reach, func(id Vec3) bool {
+		b := blocks.Block(id)
+		if b != nil && !b.Liquid {
+			center := mgl32.Vec3{id.X, id.Y, id.Z}
+			if t, ok := b.Hit(pos.Sub(center), dir); ok && t <= reach {
+				hit = &id
+				return false
+			}
+		}
+		prev = &id
+		return true
+	})
+	if hit == nil {
+		return nil, nil
+	}
+	return hit, prev
+}
@@ -0,0 +1,72 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockMap is a blockSource of the blocks in it, air elsewhere
+type blockMap map[Vec3]*block.Block
+
+func (m blockMap) Block(id Vec3) *block.Block {
+	if b, ok := m[id]; ok {
+		return b
+	}
+	return block.GetBlock(block.AirID)
+}
+
+func TestRayBlocks(t *testing.T) {
+	var ids []Vec3
+	rayBlocks(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1, 1, 0}.Normalize(), 2, func(id Vec3) bool {
+		ids = append(ids, id)
+		return true
+	})
+	assert.Equal(t, Vec3{}, ids[0])
+	for i := 1; i < len(ids); i++ {
+		d := Abs(ids[i].X-ids[i-1].X) + Abs(ids[i].Y-ids[i-1].Y) + Abs(ids[i].Z-ids[i-1].Z)
+		assert.Equal(t, float32(1), d, "%v to %v is across a face", ids[i-1], ids[i])
+	}
+	assert.Contains(t, ids, Vec3{X: 1, Y: 1})
+}
+
+func TestHitTest(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	w := blockMap{{X: 3}: stone}
+
+	hit, prev := hitTest(w, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1, 0, 0})
+	assert.Equal(t, &Vec3{X: 3}, hit)
+	assert.Equal(t, &Vec3{X: 2}, prev)
+
+	hit, _ = hitTest(w, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{-1, 0, 0})
+	assert.Nil(t, hit)
+
+	w[Vec3{X: 9}] = stone
+	delete(w, Vec3{X: 3})
+	hit, _ = hitTest(w, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1, 0, 0})
+	assert.Nil(t, hit, "out of reach")
+}
+
+func TestHitTestByShape(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	w := blockMap{
+		{X: 2}:       block.GetBlock(block.GrassID),
+		{X: 3}:       stone,
+		{X: 1, Y: 1}: block.GetBlock(block.WaterID),
+		{X: 1, Y: 0}: stone,
+	}
+
+	hit, prev := hitTest(w, mgl32.Vec3{1.6, 0.45, 0}, mgl32.Vec3{1, 0, 0})
+	assert.Equal(t, &Vec3{X: 3}, hit, "over the grass")
+	assert.Equal(t, &Vec3{X: 2}, prev)
+
+	hit, _ = hitTest(w, mgl32.Vec3{1.6, 0, 0.2}, mgl32.Vec3{1, 0, 0})
+	assert.Equal(t, &Vec3{X: 2}, hit, "through the grass")
+
+	hit, prev = hitTest(w, mgl32.Vec3{1, 3, 0}, mgl32.Vec3{0, -1, 0})
+	assert.Equal(t, &Vec3{X: 1}, hit, "liquids are gone through")
+	assert.Equal(t, &Vec3{X: 1, Y: 1}, prev)
+}
@@ -0,0 +1,97 @@
+package world
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chunk"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+// lightMap gives the block light flood fill access to the loaded chunks,
+// and remembers which chunks have to be meshed again
+type lightMap struct {
+	world *World
+	dirty map[Vec3]bool
+}
+
+func (m *lightMap) Block(id Vec3) *block.Block {
+	return m.world.Block(id)
+}
+
+func (m *lightMap) BlockLight(id Vec3) uint8 {
+	c, ok := m.world.loadChunk(id.ChunkID())
+	if !ok {
+		return 0
+	}
+	return c.BlockLight(id)
+}
+
+func (m *lightMap) SetBlockLight(id Vec3, l uint8) {
+	c, ok := m.world.loadChunk(id.ChunkID())
+	if !ok {
+		return
+	}
+	c.SetBlockLight(id, l)
+
+	// faces of the neighbor chunks sample the light at their border too
+	for dx := float32(-1); dx <= 1; dx++ {
+		for dz := float32(-1); dz <= 1; dz++ {
+			m.dirty[Vec3{X: id.X + dx, Z: id.Z + dz}.ChunkID()] = true
+		}
+	}
+}
+
+// updateLight runs f on a lightMap and marks the meshes of
+// all the chunks it changed as dirty
+func (w *World) updateLight(f func(m *lightMap)) {
+	m := &lightMap{
+		world: w,
+		dirty: make(map[Vec3]bool),
+	}
+
+	w.mutex.Lock()
+	f(m)
+	w.mutex.Unlock()
+
+	for id := range m.dirty {
+		w.ctx.Game().ChunkRenderer().DirtyChunk(id)
+	}
+}
+
+// relight updates the block light after the block at id changed
+func (w *World) relight(id Vec3) {
+	w.updateLight(func(m *lightMap) {
+		chunk.RelightBlock(m, id)
+	})
+}
+
+// lightChunk seeds the block light of a newly loaded chunk from its
+// light emitting blocks and the light at the border of its neighbors
+func (w *World) lightChunk(c *chunk.Chunk) {
+	w.updateLight(func(m *lightMap) {
+		var seeds []Vec3
+		c.RangeBlocks(func(id Vec3, b *block.Block) {
+			if b.LightLevel > 0 {
+				c.SetBlockLight(id, b.LightLevel)
+				seeds = append(seeds, id)
+			}
+		})
+
+		cid := c.ID()
+		for _, nid := range []Vec3{cid.Left(), cid.Right(), cid.Front(), cid.Back()} {
+			n, ok := w.loadChunk(nid)
+			if !ok {
+				continue
+			}
+			n.RangeBlockLight(func(id Vec3, l uint8) {
+				for _, side := range []Vec3{id.Left(), id.Right(), id.Front(), id.Back()} {
+					if l > 1 && side.ChunkID() == cid {
+						seeds = append(seeds, id)
+						return
+					}
+				}
+			})
+		}
+
+		chunk.SpreadLight(m, seeds)
+	})
+}
@@ -0,0 +1,207 @@
+package world
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+// flowSearchDepth is how far flowing liquid looks for a way down,
+// it only spreads in the directions closest to a drop
+const flowSearchDepth = 4
+
+// horizontal are the directions liquid spreads sideways in
+var horizontal = [4]Vec3{{X: -1}, {X: 1}, {Z: -1}, {Z: 1}}
+
+// LiquidWorld is what the liquid simulation reads and changes
+type LiquidWorld interface {
+	Block(id Vec3) *block.Block
+	// SetLiquid changes a block as the result of liquid flowing
+	SetLiquid(id Vec3, w *block.Block)
+	// Schedule updates the liquid at id after delay ticks
+	Schedule(id Vec3, delay int)
+}
+
+// replaceable tells if flowing liquid can wash away w
+func replaceable(w *block.Block) bool {
+	return w.ID == block.AirID || (w.Plant && !w.Obstacle)
+}
+
+// FlowLiquid runs one scheduled update of the liquid at id. Flowing blocks
+// take the level their neighbors feed them or dry up, then the liquid
+// flows down, or sideways when it can't
+func FlowLiquid(m LiquidWorld, id Vec3) {
+	w := m.Block(id)
+	if !w.Liquid {
+		return
+	}
+
+	if next := nextLiquid(m, id, w); next != w {
+		m.SetLiquid(id, next)
+		if next.ID == block.AirID {
+			return
+		}
+		w = next
+	}
+
+	// sources spread sideways even when they can fall,
+	// flowing liquid only spreads when there is no hole below it
+	if id.Y > 0 {
+		below := m.Block(id.Down())
+		if canFlowInto(below, w, block.FallingLevel) {
+			m.SetLiquid(id.Down(), block.LiquidVariant(w, block.FallingLevel))
+		}
+		hole := replaceable(below) || (below.SameFluid(w) && !below.IsSource())
+		if hole && !w.IsSource() {
+			return
+		}
+	}
+
+	level := w.LiquidLevel
+	if level == block.FallingLevel {
+		level = block.SourceLevel
+	}
+	level += w.FlowDrop
+	if level > block.MaxLiquidLevel {
+		return
+	}
+	for _, dir := range flowDirections(m, id, w, level) {
+		m.SetLiquid(id.Add(dir), block.LiquidVariant(w, level))
+	}
+}
+
+// nextLiquid returns what the liquid block w at id turns into, flowing
+// blocks are fed by the liquid above them or the lowest level next to them
+func nextLiquid(m LiquidWorld, id Vec3, w *block.Block) *block.Block {
+	if w.IsSource() {
+		return w
+	}
+
+	sources := 0
+	lowest := uint8(block.FallingLevel)
+	for _, dir := range horizontal {
+		n := m.Block(id.Add(dir))
+		if !n.SameFluid(w) {
+			continue
+		}
+		if n.IsSource() {
+			sources++
+		}
+		if l := feedLevel(n); l < lowest {
+			lowest = l
+		}
+	}
+
+	// two sources next to each other on solid ground make a new one
+	if w.Renewable && sources >= 2 {
+		if below := m.Block(id.Down()); below.Opaque() || (below.IsSource() && below.SameFluid(w)) {
+			return block.LiquidVariant(w, block.SourceLevel)
+		}
+	}
+
+	if m.Block(id.Up()).SameFluid(w) {
+		return block.LiquidVariant(w, block.FallingLevel)
+	}
+
+	if lowest == block.FallingLevel || lowest+w.FlowDrop > block.MaxLiquidLevel {
+		return block.GetBlock(block.AirID)
+	}
+	return block.LiquidVariant(w, lowest+w.FlowDrop)
+}
+
+// feedLevel is the level a liquid block passes on to its sides,
+// falling liquid spreads like a source where it lands
+func feedLevel(w *block.Block) uint8 {
+	if w.LiquidLevel == block.FallingLevel {
+		return block.SourceLevel
+	}
+	return w.LiquidLevel
+}
+
+// canFlowInto tells if liquid w flowing at level can replace block n
+func canFlowInto(n, w *block.Block, level uint8) bool {
+	if replaceable(n) {
+		return true
+	}
+	if !n.SameFluid(w) || n.IsSource() {
+		return false
+	}
+	if level == block.FallingLevel {
+		return n.LiquidLevel != block.FallingLevel
+	}
+	return n.LiquidLevel != block.FallingLevel && n.LiquidLevel > level
+}
+
+// flowDirections returns the sideways directions liquid w at id spreads
+// in at level, the ones with the shortest way to a drop, or all of them
+// when there is no drop close by. Liquid already flowing towards the drop
+// counts, so a stream doesn't widen once its way down is filled
+func flowDirections(m LiquidWorld, id Vec3, w *block.Block, level uint8) []Vec3 {
+	best := flowSearchDepth + 1
+	var open, dirs []Vec3
+	for _, dir := range horizontal {
+		n := m.Block(id.Add(dir))
+		if !passable(n, w) {
+			continue
+		}
+		into := canFlowInto(n, w, level)
+		if into {
+			open = append(open, dir)
+		}
+		d := dropDistance(m, id.Add(dir), w, dir, 1)
+		switch {
+		case d < best:
+			best = d
+			dirs = dirs[:0]
+			fallthrough
+		case d == best:
+			if into {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	if best > flowSearchDepth {
+		return open
+	}
+	return dirs
+}
+
+// passable tells if liquid w can flow through block n
+func passable(n, w *block.Block) bool {
+	return replaceable(n) || n.SameFluid(w)
+}
+
+// dropDistance returns the number of steps from id to the closest block
+// liquid can fall down from, not going back the way it came from
+func dropDistance(m LiquidWorld, id Vec3, w *block.Block, from Vec3, depth int) int {
+	below := m.Block(id.Down())
+	if id.Y > 0 && (replaceable(below) || (below.SameFluid(w) && !below.IsSource())) {
+		return depth
+	}
+	if depth >= flowSearchDepth {
+		return flowSearchDepth + 1
+	}
+	best := flowSearchDepth + 1
+	for _, dir := range horizontal {
+		if dir == (Vec3{X: -from.X, Z: -from.Z}) {
+			continue
+		}
+		n := id.Add(dir)
+		if !passable(m.Block(n), w) {
+			continue
+		}
+		if d := dropDistance(m, n, w, dir, depth+1); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// ScheduleLiquids schedules the liquids at and around id after
+// the block at id changed
+func ScheduleLiquids(m LiquidWorld, id Vec3) {
+	for _, n := range [...]Vec3{id, id.Up(), id.Down(), id.Left(), id.Right(), id.Front(), id.Back()} {
+		if w := m.Block(n); w.Liquid {
+			m.Schedule(n, w.FlowDelay)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package world
+
+import (
+	"os"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	block.InitRegister()
+	os.Exit(m.Run())
+}
+
+// liquidWorld is a LiquidWorld backed by a map, with its own tick queue
+type liquidWorld struct {
+	blocks    map[Vec3]*block.Block
+	ticks     int
+	scheduled map[Vec3]int
+}
+
+func newLiquidWorld() *liquidWorld {
+	return &liquidWorld{
+		blocks:    make(map[Vec3]*block.Block),
+		scheduled: make(map[Vec3]int),
+	}
+}
+
+func (w *liquidWorld) Block(id Vec3) *block.Block {
+	if b, ok := w.blocks[id]; ok {
+		return b
+	}
+	return block.GetBlock(block.AirID)
+}
+
+func (w *liquidWorld) SetLiquid(id Vec3, b *block.Block) {
+	w.set(id, b)
+}
+
+func (w *liquidWorld) Schedule(id Vec3, delay int) {
+	at := w.ticks + delay
+	if t, ok := w.scheduled[id]; ok && t <= at {
+		return
+	}
+	w.scheduled[id] = at
+}
+
+// set changes a block the way World.UpdateBlock does
+func (w *liquidWorld) set(id Vec3, b *block.Block) {
+	if b.ID == block.AirID {
+		delete(w.blocks, id)
+	} else {
+		w.blocks[id] = b
+	}
+	ScheduleLiquids(w, id)
+}
+
+// run ticks the world until no liquid updates are left
+func (w *liquidWorld) run(t *testing.T) {
+	for i := 0; len(w.scheduled) > 0; i++ {
+		if i > 10000 {
+			t.Fatal("liquid never settled")
+		}
+		w.ticks++
+		for id, at := range w.scheduled {
+			if at <= w.ticks {
+				delete(w.scheduled, id)
+				FlowLiquid(w, id)
+			}
+		}
+	}
+}
+
+// floor fills the layer y = 0 from -n to n with stone
+func (w *liquidWorld) floor(n int) {
+	stone := block.GetBlock(block.StoneID)
+	for x := -n; x <= n; x++ {
+		for z := -n; z <= n; z++ {
+			w.blocks[Vec3{X: float32(x), Z: float32(z)}] = stone
+		}
+	}
+}
+
+func TestLiquidSpreadsOnFlatGround(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	w := newLiquidWorld()
+	w.floor(12)
+
+	w.set(Vec3{Y: 1}, water)
+	w.run(t)
+
+	for x := 1; x <= block.MaxLiquidLevel; x++ {
+		b := w.Block(Vec3{X: float32(x), Y: 1})
+		assert.True(t, b.SameFluid(water), "x=%d", x)
+		assert.Equal(t, uint8(x), b.LiquidLevel, "x=%d", x)
+	}
+	assert.Equal(t, block.AirID, w.Block(Vec3{X: block.MaxLiquidLevel + 1, Y: 1}).ID)
+	// diagonals are reached around the corner
+	assert.Equal(t, uint8(2), w.Block(Vec3{X: 1, Y: 1, Z: 1}).LiquidLevel)
+}
+
+func TestLavaSpreadsShorter(t *testing.T) {
+	lava := block.GetBlock(block.LavaID)
+	w := newLiquidWorld()
+	w.floor(12)
+
+	w.set(Vec3{Y: 1}, lava)
+	w.run(t)
+
+	assert.Equal(t, uint8(6), w.Block(Vec3{X: 3, Y: 1}).LiquidLevel)
+	assert.Equal(t, block.AirID, w.Block(Vec3{X: 4, Y: 1}).ID)
+}
+
+func TestLiquidFallsDown(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	w := newLiquidWorld()
+	w.floor(12)
+
+	w.set(Vec3{Y: 5}, water)
+	w.run(t)
+
+	for y := 1; y < 5; y++ {
+		assert.Equal(t, uint8(block.FallingLevel), w.Block(Vec3{Y: float32(y)}).LiquidLevel, "y=%d", y)
+	}
+	// the source spreads on its own level even though it can fall,
+	// flowing water above a hole does not
+	assert.Equal(t, uint8(1), w.Block(Vec3{X: 1, Y: 5}).LiquidLevel)
+	assert.Equal(t, block.AirID, w.Block(Vec3{X: 2, Y: 5}).ID)
+	// the falling water spreads where it lands
+	assert.Equal(t, uint8(1), w.Block(Vec3{X: 2, Y: 1}).LiquidLevel)
+}
+
+func TestLiquidFlowsTowardsDrop(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	stone := block.GetBlock(block.StoneID)
+	w := newLiquidWorld()
+	w.floor(12)
+	// raise the ground by one and cut a hole two blocks east of the source
+	for x := -12; x <= 12; x++ {
+		for z := -12; z <= 12; z++ {
+			w.blocks[Vec3{X: float32(x), Y: 1, Z: float32(z)}] = stone
+		}
+	}
+	delete(w.blocks, Vec3{X: 2, Y: 1})
+
+	w.set(Vec3{Y: 2}, water)
+	w.run(t)
+
+	assert.True(t, w.Block(Vec3{X: 1, Y: 2}).SameFluid(water))
+	assert.Equal(t, block.AirID, w.Block(Vec3{X: -1, Y: 2}).ID)
+	assert.Equal(t, block.AirID, w.Block(Vec3{Y: 2, Z: 1}).ID)
+	assert.True(t, w.Block(Vec3{X: 2, Y: 1}).SameFluid(water))
+}
+
+func TestLiquidDriesUpWithoutSource(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	w := newLiquidWorld()
+	w.floor(12)
+
+	w.set(Vec3{Y: 1}, water)
+	w.run(t)
+	w.set(Vec3{Y: 1}, block.GetBlock(block.AirID))
+	w.run(t)
+
+	for id, b := range w.blocks {
+		assert.False(t, b.Liquid, "liquid left at %v", id)
+	}
+}
+
+func TestWaterMakesNewSources(t *testing.T) {
+	water := block.GetBlock(block.WaterID)
+	lava := block.GetBlock(block.LavaID)
+	w := newLiquidWorld()
+	w.floor(12)
+
+	w.set(Vec3{X: -1, Y: 1}, water)
+	w.set(Vec3{X: 1, Y: 1}, water)
+	w.set(Vec3{X: -1, Y: 1, Z: 5}, lava)
+	w.set(Vec3{X: 1, Y: 1, Z: 5}, lava)
+	w.run(t)
+
+	assert.True(t, w.Block(Vec3{Y: 1}).IsSource())
+	assert.False(t, w.Block(Vec3{Y: 1, Z: 5}).IsSource())
+}
+
+func TestGeneratedWaterIsHeld(t *testing.T) {
+	var oceans, lakes int
+	for x := -8; x < 8; x++ {
+		for z := -8; z < 8; z++ {
+			cid := Vec3{X: float32(x), Z: float32(z)}
+			if makeLake(cid) != nil {
+				lakes++
+			} else if x < -2 || x > 2 || z < -2 || z > 2 {
+				continue
+			}
+			m := makeChunkMap(cid)
+			for id, b := range m {
+				if !b.Liquid {
+					continue
+				}
+				assert.True(t, b.IsSource(), "flowing water generated at %v", id)
+				if id.Y == seaLevel-1 {
+					oceans++
+				}
+				// every side of generated water is water, solid ground
+				// or outside the chunk, so nothing starts to flow
+				for _, n := range []Vec3{id.Down(), id.Left(), id.Right(), id.Front(), id.Back()} {
+					if n.ChunkID() != cid || id.Y < seaLevel {
+						continue
+					}
+					w, ok := m[n]
+					assert.True(t, ok && (w.Liquid || w.Obstacle), "lake leaks at %v", n)
+				}
+			}
+		}
+	}
+	assert.NotZero(t, oceans)
+	assert.NotZero(t, lakes)
+}
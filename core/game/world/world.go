@@ -1,38 +1,63 @@
 package world
 
 import (
+	"github.com/artheus/go-events"
 	evttypes "github.com/artheus/go-events/types"
 	"github.com/artheus/go-minecraft/core/block"
 	"github.com/artheus/go-minecraft/core/chunk"
 	"github.com/artheus/go-minecraft/core/ctx"
 	"github.com/artheus/go-minecraft/core/game/rpc"
+	"github.com/artheus/go-minecraft/core/game/settings"
 	"github.com/artheus/go-minecraft/core/game/store"
 	"github.com/artheus/go-minecraft/core/types"
 	. "github.com/artheus/go-minecraft/math/f32"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/hashicorp/golang-lru"
 )
 
 type World struct {
-	ctx           *ctx.Context
-	evtPublisher  evttypes.Publisher
-	mutex         sync.Mutex
-	chunks        *lru.Cache // map[Vec3]*Chunk
+	ctx          *ctx.Context
+	evtPublisher evttypes.Publisher
+	mutex        sync.Mutex // serializes block light updates
+	chunks       *lru.Cache // map[Vec3]*Chunk
+
+	tickMx    sync.Mutex
+	ticks     int64
+	scheduled map[Vec3]int64        // tick a liquid is updated at
+	flowed    map[Vec3]*block.Block // liquid changes saved after the tick
 }
 
 func NewWorld(ctx *ctx.Context) *World {
-	m := (*chunk.RenderRadius) * (*chunk.RenderRadius) * 4
-	chunks, _ := lru.New(m)
+	chunks, _ := lru.New(cacheSize(settings.Current().RenderRadius))
 	return &World{
-		chunks: chunks,
-		ctx:    ctx,
+		chunks:       chunks,
+		ctx:          ctx,
 		evtPublisher: ctx.EventPipe().Publisher(),
+		scheduled:    make(map[Vec3]int64),
+		flowed:       make(map[Vec3]*block.Block),
 	}
 }
 
+// cacheSize is how many chunks are kept loaded for a render radius
+func cacheSize(radius int) int {
+	return radius * radius * 4
+}
+
+// Resize keeps as many chunks loaded as a render radius needs,
+// the least recently used ones are dropped when it shrinks
+func (w *World) Resize(radius int) {
+	w.chunks.Resize(cacheSize(radius))
+}
+
+// Loaded returns how many chunks are kept loaded
+func (w *World) Loaded() int {
+	return w.chunks.Len()
+}
+
 func (w *World) loadChunk(id Vec3) (*chunk.Chunk, bool) {
 	c, ok := w.chunks.Get(id)
 	if !ok {
@@ -45,65 +70,8 @@ func (w *World) storeChunk(id Vec3, chunk *chunk.Chunk) {
 	w.chunks.Add(id, chunk)
 }
 
-func (w *World) Collide(pos mgl32.Vec3) (mgl32.Vec3, bool) {
-	x, y, z := pos.X(), pos.Y(), pos.Z()
-	nx, ny, nz := Round(pos.X()), Round(pos.Y()), Round(pos.Z())
-	const pad = 0.25
-
-	head := Vec3{
-		X: nx,
-		Y: ny,
-		Z: nz,
-	}
-	foot := head.Down()
-
-	stop := false
-	for _, b := range []Vec3{foot, head} {
-		if w.Block(b.Left()).Obstacle && x < nx && nx-x > pad {
-			x = nx - pad
-		}
-		if w.Block(b.Right()).Obstacle && x > nx && x-nx > pad {
-			x = nx + pad
-		}
-		if w.Block(b.Up()).Obstacle && y > ny && y-ny > pad {
-			y = ny + pad
-			stop = true
-		}
-		if w.Block(b.Back()).Obstacle && z < nz && nz-z > pad {
-			z = nz - pad
-		}
-		if w.Block(b.Front()).Obstacle && z > nz && z-nz > pad {
-			z = nz + pad
-		}
-	}
-
-	newPos := Vec3{X: x, Y: y, Z: z}
-
-	for w.HasBlock(newPos) {
-		newPos = newPos.Up()
-	}
-
-	return mgl32.Vec3{newPos.X, newPos.Y, newPos.Z}, stop
-}
-
 func (w *World) HitTest(pos mgl32.Vec3, vec mgl32.Vec3) (*Vec3, *Vec3) {
-	var (
-		maxLen = float32(8.0)
-		step   = float32(0.125)
-
-		block, prev Vec3
-		pprev       *Vec3
-	)
-
-	for len := float32(0); len < maxLen; len += step {
-		block = chunk.NearBlock(pos.Add(vec.Mul(len)))
-		if prev != block && w.HasBlock(block) {
-			return &block, pprev
-		}
-		prev = block
-		pprev = &prev
-	}
-	return nil, nil
+	return hitTest(w, pos, vec)
 }
 
 func (w *World) Block(pos Vec3) *block.Block {
@@ -115,6 +83,20 @@ func (w *World) Block(pos Vec3) *block.Block {
 	return chunk.Block(pos)
 }
 
+// Light returns the sky light, 1 for positions exposed to the sky and 0
+// for positions below an opaque block, and the block light (0-1) at id.
+// Positions in chunks not loaded yet are lit by the sky only
+func (w *World) Light(id Vec3) (sky, torch float32) {
+	c, ok := w.loadChunk(id.ChunkID())
+	if !ok {
+		return 1, 0
+	}
+	if id.Y > c.Height(id) {
+		sky = 1
+	}
+	return sky, float32(c.BlockLight(id)) / block.MaxLightLevel
+}
+
 func (w *World) BlockChunk(block Vec3) types.IChunk {
 	cid := block.ChunkID()
 	chunk, ok := w.loadChunk(cid)
@@ -125,15 +107,162 @@ func (w *World) BlockChunk(block Vec3) types.IChunk {
 }
 
 func (w *World) UpdateBlock(id Vec3, tp *block.Block) {
+	w.setBlock(id, tp)
+	store.Storage.UpdateBlock(id, tp)
+}
+
+// setBlock changes the block at id in its chunk, when it is loaded,
+// without saving it
+func (w *World) setBlock(id Vec3, tp *block.Block) {
 	chunk := w.BlockChunk(id)
-	if chunk != nil {
-		if tp.ID != block.AirID {
-			chunk.Add(id, tp)
-		} else {
-			chunk.Del(id)
+	if chunk == nil {
+		return
+	}
+	prev := chunk.Block(id)
+	if tp.ID != block.AirID {
+		chunk.Add(id, tp)
+	} else {
+		chunk.Del(id)
+	}
+	w.relight(id)
+	w.dirtyBlock(id)
+	ScheduleLiquids(w, id)
+	w.publishChange(id, prev, tp)
+}
+
+// UpdateBlocks changes and saves many blocks, like /fill does. The
+// blocks of a chunk are relit, redrawn and saved together, and no
+// events are published for them
+func (w *World) UpdateBlocks(blocks map[Vec3]*block.Block) {
+	chunks := make(map[Vec3]map[Vec3]*block.Block)
+	for id, tp := range blocks {
+		cid := id.ChunkID()
+		if chunks[cid] == nil {
+			chunks[cid] = make(map[Vec3]*block.Block)
 		}
+		chunks[cid][id] = tp
+	}
+	for cid, changed := range chunks {
+		if c, ok := w.loadChunk(cid); ok {
+			w.setChunkBlocks(c, changed)
+		}
+		if err := store.Storage.UpdateBlocks(changed); err != nil {
+			log.Printf("save blocks of chunk %v: %s", cid, err)
+		}
+	}
+}
+
+// setChunkBlocks changes blocks of chunk c without saving them
+func (w *World) setChunkBlocks(c *chunk.Chunk, blocks map[Vec3]*block.Block) {
+	ids := make([]Vec3, 0, len(blocks))
+	dirty := map[Vec3]bool{c.ID(): true}
+	w.updateLight(func(m *lightMap) {
+		for id, tp := range blocks {
+			if tp.ID != block.AirID {
+				c.Add(id, tp)
+			} else {
+				c.Del(id)
+			}
+			ids = append(ids, id)
+			for _, neighbor := range []Vec3{id.Left(), id.Right(), id.Front(), id.Back()} {
+				dirty[neighbor.ChunkID()] = true
+			}
+		}
+		chunk.RelightBlocks(m, ids)
+	})
+	for cid := range dirty {
+		w.ctx.Game().ChunkRenderer().DirtyChunk(cid)
+	}
+	w.ctx.Game().Minimap().DirtyChunk(c.ID())
+	for _, id := range ids {
+		ScheduleLiquids(w, id)
+	}
+}
+
+// publishChange tells the event pipe a solid block was broken or
+// placed, liquids flowing are left out
+func (w *World) publishChange(id Vec3, prev, tp *block.Block) {
+	solid := func(b *block.Block) bool {
+		return b != nil && b.ID != block.AirID && !b.Liquid
+	}
+	var evt interface{}
+	switch {
+	case solid(prev) && !solid(tp):
+		evt = &EventBlockBroken{ID: id, Block: prev}
+	case solid(tp) && prev != tp:
+		evt = &EventBlockPlaced{ID: id, Block: tp}
+	default:
+		return
+	}
+	_ = w.evtPublisher.Publish(events.Event(time.Now(), evt))
+}
+
+// dirtyBlock marks the meshes and the map showing block id as dirty
+func (w *World) dirtyBlock(id Vec3) {
+	cid := id.ChunkID()
+	w.ctx.Game().ChunkRenderer().DirtyChunk(cid)
+	w.ctx.Game().Minimap().DirtyChunk(cid)
+	neighbors := []Vec3{id.Left(), id.Right(), id.Front(), id.Back()}
+	for _, neighbor := range neighbors {
+		chunkid := neighbor.ChunkID()
+		if chunkid != cid {
+			w.ctx.Game().ChunkRenderer().DirtyChunk(chunkid)
+		}
+	}
+}
+
+// SetLiquid changes the block at id as the result of liquid flowing,
+// liquid doesn't flow into chunks that aren't loaded. The changes of a
+// tick are saved together at its end
+func (w *World) SetLiquid(id Vec3, tp *block.Block) {
+	if w.BlockChunk(id) == nil {
+		return
+	}
+	w.setBlock(id, tp)
+
+	w.tickMx.Lock()
+	w.flowed[id] = tp
+	w.tickMx.Unlock()
+}
+
+// Schedule runs a liquid update of id after delay ticks
+func (w *World) Schedule(id Vec3, delay int) {
+	w.tickMx.Lock()
+	defer w.tickMx.Unlock()
+
+	at := w.ticks + int64(delay)
+	if t, ok := w.scheduled[id]; ok && t <= at {
+		return
+	}
+	w.scheduled[id] = at
+}
+
+// Tick advances the world by one tick and runs the liquid updates due
+func (w *World) Tick() {
+	w.tickMx.Lock()
+	w.ticks++
+	var due []Vec3
+	for id, at := range w.scheduled {
+		if at <= w.ticks {
+			due = append(due, id)
+			delete(w.scheduled, id)
+		}
+	}
+	w.tickMx.Unlock()
+
+	for _, id := range due {
+		FlowLiquid(w, id)
+	}
+
+	w.tickMx.Lock()
+	flowed := w.flowed
+	if len(flowed) > 0 {
+		w.flowed = make(map[Vec3]*block.Block)
+	}
+	w.tickMx.Unlock()
+	if err := store.Storage.UpdateBlocks(flowed); err != nil {
+		log.Printf("save flowed liquid: %s", err)
 	}
-	store.Storage.UpdateBlock(id, tp)
 }
 
 func (w *World) HasBlock(id Vec3) bool {
@@ -171,9 +300,47 @@ func (w *World) Chunk(id Vec3) types.IChunk {
 		store.Storage.UpdateBlock(bid, w)
 	})
 	w.storeChunk(id, chunk)
+	w.lightChunk(chunk)
+	w.scheduleLoaded(chunk)
 	return chunk
 }
 
+// scheduleLoaded schedules the liquid of chunk c, just loaded, that may
+// flow on: flowing liquid, saved while it flowed, and liquid along the
+// edges c shares with loaded chunks, which couldn't flow across them
+// while one side wasn't loaded
+func (w *World) scheduleLoaded(c *chunk.Chunk) {
+	cid := c.ID()
+	// atEdge schedules the liquid of from next to a block of chunk to
+	atEdge := func(from *chunk.Chunk, to Vec3) {
+		from.RangeBlocks(func(id Vec3, b *block.Block) {
+			if !b.Liquid {
+				return
+			}
+			for _, d := range horizontal {
+				if id.Add(d).ChunkID() == to {
+					w.Schedule(id, b.FlowDelay)
+					return
+				}
+			}
+		})
+	}
+
+	c.RangeBlocks(func(id Vec3, b *block.Block) {
+		if b.Liquid && !b.IsSource() {
+			w.Schedule(id, b.FlowDelay)
+		}
+	})
+	for _, nid := range []Vec3{cid.Left(), cid.Right(), cid.Front(), cid.Back()} {
+		n, ok := w.loadChunk(nid)
+		if !ok {
+			continue
+		}
+		atEdge(c, nid)
+		atEdge(n, cid)
+	}
+}
+
 func (w *World) Chunks(ids []Vec3) []types.IChunk {
 	ch := make(chan types.IChunk)
 	var chunks []types.IChunk
@@ -191,31 +358,103 @@ func (w *World) Chunks(ids []Vec3) []types.IChunk {
 	return chunks
 }
 
+// seaLevel is the height oceans are filled with water up to
+const seaLevel = 12
+
+// terrainHeight returns the height of the ground in column x, z
+func terrainHeight(x, z int) int {
+	f := Noise2(float32(x)*0.01, float32(z)*0.01, 4, 0.5, 2)
+	g := Noise2(float32(-x)*0.01, float32(-z)*0.01, 2, 0.9, 2)
+	mh := int(g*32 + 16)
+	return int(f * float32(mh))
+}
+
+// lakeColumn is a column of a lake, filled with water from floor up to surface
+type lakeColumn struct {
+	floor, surface int
+}
+
+const (
+	lakeChance   = 0.66 // noise a chunk needs to have a lake
+	lakeMaxDepth = 4
+)
+
+// makeLake returns the columns of the lake in the middle of chunk cid,
+// or nil when the chunk has none. Lakes are left out where the ground
+// around them is too low to hold the water
+func makeLake(cid Vec3) map[[2]int]lakeColumn {
+	if Noise2(cid.X*0.7+31, cid.Z*0.7-17, 2, 0.5, 2) < lakeChance {
+		return nil
+	}
+	cx := int(cid.X)*ChunkWidth + ChunkWidth/2
+	cz := int(cid.Z)*ChunkWidth + ChunkWidth/2
+	surface := terrainHeight(cx, cz) - 1
+	if surface <= seaLevel {
+		return nil
+	}
+	r := 4 + int(Noise2(cid.X*0.3, cid.Z*0.3, 2, 0.5, 2)*3)
+
+	lake := make(map[[2]int]lakeColumn)
+	for dx := -r - 1; dx <= r+1; dx++ {
+		for dz := -r - 1; dz <= r+1; dz++ {
+			d := Sqrt(float32(dx*dx + dz*dz))
+			if d > float32(r+1) {
+				continue
+			}
+			if terrainHeight(cx+dx, cz+dz)-1 < surface {
+				return nil
+			}
+			if d > float32(r) {
+				continue
+			}
+			depth := 1 + int(float32(lakeMaxDepth-1)*(1-d/float32(r)))
+			lake[[2]int{cx + dx, cz + dz}] = lakeColumn{floor: surface - depth + 1, surface: surface}
+		}
+	}
+	return lake
+}
+
 func makeChunkMap(cid Vec3) map[Vec3]*block.Block {
 	var (
 		grassBlock = block.GetBlock(block.GrassBlockID)
 		dirtBlock  = block.GetBlock(block.DirtID)
-		waterBlock = block.GetBlock(block.SandID)
+		sandBlock  = block.GetBlock(block.SandID)
+		water      = block.GetBlock(block.WaterID)
 		grass      = block.GetBlock(block.GrassID)
 		leaves     = block.GetBlock(block.LeavesID)
 		wood       = block.GetBlock(block.WoodID)
 		dandelion  = block.GetBlock(block.DandelionID)
-		cloud      = block.GetBlock(block.CloudID)
 	)
 	m := make(map[Vec3]*block.Block)
+	lake := makeLake(cid)
 	p, q := cid.X, cid.Z
 	for dx := 0; dx < ChunkWidth; dx++ {
 		for dz := 0; dz < ChunkWidth; dz++ {
 			x, z := int(p)*ChunkWidth+dx, int(q)*ChunkWidth+dz
-			f := Noise2(float32(x)*0.01, float32(z)*0.01, 4, 0.5, 2)
-			g := Noise2(float32(-x)*0.01, float32(-z)*0.01, 2, 0.9, 2)
-			mh := int(g*32 + 16)
-			h := int(f * float32(mh))
+			h := terrainHeight(x, z)
 			w := dirtBlock
-			if h <= 12 {
-				h = 12
-				w = waterBlock
+			if h <= seaLevel {
+				w = sandBlock
+				if h < 1 {
+					h = 1
+				}
 			}
+
+			// lakes, a sand bed with water above it
+			if lc, ok := lake[[2]int{x, z}]; ok {
+				for y := 0; y <= lc.surface; y++ {
+					tp := dirtBlock
+					switch {
+					case y >= lc.floor:
+						tp = water
+					case y == lc.floor-1:
+						tp = sandBlock
+					}
+					m[Vec3{X: float32(x), Y: float32(y), Z: float32(z)}] = tp
+				}
+				continue
+			}
+
 			// grass and sand
 			for y := 0; y < h; y++ {
 				if y == h-1 && w == dirtBlock {
@@ -226,6 +465,11 @@ func makeChunkMap(cid Vec3) map[Vec3]*block.Block {
 				m[Vec3{X: float32(x), Y: float32(y), Z: float32(z)}] = w
 			}
 
+			// oceans
+			for y := h; y < seaLevel; y++ {
+				m[Vec3{X: float32(x), Y: float32(y), Z: float32(z)}] = water
+			}
+
 			// flowers
 			if w == dirtBlock {
 				if Noise2(-float32(x)*0.1, float32(z)*0.1, 4, 0.8, 2) > 0.6 {
@@ -261,13 +505,6 @@ func makeChunkMap(cid Vec3) map[Vec3]*block.Block {
 					}
 				}
 			}
-
-			// cloud
-			for y := 64; y < 72; y++ {
-				if Noise3(float32(x)*0.01, float32(y)*0.1, float32(z)*0.01, 8, 0.5, 2) > 0.69 {
-					m[Vec3{X: float32(x), Y: float32(y), Z: float32(z)}] = cloud
-				}
-			}
 		}
 	}
 	return m
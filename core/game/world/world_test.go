@@ -0,0 +1,48 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chunk"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedChunksHaveNoClouds(t *testing.T) {
+	for x := -2; x < 2; x++ {
+		for z := -2; z < 2; z++ {
+			for id, b := range makeChunkMap(Vec3{X: float32(x), Z: float32(z)}) {
+				assert.NotEqual(t, block.CloudID, b.ID, "cloud block at %v", id)
+			}
+		}
+	}
+}
+
+func TestLoadedChunkSchedulesLiquids(t *testing.T) {
+	chunks, _ := lru.New(8)
+	w := &World{chunks: chunks, scheduled: make(map[Vec3]int64)}
+	water := block.GetBlock(block.WaterID)
+	flowing := block.LiquidVariant(water, 3)
+
+	left := chunk.NewChunk(Vec3{X: -1})
+	left.Add(Vec3{X: -1, Y: 5, Z: 3}, water) // at the edge with the chunk loaded next
+	left.Add(Vec3{X: -5, Y: 5, Z: 3}, water) // away from it
+	left.Add(Vec3{X: -20, Y: 5, Z: 3}, flowing)
+	w.storeChunk(left.ID(), left)
+	w.scheduleLoaded(left)
+	assert.Equal(t, map[Vec3]int64{{X: -20, Y: 5, Z: 3}: int64(flowing.FlowDelay)}, w.scheduled,
+		"flowing liquid goes on, sources next to chunks not loaded wait")
+
+	w.scheduled = make(map[Vec3]int64)
+	c := chunk.NewChunk(Vec3{})
+	c.Add(Vec3{X: 0, Y: 7, Z: 1}, water)
+	c.Add(Vec3{X: 31, Y: 7, Z: 1}, water)
+	w.storeChunk(c.ID(), c)
+	w.scheduleLoaded(c)
+	assert.Equal(t, map[Vec3]int64{
+		{X: -1, Y: 5, Z: 3}: int64(water.FlowDelay),
+		{X: 0, Y: 7, Z: 1}:  int64(water.FlowDelay),
+	}, w.scheduled, "the liquid on both sides of the edge with a loaded chunk")
+}
@@ -0,0 +1,167 @@
+// Package headless has renderers that draw nothing, for running the
+// world, player physics, rpc and store without a window or OpenGL
+package headless
+
+import (
+	"image"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/chunk"
+	"github.com/artheus/go-minecraft/core/chunk/state"
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/icexin/gocraft-server/proto"
+)
+
+// ChunkRenderer draws nothing, but keeps the chunks within the
+// render radius of the camera loaded, like the real renderer does
+type ChunkRenderer struct {
+	ctx *ctx.Context
+
+	mx     sync.Mutex
+	center Vec3 // chunk the loaded chunks are around
+	loaded int
+	sigch  chan struct{}
+}
+
+func NewChunkRenderer(ctx *ctx.Context) *ChunkRenderer {
+	return &ChunkRenderer{
+		ctx:   ctx,
+		sigch: make(chan struct{}, 1),
+	}
+}
+
+// Render asks UpdateLoop to load chunks when the camera moved into another chunk
+func (r *ChunkRenderer) Render() {
+	id := chunk.NearBlock(r.ctx.Game().Camera().Pos()).ChunkID()
+	r.mx.Lock()
+	moved := id != r.center || r.loaded == 0
+	r.mx.Unlock()
+	if !moved {
+		return
+	}
+	select {
+	case r.sigch <- struct{}{}:
+	default:
+	}
+}
+
+func (r *ChunkRenderer) UpdateLoop() {
+	for {
+		select {
+		case <-r.ctx.Context().Done():
+			return
+		case <-r.sigch:
+		}
+		r.LoadChunks()
+	}
+}
+
+// LoadChunks loads all chunks within the render radius of the camera
+func (r *ChunkRenderer) LoadChunks() {
+	id := chunk.NearBlock(r.ctx.Game().Camera().Pos()).ChunkID()
+	n := settings.Current().RenderRadius
+	var ids []Vec3
+	for dx := -n; dx < n; dx++ {
+		for dz := -n; dz < n; dz++ {
+			if dx*dx+dz*dz > n*n {
+				continue
+			}
+			ids = append(ids, Vec3{X: id.X + float32(dx), Z: id.Z + float32(dz)})
+		}
+	}
+	chunks := r.ctx.Game().World().Chunks(ids)
+
+	r.mx.Lock()
+	r.center, r.loaded = id, len(chunks)
+	r.mx.Unlock()
+}
+
+func (r *ChunkRenderer) State() state.State {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return state.State{CacheChunks: r.loaded}
+}
+
+// ItemIcons draws no icons
+func (r *ChunkRenderer) ItemIcons(ids []string, _ int) ([]*image.NRGBA, error) {
+	return make([]*image.NRGBA, len(ids)), nil
+}
+
+func (r *ChunkRenderer) UpdateItem(string)    {}
+func (r *ChunkRenderer) DirtyChunk(Vec3)      {}
+func (r *ChunkRenderer) Get3dMat() mgl32.Mat4 { return mgl32.Ident4() }
+func (r *ChunkRenderer) Get2dMat() mgl32.Mat4 { return mgl32.Ident4() }
+
+// PlayerRenderer draws no players
+type PlayerRenderer struct{}
+
+func (PlayerRenderer) Render()                              {}
+func (PlayerRenderer) UpdateOrAdd(int32, proto.PlayerState) {}
+func (PlayerRenderer) SetName(int32, string)                {}
+func (PlayerRenderer) SetMotion(int32, bool, bool)          {}
+func (PlayerRenderer) Remove(int32)                         {}
+func (PlayerRenderer) Players() []types.RemotePlayer        { return nil }
+
+// SkyRenderer draws no sky
+type SkyRenderer struct{}
+
+func (SkyRenderer) Render()       {}
+func (SkyRenderer) RenderClouds() {}
+
+// ParticleRenderer drops all particles
+type ParticleRenderer struct{}
+
+func (ParticleRenderer) Render()                 {}
+func (ParticleRenderer) Spawn(...types.Particle) {}
+
+// LineRenderer draws no lines
+type LineRenderer struct{}
+
+func (LineRenderer) Render() {}
+
+// InventoryRenderer draws no hotbar and never opens the screen
+type InventoryRenderer struct{}
+
+func (InventoryRenderer) Render()                {}
+func (InventoryRenderer) Toggle()                {}
+func (InventoryRenderer) Visible() bool          { return false }
+func (InventoryRenderer) SetCursor(_, _ float64) {}
+func (InventoryRenderer) HoveredSlot() int       { return -1 }
+
+// ChatRenderer draws no chat
+type ChatRenderer struct{}
+
+func (ChatRenderer) Render() {}
+
+// Minimap draws no map
+type Minimap struct{}
+
+func (Minimap) Render()         {}
+func (Minimap) DirtyChunk(Vec3) {}
+func (Minimap) UpdateLoop()     {}
+func (Minimap) Zoom()           {}
+func (Minimap) Rotate()         {}
+
+// TextRenderer draws no text
+type TextRenderer struct{}
+
+func (TextRenderer) Render()                                       {}
+func (TextRenderer) Text(string, int, int, bool) int               { return 0 }
+func (TextRenderer) TextAlpha(string, int, int, bool, float32) int { return 0 }
+func (TextRenderer) Box(image.Rectangle, mgl32.Vec4)               {}
+func (TextRenderer) Width(string) int                              { return 0 }
+func (TextRenderer) LineHeight() int                               { return 0 }
+func (TextRenderer) Size() (int, int)                              { return 0, 0 }
+
+// DebugOverlay is never shown
+type DebugOverlay struct{}
+
+func (DebugOverlay) Render()                                           {}
+func (DebugOverlay) Register(string, types.DebugSide, func() []string) {}
+func (DebugOverlay) Unregister(string)                                 {}
+func (DebugOverlay) Toggle()                                           {}
+func (DebugOverlay) Visible() bool                                     { return false }
@@ -0,0 +1,230 @@
+package hud
+
+import (
+	"image"
+	"strings"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// chatWidth is the width in gui pixels of the chat, longer
+	// messages are wrapped
+	chatWidth = 320
+	// chatShown is how many messages are shown while the console is
+	// closed, chatOpen while it is open
+	chatShown = 10
+	chatOpen  = 20
+	// chatLife is how long a message is shown, the last chatFade of it
+	// fading out
+	chatLife = 10 * time.Second
+	chatFade = time.Second
+	// chatBottom is the space kept under the chat for the hotbar
+	chatBottom = slotSize + 16
+	// cursorBlink is how long the cursor of the input line is shown
+	// and hidden
+	cursorBlink = 500 * time.Millisecond
+)
+
+var (
+	chatBackground  = mgl32.Vec4{0, 0, 0, 0.5}
+	inputBackground = mgl32.Vec4{0, 0, 0, 0.6}
+)
+
+// chatLine is a line of the chat log and its opacity
+type chatLine struct {
+	text  string
+	alpha float32
+}
+
+// chatLines returns the lines of the messages shown, oldest first.
+// While the console is closed these are the recent messages, fading
+// out, while it is open the log scrolled back scroll messages
+func chatLines(msgs []types.ChatMessage, now time.Time, open bool, scroll int) []chatLine {
+	if open {
+		end := len(msgs) - scroll
+		if end < 0 {
+			end = 0
+		}
+		start := end - chatOpen
+		if start < 0 {
+			start = 0
+		}
+		lines := make([]chatLine, 0, end-start)
+		for _, m := range msgs[start:end] {
+			lines = append(lines, chatLine{text: m.Text, alpha: 1})
+		}
+		return lines
+	}
+
+	start := len(msgs) - chatShown
+	if start < 0 {
+		start = 0
+	}
+	var lines []chatLine
+	for _, m := range msgs[start:] {
+		left := chatLife - now.Sub(m.Time)
+		if left <= 0 {
+			continue
+		}
+		alpha := float32(1)
+		if left < chatFade {
+			alpha = float32(left) / float32(chatFade)
+		}
+		lines = append(lines, chatLine{text: m.Text, alpha: alpha})
+	}
+	return lines
+}
+
+// wrap breaks s into lines no wider than width, between words when it
+// can. The color of a line carries on to the lines it is wrapped to
+func wrap(s string, width int, measure func(string) int) []string {
+	var lines []string
+	color := ""
+	line := ""
+	for _, word := range strings.SplitAfter(s, " ") {
+		if line != "" && measure(strings.TrimRight(line+word, " ")) > width {
+			lines = append(lines, color+strings.TrimRight(line, " "))
+			color = lastColor(color + line)
+			line = ""
+		}
+		// a word longer than the line is broken anywhere
+		for line == "" && measure(word) > width {
+			runes := []rune(word)
+			n := len(runes) - 1
+			for n > 1 && measure(string(runes[:n])) > width {
+				n--
+			}
+			lines = append(lines, color+string(runes[:n]))
+			color = lastColor(color + string(runes[:n]))
+			word = string(runes[n:])
+		}
+		line += word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, color+strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// lastColor returns the last color code of s, or "" when there is none
+func lastColor(s string) string {
+	runes := []rune(s)
+	color := ""
+	for i := 0; i+1 < len(runes); i++ {
+		if runes[i] != ColorCode {
+			continue
+		}
+		i++
+		if runes[i] == 'r' {
+			color = ""
+		} else if _, ok := colorCode(runes[i], White); ok {
+			color = string(runes[i-1 : i+1])
+		}
+	}
+	return color
+}
+
+// inputStart returns the first rune of line shown in width, so that
+// the cursor stays in view
+func inputStart(line []rune, cursor, width int, measure func(string) int) int {
+	start := 0
+	for start < cursor && measure(string(line[start:cursor])) > width {
+		start++
+	}
+	return start
+}
+
+// ChatRenderer draws the chat with the text renderer, the recent
+// messages over the game and, while the console is open, the log
+// and the line being typed
+type ChatRenderer struct {
+	ctx *ctx.Context
+}
+
+func NewChatRenderer(ctx *ctx.Context) *ChatRenderer {
+	return &ChatRenderer{ctx: ctx}
+}
+
+// Render adds the chat to the text renderer, it must be called before
+// the text is rendered
+func (r *ChatRenderer) Render() {
+	chat := r.ctx.Game().Chat()
+	text := r.ctx.Game().TextRenderer()
+	_, height := text.Size()
+	now := time.Now()
+	open := chat.Visible()
+	lh := text.LineHeight() + 1
+
+	var rows []chatLine
+	for _, l := range chatLines(chat.Messages(), now, open, chat.Scroll()) {
+		for _, s := range wrap(l.text, chatWidth-4, text.Width) {
+			rows = append(rows, chatLine{text: s, alpha: l.alpha})
+		}
+	}
+	limit := chatShown
+	if open {
+		limit = chatOpen
+	}
+	if len(rows) > limit {
+		rows = rows[len(rows)-limit:]
+	}
+
+	y := height - chatBottom - len(rows)*lh
+	for _, l := range rows {
+		bg := chatBackground
+		bg[3] *= l.alpha
+		text.Box(image.Rect(debugMargin, y, debugMargin+chatWidth, y+lh), bg)
+		text.TextAlpha(l.text, debugMargin+2, y+1, true, l.alpha)
+		y += lh
+	}
+	if open {
+		r.renderInput(text, chat, now, height-lh-debugMargin)
+	}
+}
+
+// renderInput adds the line being typed at y, with a blinking cursor
+func (r *ChatRenderer) renderInput(text types.ITextRenderer, chat types.IChat, now time.Time, y int) {
+	width, _ := text.Size()
+	box := image.Rect(debugMargin, y, width-debugMargin, y+text.LineHeight()+1)
+	text.Box(box, inputBackground)
+
+	s, cursor := chat.Input()
+	line := []rune(s)
+	start := inputStart(line, cursor, box.Dx()-6, text.Width)
+	end := len(line)
+	for end > cursor && text.Width(string(line[start:end])) > box.Dx()-6 {
+		end--
+	}
+	x := box.Min.X + 2
+	text.Text(string(line[start:end]), x, y+1, true)
+	if now.UnixNano()/int64(cursorBlink)%2 == 0 {
+		cx := x + text.Width(string(line[start:cursor]))
+		text.Box(image.Rect(cx, y+1, cx+1, y+text.LineHeight()), White)
+	}
+
+	if hint := completionHint(chat.Completions()); hint != "" {
+		y -= text.LineHeight() + 1
+		text.Box(image.Rect(box.Min.X, y, box.Min.X+text.Width(hint)+4, y+text.LineHeight()+1), inputBackground)
+		text.Text(hint, x, y+1, true)
+	}
+}
+
+// completionHint returns the words the typed line completes to, the
+// shown one in yellow, or "" when there is no choice
+func completionHint(lines []string, shown int) string {
+	if len(lines) < 2 {
+		return ""
+	}
+	words := make([]string, len(lines))
+	for i, l := range lines {
+		words[i] = l[strings.LastIndex(l, " ")+1:]
+		if i == shown {
+			words[i] = string(ColorCode) + "e" + words[i] + string(ColorCode) + "r"
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,60 @@
+package hud
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatLines(t *testing.T) {
+	now := time.Now()
+	var msgs []types.ChatMessage
+	for i := 0; i < 30; i++ {
+		msgs = append(msgs, types.ChatMessage{Text: string(rune('a' + i%26)), Time: now.Add(-chatLife * 2)})
+	}
+	msgs[27].Time = now.Add(-chatLife + chatFade/2)
+	msgs[28].Time = now
+	msgs[29].Time = now
+
+	lines := chatLines(msgs, now, false, 0)
+	assert.Len(t, lines, 3, "old messages are hidden while the chat is closed")
+	assert.InDelta(t, 0.5, lines[0].alpha, 0.01)
+	assert.Equal(t, float32(1), lines[2].alpha)
+
+	lines = chatLines(msgs, now, true, 0)
+	assert.Len(t, lines, chatOpen)
+	assert.Equal(t, msgs[29].Text, lines[chatOpen-1].text)
+	lines = chatLines(msgs, now, true, 25)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, msgs[4].Text, lines[4].text)
+}
+
+func TestWrap(t *testing.T) {
+	// every rune is 1 wide
+	measure := func(s string) int { return len([]rune(s)) - 2*strings.Count(s, "§") }
+
+	assert.Equal(t, []string{""}, wrap("", 10, measure))
+	assert.Equal(t, []string{"one two", "three"}, wrap("one two three", 8, measure))
+	assert.Equal(t, []string{"abcde", "fghij", "k"}, wrap("abcdefghijk", 5, measure))
+	assert.Equal(t, []string{"§cred is", "§cred §rno"}, wrap("§cred is red §rno", 7, measure),
+		"the color carries on to wrapped lines")
+}
+
+func TestLastColor(t *testing.T) {
+	assert.Equal(t, "", lastColor("plain"))
+	assert.Equal(t, "§a", lastColor("§cx§ay"))
+	assert.Equal(t, "", lastColor("§cx§ry"))
+	assert.Equal(t, "§c", lastColor("§cx§z"))
+	assert.Equal(t, "§c", lastColor("§cx§§a"), "an escaped § starts no code")
+}
+
+func TestInputStart(t *testing.T) {
+	measure := func(s string) int { return len([]rune(s)) }
+	line := []rune("0123456789")
+	assert.Equal(t, 0, inputStart(line, 10, 20, measure))
+	assert.Equal(t, 4, inputStart(line, 10, 6, measure))
+	assert.Equal(t, 0, inputStart(line, 3, 6, measure))
+}
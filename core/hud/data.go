@@ -1,108 +1,52 @@
 package hud
 
-import "github.com/artheus/go-minecraft/core/block"
-
-func WireFrameData(vertices []float32, show block.Side) []float32 {
-	if show.Left {
-		vertices = append(vertices, []float32{
-			// left
-			-0.5, -0.5, -0.5,
-			-0.5, -0.5, +0.5,
-
-			-0.5, -0.5, +0.5,
-			-0.5, +0.5, +0.5,
-
-			-0.5, +0.5, +0.5,
-			-0.5, +0.5, -0.5,
-
-			-0.5, +0.5, -0.5,
-			-0.5, -0.5, -0.5,
-		}...)
-	}
-	if show.Right {
-		vertices = append(vertices, []float32{
-			// right
-			+0.5, -0.5, +0.5,
-			+0.5, -0.5, -0.5,
-
-			+0.5, -0.5, -0.5,
-			+0.5, +0.5, -0.5,
-
-			+0.5, +0.5, -0.5,
-			+0.5, +0.5, +0.5,
-
-			+0.5, +0.5, +0.5,
-			+0.5, -0.5, +0.5,
-		}...)
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// outlineGap is how far the outline of a block is drawn from its shape,
+// so it isn't hidden by the faces of the block
+const outlineGap = 0.03
+
+// OutlineData appends the lines around the faces of the cuboids of a
+// block, centered on the origin. Faces on a side of the block which is
+// not shown are left out
+func OutlineData(vertices []float32, cuboids []block.Cuboid, show block.Side) []float32 {
+	sides := [3][2]bool{
+		{show.Left, show.Right},
+		{show.Down, show.Up},
+		{show.Back, show.Front},
 	}
-
-	if show.Up {
-		vertices = append(vertices, []float32{
-			// top
-			-0.5, +0.5, +0.5,
-			+0.5, +0.5, +0.5,
-
-			+0.5, +0.5, +0.5,
-			+0.5, +0.5, -0.5,
-
-			+0.5, +0.5, -0.5,
-			-0.5, +0.5, -0.5,
-
-			-0.5, +0.5, -0.5,
-			-0.5, +0.5, +0.5,
-		}...)
+	grow := mgl32.Vec3{outlineGap, outlineGap, outlineGap}
+	for _, c := range cuboids {
+		bounds := [2]mgl32.Vec3{c.Min.Sub(grow), c.Max.Add(grow)}
+		for axis := 0; axis < 3; axis++ {
+			for end := 0; end < 2; end++ {
+				onSide := (end == 0 && c.Min[axis] <= -0.5) || (end == 1 && c.Max[axis] >= 0.5)
+				if onSide && !sides[axis][end] {
+					continue
+				}
+				vertices = faceLines(vertices, bounds, axis, end)
+			}
+		}
 	}
+	return vertices
+}
 
-	if show.Down {
-		vertices = append(vertices, []float32{
-			// bottom
-			+0.5, -0.5, +0.5,
-			-0.5, -0.5, +0.5,
-
-			-0.5, -0.5, +0.5,
-			-0.5, -0.5, -0.5,
-
-			-0.5, -0.5, -0.5,
-			+0.5, -0.5, -0.5,
-
-			+0.5, -0.5, -0.5,
-			+0.5, -0.5, +0.5,
-		}...)
-	}
-
-	if show.Front {
-		// z front
-		vertices = append(vertices, []float32{
-			-0.5, -0.5, +0.5,
-			+0.5, -0.5, +0.5,
-
-			+0.5, -0.5, +0.5,
-			+0.5, +0.5, +0.5,
-
-			+0.5, +0.5, +0.5,
-			-0.5, +0.5, +0.5,
-
-			-0.5, +0.5, +0.5,
-			-0.5, -0.5, +0.5,
-		}...)
+// faceLines appends the four edges of the face of the box bounds at its
+// end (0 for min, 1 for max) along axis
+func faceLines(vertices []float32, bounds [2]mgl32.Vec3, axis, end int) []float32 {
+	u, v := (axis+1)%3, (axis+2)%3
+	var corners [4]mgl32.Vec3
+	for i, uv := range [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+		corners[i][axis] = bounds[end][axis]
+		corners[i][u] = bounds[uv[0]][u]
+		corners[i][v] = bounds[uv[1]][v]
 	}
-
-	if show.Back {
-		vertices = append(vertices, []float32{
-			// back
-			+0.5, -0.5, -0.5,
-			-0.5, -0.5, -0.5,
-
-			-0.5, -0.5, -0.5,
-			-0.5, +0.5, -0.5,
-
-			-0.5, +0.5, -0.5,
-			+0.5, +0.5, -0.5,
-
-			+0.5, +0.5, -0.5,
-			+0.5, -0.5, -0.5,
-		}...)
+	for i := range corners {
+		a, b := corners[i], corners[(i+1)%4]
+		vertices = append(vertices, a[0], a[1], a[2], b[0], b[1], b[2])
 	}
-
 	return vertices
 }
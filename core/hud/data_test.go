@@ -0,0 +1,32 @@
+package hud
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// outlineFloats is the length of the data of a face outline
+const outlineFloats = 4 * 2 * 3
+
+func TestOutlineData(t *testing.T) {
+	all := block.Sides(true, true, true, true, true, true)
+	data := OutlineData(nil, []block.Cuboid{block.FullCuboid}, all)
+	assert.Len(t, data, 6*outlineFloats)
+	for _, f := range data {
+		assert.InDelta(t, 0.5+outlineGap, Abs(f), 1e-6, "the outline is just outside the block")
+	}
+
+	covered := block.Sides(true, true, false, true, true, true)
+	data = OutlineData(nil, []block.Cuboid{block.FullCuboid}, covered)
+	assert.Len(t, data, 5*outlineFloats, "the covered top is left out")
+
+	torch := block.Cuboid{Min: mgl32.Vec3{-0.1, -0.5, -0.1}, Max: mgl32.Vec3{0.1, 0.4, 0.1}}
+	data = OutlineData(nil, []block.Cuboid{torch}, covered)
+	assert.Len(t, data, 6*outlineFloats, "a top within the block is drawn")
+	data = OutlineData(nil, []block.Cuboid{torch}, block.Sides(true, true, true, false, true, true))
+	assert.Len(t, data, 5*outlineFloats)
+}
@@ -0,0 +1,267 @@
+package hud
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// frameSamples is how many frame times the graph shows
+	frameSamples = 240
+	// graphHeight is the height in gui pixels of the graph, a frame
+	// of graphMax or longer fills it
+	graphHeight = 60
+	graphMax    = 50 * time.Millisecond
+	// debugMargin is the gap around the overlay and its lines
+	debugMargin = 2
+)
+
+var (
+	debugBackground = mgl32.Vec4{0.3, 0.3, 0.3, 0.55}
+	graphBackground = mgl32.Vec4{0, 0, 0, 0.35}
+	graphFast       = mgl32.Vec4{0.33, 1, 0.33, 0.9} // 60 fps or more
+	graphSlow       = mgl32.Vec4{1, 1, 0.33, 0.9}    // 30 fps or more
+	graphStall      = mgl32.Vec4{1, 0.33, 0.33, 0.9} // below 30 fps
+	graphMark       = mgl32.Vec4{1, 1, 1, 0.4}       // lines at 60 and 30 fps
+)
+
+// debugSource is a registered provider of lines
+type debugSource struct {
+	name  string
+	side  types.DebugSide
+	lines func() []string
+}
+
+// DebugOverlay draws the lines of the registered sources on the left
+// and right of the screen, and a graph of the last frame times, with
+// the text renderer. Frame times are kept while it is hidden
+type DebugOverlay struct {
+	ctx *ctx.Context
+
+	mx      sync.Mutex
+	visible bool
+	sources []debugSource
+
+	frames [frameSamples]time.Duration
+	frame  int // index of the next sample
+	last   time.Time
+}
+
+func NewDebugOverlay(ctx *ctx.Context) *DebugOverlay {
+	return &DebugOverlay{ctx: ctx}
+}
+
+// Register adds lines shown on side, a source registered again with
+// the same name replaces the old one and keeps its place
+func (o *DebugOverlay) Register(name string, side types.DebugSide, lines func() []string) {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	src := debugSource{name: name, side: side, lines: lines}
+	for i := range o.sources {
+		if o.sources[i].name == name {
+			o.sources[i] = src
+			return
+		}
+	}
+	o.sources = append(o.sources, src)
+}
+
+func (o *DebugOverlay) Unregister(name string) {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	for i := range o.sources {
+		if o.sources[i].name == name {
+			o.sources = append(o.sources[:i], o.sources[i+1:]...)
+			return
+		}
+	}
+}
+
+func (o *DebugOverlay) Toggle() {
+	o.mx.Lock()
+	o.visible = !o.visible
+	o.mx.Unlock()
+}
+
+func (o *DebugOverlay) Visible() bool {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	return o.visible
+}
+
+// Frame records the time since the previous frame
+func (o *DebugOverlay) Frame(now time.Time) {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if !o.last.IsZero() {
+		o.frames[o.frame] = now.Sub(o.last)
+		o.frame = (o.frame + 1) % frameSamples
+	}
+	o.last = now
+}
+
+// Lines returns the lines of the sources on each side, a blank line
+// separates the sources
+func (o *DebugOverlay) Lines() (left, right []string) {
+	o.mx.Lock()
+	sources := append([]debugSource(nil), o.sources...)
+	o.mx.Unlock()
+	for _, src := range sources {
+		lines := src.lines()
+		if len(lines) == 0 {
+			continue
+		}
+		side := &left
+		if src.side == types.DebugRight {
+			side = &right
+		}
+		if len(*side) > 0 {
+			*side = append(*side, "")
+		}
+		*side = append(*side, lines...)
+	}
+	return left, right
+}
+
+// frameTimes returns the recorded frame times, oldest first
+func (o *DebugOverlay) frameTimes() []time.Duration {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	times := make([]time.Duration, 0, frameSamples)
+	times = append(times, o.frames[o.frame:]...)
+	return append(times, o.frames[:o.frame]...)
+}
+
+// Render records the frame and adds the overlay to the text renderer
+// when it is shown, it must be called before the text is rendered
+func (o *DebugOverlay) Render() {
+	o.Frame(time.Now())
+	if !o.Visible() {
+		return
+	}
+	text := o.ctx.Game().TextRenderer()
+	width, height := text.Size()
+	left, right := o.Lines()
+	for _, l := range layoutColumn(left, false, width, text.LineHeight(), text.Width) {
+		text.Box(l.box, debugBackground)
+		text.Text(l.text, l.box.Min.X+1, l.box.Min.Y+1, true)
+	}
+	for _, l := range layoutColumn(right, true, width, text.LineHeight(), text.Width) {
+		text.Box(l.box, debugBackground)
+		text.Text(l.text, l.box.Min.X+1, l.box.Min.Y+1, true)
+	}
+	o.renderGraph(text, height)
+}
+
+// renderGraph draws the frame times in the bottom left corner
+func (o *DebugOverlay) renderGraph(text types.ITextRenderer, height int) {
+	times := o.frameTimes()
+	bottom := height - debugMargin
+	area := image.Rect(debugMargin, bottom-graphHeight, debugMargin+frameSamples, bottom)
+	text.Box(area, graphBackground)
+	for _, b := range graphBars(times, area) {
+		text.Box(b.rect, b.color)
+	}
+	for _, mark := range []time.Duration{time.Second / 60, time.Second / 30} {
+		y := bottom - barHeight(mark)
+		text.Box(image.Rect(area.Min.X, y, area.Max.X, y+1), graphMark)
+	}
+
+	var sum, max time.Duration
+	n := 0
+	for _, t := range times {
+		if t == 0 {
+			continue
+		}
+		sum += t
+		n++
+		if t > max {
+			max = t
+		}
+	}
+	if n == 0 {
+		return
+	}
+	label := fmt.Sprintf("frame %.1f ms avg, %.1f ms max", ms(sum/time.Duration(n)), ms(max))
+	text.Text(label, area.Min.X, area.Min.Y-text.LineHeight()-1, true)
+}
+
+// debugLine is a line of text placed on its background box
+type debugLine struct {
+	text string
+	box  image.Rectangle
+}
+
+// layoutColumn places lines from the top of the screen, against its
+// left or right edge. Blank lines are gaps without a box
+func layoutColumn(lines []string, right bool, screenWidth, lineHeight int, width func(string) int) []debugLine {
+	placed := make([]debugLine, 0, len(lines))
+	for i, s := range lines {
+		if s == "" {
+			continue
+		}
+		w := width(s) + 2
+		x := debugMargin
+		if right {
+			x = screenWidth - debugMargin - w
+		}
+		y := debugMargin + i*(lineHeight+1)
+		placed = append(placed, debugLine{text: s, box: image.Rect(x, y, x+w, y+lineHeight+1)})
+	}
+	return placed
+}
+
+// graphBar is the bar of a frame in the graph
+type graphBar struct {
+	rect  image.Rectangle
+	color mgl32.Vec4
+}
+
+// graphBars returns a bar a pixel wide for each frame time in area,
+// oldest on the left. Frames not recorded yet have no bar
+func graphBars(times []time.Duration, area image.Rectangle) []graphBar {
+	bars := make([]graphBar, 0, len(times))
+	for i, t := range times {
+		if t <= 0 {
+			continue
+		}
+		x := area.Min.X + i
+		if x >= area.Max.X {
+			break
+		}
+		c := graphFast
+		switch {
+		case t > time.Second/30:
+			c = graphStall
+		case t > time.Second/60+time.Millisecond:
+			c = graphSlow
+		}
+		bars = append(bars, graphBar{
+			rect:  image.Rect(x, area.Max.Y-barHeight(t), x+1, area.Max.Y),
+			color: c,
+		})
+	}
+	return bars
+}
+
+// barHeight returns the height in gui pixels of a frame of time t
+func barHeight(t time.Duration) int {
+	if t > graphMax {
+		t = graphMax
+	}
+	h := int(int64(t) * graphHeight / int64(graphMax))
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+func ms(t time.Duration) float64 {
+	return float64(t) / float64(time.Millisecond)
+}
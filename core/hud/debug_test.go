@@ -0,0 +1,77 @@
+package hud
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lines(s ...string) func() []string {
+	return func() []string { return s }
+}
+
+func TestDebugOverlayLines(t *testing.T) {
+	o := NewDebugOverlay(nil)
+	o.Register("a", types.DebugLeft, lines("a1", "a2"))
+	o.Register("empty", types.DebugLeft, lines())
+	o.Register("b", types.DebugRight, lines("b1"))
+	o.Register("c", types.DebugLeft, lines("c1"))
+
+	left, right := o.Lines()
+	assert.Equal(t, []string{"a1", "a2", "", "c1"}, left)
+	assert.Equal(t, []string{"b1"}, right)
+
+	// registering a name again replaces its lines in place
+	o.Register("a", types.DebugLeft, lines("new"))
+	o.Unregister("c")
+	left, _ = o.Lines()
+	assert.Equal(t, []string{"new"}, left)
+}
+
+func TestDebugOverlayToggle(t *testing.T) {
+	o := NewDebugOverlay(nil)
+	assert.False(t, o.Visible())
+	o.Toggle()
+	assert.True(t, o.Visible())
+	o.Toggle()
+	assert.False(t, o.Visible())
+}
+
+func TestFrameTimes(t *testing.T) {
+	o := NewDebugOverlay(nil)
+	now := time.Now()
+	for i := 0; i <= frameSamples+2; i++ {
+		o.Frame(now.Add(time.Duration(i*i) * time.Millisecond))
+	}
+	times := o.frameTimes()
+	require.Len(t, times, frameSamples)
+	// the two oldest frames were overwritten
+	assert.Equal(t, 5*time.Millisecond, times[0])
+	assert.Equal(t, time.Duration(2*(frameSamples+2)-1)*time.Millisecond, times[frameSamples-1])
+}
+
+func TestLayoutColumn(t *testing.T) {
+	width := func(s string) int { return 6 * len(s) }
+	left := layoutColumn([]string{"ab", "", "abc"}, false, 100, 10, width)
+	require.Len(t, left, 2, "blank lines have no box")
+	assert.Equal(t, image.Rect(2, 2, 16, 13), left[0].box)
+	assert.Equal(t, image.Rect(2, 24, 22, 35), left[1].box)
+
+	right := layoutColumn([]string{"abc"}, true, 100, 10, width)
+	assert.Equal(t, image.Rect(78, 2, 98, 13), right[0].box)
+}
+
+func TestGraphBars(t *testing.T) {
+	area := image.Rect(0, 0, 4, graphHeight)
+	bars := graphBars([]time.Duration{0, 10 * time.Millisecond, 25 * time.Millisecond, time.Second, time.Millisecond}, area)
+	require.Len(t, bars, 3, "missing samples and samples outside the area have no bar")
+	assert.Equal(t, graphFast, bars[0].color)
+	assert.Equal(t, image.Rect(1, graphHeight-12, 2, graphHeight), bars[0].rect)
+	assert.Equal(t, graphSlow, bars[1].color)
+	assert.Equal(t, graphStall, bars[2].color)
+	assert.Equal(t, image.Rect(3, 0, 4, graphHeight), bars[2].rect, "long frames fill the graph")
+}
@@ -0,0 +1,173 @@
+package hud
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/inconsolata"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	FontPath = flag.String("font", "", "bitmap font atlas, a 16x16 grid of white glyphs in Latin-1 order, the built in font when empty")
+)
+
+// fontCells is how many glyph cells there are in a row and a column
+// of the font atlas, one for each rune up to 0xff
+const fontCells = 16
+
+// Font is a bitmap font of ASCII and Latin-1, the glyphs are white
+// in cells of an atlas indexed by their rune
+type Font struct {
+	Atlas   *image.NRGBA
+	Width   int // of a cell
+	Height  int // of a cell and a line
+	advance [fontCells * fontCells]int
+}
+
+var (
+	gameFont     *Font
+	gameFontOnce sync.Once
+)
+
+// GameFont returns the font given with -font, or the built in font
+// when there is none or it can't be loaded
+func GameFont() *Font {
+	gameFontOnce.Do(func() {
+		if *FontPath != "" {
+			f, err := LoadFont(*FontPath)
+			if err == nil {
+				gameFont = f
+				return
+			}
+			log.Print(err)
+		}
+		gameFont = DefaultFont()
+	})
+	return gameFont
+}
+
+// DefaultFont builds the atlas from the Inconsolata 8x16 bitmap font
+func DefaultFont() *Font {
+	return newFont(inconsolata.Regular8x16)
+}
+
+func newFont(face *basicfont.Face) *Font {
+	f := &Font{
+		Width:  face.Width,
+		Height: face.Ascent + face.Descent,
+	}
+	f.Atlas = image.NewNRGBA(image.Rect(0, 0, fontCells*f.Width, fontCells*f.Height))
+	white := image.NewUniform(color.White)
+	for r := rune(0); r < fontCells*fontCells; r++ {
+		f.advance[r] = face.Advance
+		if !printable(r) {
+			continue
+		}
+		cell := f.cell(r)
+		dot := fixed.P(cell.Min.X, cell.Min.Y+face.Ascent)
+		dr, mask, maskp, _, ok := face.Glyph(dot, r)
+		if !ok {
+			continue
+		}
+		draw.DrawMask(f.Atlas, dr.Intersect(cell), white, image.Point{}, mask, maskp, draw.Over)
+	}
+	f.makeSolid()
+	return f
+}
+
+// LoadFont loads a font atlas image of 16x16 glyph cells. Glyphs are
+// drawn in white on transparent, or in white on black when the image
+// has no transparency. Each glyph advances by its width plus a pixel
+func LoadFont(fname string) (*Font, error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "font %s", fname)
+	}
+	f, err := NewFont(img)
+	return f, errors.Wrapf(err, "font %s", fname)
+}
+
+// NewFont makes a font from an atlas image, see LoadFont
+func NewFont(img image.Image) (*Font, error) {
+	b := img.Bounds()
+	if b.Dx()%fontCells != 0 || b.Dy()%fontCells != 0 || b.Dx() == 0 || b.Dy() == 0 {
+		return nil, errors.Errorf("atlas size %dx%d is not a multiple of %d", b.Dx(), b.Dy(), fontCells)
+	}
+	f := &Font{
+		Atlas:  image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy())),
+		Width:  b.Dx() / fontCells,
+		Height: b.Dy() / fontCells,
+	}
+	src := image.NewNRGBA(f.Atlas.Rect)
+	draw.Draw(src, src.Rect, img, b.Min, draw.Src)
+	opaque := src.Opaque()
+	for i := 0; i < len(src.Pix); i += 4 {
+		a := src.Pix[i+3]
+		if opaque {
+			p := src.Pix[i : i+3]
+			a = uint8((int(p[0]) + int(p[1]) + int(p[2])) / 3)
+		}
+		copy(f.Atlas.Pix[i:i+4], []uint8{0xff, 0xff, 0xff, a})
+	}
+	for r := rune(0); r < fontCells*fontCells; r++ {
+		f.advance[r] = f.inkWidth(r) + 1
+	}
+	f.advance[' '] = f.Width / 2
+	f.makeSolid()
+	return f, nil
+}
+
+// inkWidth returns how many columns of the cell of r have pixels
+func (f *Font) inkWidth(r rune) int {
+	cell := f.cell(r)
+	for x := cell.Max.X - 1; x >= cell.Min.X; x-- {
+		for y := cell.Min.Y; y < cell.Max.Y; y++ {
+			if f.Atlas.NRGBAAt(x, y).A > 0 {
+				return x - cell.Min.X + 1
+			}
+		}
+	}
+	return 0
+}
+
+// makeSolid fills cell 0, a control character, for backgrounds
+func (f *Font) makeSolid() {
+	draw.Draw(f.Atlas, f.cell(0), image.NewUniform(color.White), image.Point{}, draw.Src)
+}
+
+// printable tells if r is a character of ASCII or Latin-1
+func printable(r rune) bool {
+	return (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r <= 0xff)
+}
+
+func (f *Font) cell(r rune) image.Rectangle {
+	x, y := int(r)%fontCells, int(r)/fontCells
+	return image.Rect(x*f.Width, y*f.Height, (x+1)*f.Width, (y+1)*f.Height)
+}
+
+// Advance returns how far the next glyph is placed after r
+func (f *Font) Advance(r rune) int {
+	if !printable(r) {
+		r = '?'
+	}
+	return f.advance[r]
+}
+
+// Solid returns a part of the atlas that is opaque white, to draw
+// backgrounds with the same texture as the text
+func (f *Font) Solid() image.Rectangle {
+	return f.cell(0).Inset(1)
+}
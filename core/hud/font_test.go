@@ -0,0 +1,52 @@
+package hud
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hasInk(f *Font, r rune) bool {
+	return f.inkWidth(r) > 0
+}
+
+func TestDefaultFontHasASCIIAndLatin1(t *testing.T) {
+	f := DefaultFont()
+	for _, r := range []rune{'A', 'z', '~', 'é', 'Ä', 'ß', 'ÿ'} {
+		assert.True(t, hasInk(f, r), "%q has no pixels", r)
+	}
+	assert.False(t, hasInk(f, ' '))
+	assert.Equal(t, f.Advance('i'), f.Advance('W'), "monospace")
+}
+
+func TestFontSolidIsOpaque(t *testing.T) {
+	f := DefaultFont()
+	s := f.Solid()
+	assert.False(t, s.Empty())
+	assert.Equal(t, uint8(0xff), f.Atlas.NRGBAAt(s.Min.X, s.Min.Y).A)
+	assert.Equal(t, uint8(0xff), f.Atlas.NRGBAAt(s.Max.X-1, s.Max.Y-1).A)
+}
+
+func TestNewFontMeasuresGlyphs(t *testing.T) {
+	// an 8x8 cell atlas, white on black, with a 3 pixel wide 'i'
+	img := image.NewNRGBA(image.Rect(0, 0, 128, 128))
+	draw.Draw(img, img.Rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	cell := image.Rect('i'%16*8, 'i'/16*8, 'i'%16*8+3, 'i'/16*8+7)
+	draw.Draw(img, cell, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	f, err := NewFont(img)
+	require.NoError(t, err)
+	assert.Equal(t, 8, f.Width)
+	assert.Equal(t, 8, f.Height)
+	assert.Equal(t, 4, f.Advance('i'))
+	assert.Equal(t, 4, f.Advance(' '))
+	assert.Equal(t, uint8(0xff), f.Atlas.NRGBAAt(cell.Min.X, cell.Min.Y).A)
+	assert.Zero(t, f.Atlas.NRGBAAt(cell.Max.X, cell.Min.Y).A, "black is transparent")
+
+	_, err = NewFont(image.NewNRGBA(image.Rect(0, 0, 100, 128)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package hud
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// slotSize is the size in gui pixels of a hotbar slot, iconSize of
+	// the icon in it
+	slotSize = 20
+	iconSize = 16
+	// iconPixels is the size of an icon in the atlas, sharp up to a
+	// gui scale of 2
+	iconPixels = 32
+	// iconColumns is how many icons are in a row of the atlas
+	iconColumns = 8
+)
+
+var (
+	hotbarBackground = mgl32.Vec4{0, 0, 0, 0.45}
+	slotBackground   = mgl32.Vec4{0.55, 0.55, 0.55, 0.35}
+	slotHighlight    = mgl32.Vec4{1, 1, 1, 0.9}
+)
+
+// IconAtlas holds the icons of blocks in cells of iconPixels, cell 0
+// is opaque white for backgrounds
+type IconAtlas struct {
+	Image *image.NRGBA
+	cells map[string]int
+}
+
+// NewIconAtlas puts icons[i] in the cell of block ids[i], nil icons
+// are left out
+func NewIconAtlas(ids []string, icons []*image.NRGBA) *IconAtlas {
+	rows := (len(ids) + iconColumns) / iconColumns
+	a := &IconAtlas{
+		Image: image.NewNRGBA(image.Rect(0, 0, iconColumns*iconPixels, rows*iconPixels)),
+		cells: make(map[string]int),
+	}
+	draw.Draw(a.Image, a.cell(0), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, id := range ids {
+		if i >= len(icons) || icons[i] == nil {
+			continue
+		}
+		cell := i + 1
+		a.cells[id] = cell
+		draw.Draw(a.Image, a.cell(cell), icons[i], icons[i].Rect.Min, draw.Src)
+	}
+	return a
+}
+
+func (a *IconAtlas) cell(i int) image.Rectangle {
+	x, y := i%iconColumns, i/iconColumns
+	return image.Rect(x*iconPixels, y*iconPixels, (x+1)*iconPixels, (y+1)*iconPixels)
+}
+
+// Icon returns the part of the atlas with the icon of block id
+func (a *IconAtlas) Icon(id string) (image.Rectangle, bool) {
+	cell, ok := a.cells[id]
+	return a.cell(cell), ok
+}
+
+// Solid returns an opaque white part of the atlas
+func (a *IconAtlas) Solid() image.Rectangle {
+	return a.cell(0).Inset(1)
+}
+
+// hotbarSlots returns where the slots of the hotbar are on a screen of
+// width x height gui pixels, centered at the bottom
+func hotbarSlots(n, width, height int) []image.Rectangle {
+	x := (width - n*slotSize) / 2
+	y := height - slotSize - 2
+	slots := make([]image.Rectangle, n)
+	for i := range slots {
+		slots[i] = image.Rect(x+i*slotSize, y, x+(i+1)*slotSize, y+slotSize)
+	}
+	return slots
+}
+
+// frame returns the four edges of r, w pixels wide
+func frame(r image.Rectangle, w int) []image.Rectangle {
+	return []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+w),
+		image.Rect(r.Min.X, r.Max.Y-w, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y+w, r.Min.X+w, r.Max.Y-w),
+		image.Rect(r.Max.X-w, r.Min.Y+w, r.Max.X, r.Max.Y-w),
+	}
+}
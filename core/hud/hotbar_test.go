@@ -0,0 +1,52 @@
+package hud
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHotbarSlots(t *testing.T) {
+	slots := hotbarSlots(9, 200, 100)
+	require.Len(t, slots, 9)
+	assert.Equal(t, image.Rect(10, 78, 30, 98), slots[0])
+	assert.Equal(t, image.Rect(170, 78, 190, 98), slots[8])
+}
+
+func TestIconAtlas(t *testing.T) {
+	icon := image.NewNRGBA(image.Rect(0, 0, iconPixels, iconPixels))
+	icon.SetNRGBA(3, 4, color.NRGBA{R: 255, A: 255})
+	ids := make([]string, iconColumns+1)
+	ids[0], ids[1], ids[iconColumns] = "a", "missing", "b"
+	icons := make([]*image.NRGBA, len(ids))
+	icons[0], icons[iconColumns] = icon, icon
+
+	a := NewIconAtlas(ids, icons)
+	assert.Equal(t, image.Rect(0, 0, iconColumns*iconPixels, 2*iconPixels), a.Image.Rect)
+	assert.EqualValues(t, 255, a.Image.NRGBAAt(a.Solid().Min.X, a.Solid().Min.Y).A)
+
+	r, ok := a.Icon("a")
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(iconPixels, 0, 2*iconPixels, iconPixels), r)
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, a.Image.NRGBAAt(r.Min.X+3, r.Min.Y+4))
+
+	r, ok = a.Icon("b")
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(iconPixels, iconPixels, 2*iconPixels, 2*iconPixels), r, "icons wrap to the next row")
+
+	_, ok = a.Icon("missing")
+	assert.False(t, ok, "blocks without an icon are left out")
+}
+
+func TestHotbarVertices(t *testing.T) {
+	icon := image.NewNRGBA(image.Rect(0, 0, iconPixels, iconPixels))
+	r := &InventoryRenderer{icons: NewIconAtlas([]string{"a"}, []*image.NRGBA{icon})}
+	r.hotbar(nil, []types.ItemStack{{ID: "a", Count: 2}, {}, {ID: "x", Count: 1}}, 1, 200, 100)
+	// the bar, a box for each slot, the frame of the selected slot and an icon
+	quads := 1 + 3 + 4 + 1
+	assert.Len(t, r.data, quads*6*textVertexSize)
+}
@@ -0,0 +1,230 @@
+package hud
+
+import (
+	"image"
+	"strconv"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/faiface/glhf"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// inventoryColumns is the number of slots in a row of the
+	// inventory screen, the first row is the hotbar
+	inventoryColumns = 9
+	// panelPadding is the space around the slots of the screen
+	panelPadding = 8
+	// hotbarGap separates the hotbar from the other rows
+	hotbarGap = 6
+)
+
+var (
+	screenDim       = mgl32.Vec4{0, 0, 0, 0.4}
+	panelBackground = mgl32.Vec4{0.12, 0.12, 0.12, 0.85}
+	slotHover       = mgl32.Vec4{1, 1, 1, 0.35}
+)
+
+// inventoryLayout returns where the panel of the inventory screen and
+// its n slots are on a screen of width x height gui pixels. The slots
+// after the hotbar fill rows from the top, the hotbar is at the bottom
+func inventoryLayout(n, width, height, titleHeight int) (image.Rectangle, []image.Rectangle) {
+	rows := (n + inventoryColumns - 1) / inventoryColumns
+	w := inventoryColumns*slotSize + 2*panelPadding
+	h := 2*panelPadding + titleHeight + rows*slotSize + hotbarGap
+	panel := image.Rect(0, 0, w, h).Add(image.Pt((width-w)/2, (height-h)/2))
+
+	top := panel.Min.Add(image.Pt(panelPadding, panelPadding+titleHeight))
+	slots := make([]image.Rectangle, n)
+	for i := range slots {
+		row, col := i/inventoryColumns, i%inventoryColumns
+		y := top.Y + (row-1)*slotSize
+		if row == 0 {
+			y = top.Y + (rows-1)*slotSize + hotbarGap
+		}
+		x := top.X + col*slotSize
+		slots[i] = image.Rect(x, y, x+slotSize, y+slotSize)
+	}
+	return panel, slots
+}
+
+// slotAt returns the index of the slot containing p, or -1
+func slotAt(slots []image.Rectangle, p image.Point) int {
+	for i, s := range slots {
+		if p.In(s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// InventoryRenderer draws the hotbar of the game with the icons of its
+// blocks and a frame around the selected slot, and the inventory
+// screen over it when it is open
+type InventoryRenderer struct {
+	ctx     *ctx.Context
+	shader  *glhf.Shader
+	texture *glhf.Texture
+	slice   *glhf.VertexSlice
+	icons   *IconAtlas
+	data    []float32
+
+	mx      sync.Mutex
+	visible bool
+	cursor  image.Point // in gui pixels
+}
+
+// NewInventoryRenderer makes the icons of the blocks ids, the blocks
+// that can be carried
+func NewInventoryRenderer(ctx *ctx.Context, ids []string) (*InventoryRenderer, error) {
+	icons, err := ctx.Game().ChunkRenderer().ItemIcons(ids, iconPixels)
+	if err != nil {
+		return nil, err
+	}
+	r := &InventoryRenderer{
+		ctx:   ctx,
+		icons: NewIconAtlas(ids, icons),
+	}
+	mainthread.Call(func() {
+		r.shader, err = glhf.NewShader(TextVertexFormat, textUniformFormat, textVertexSource, textFragmentSource)
+		if err != nil {
+			return
+		}
+		img := r.icons.Image
+		r.texture = glhf.NewTexture(img.Rect.Dx(), img.Rect.Dy(), true, img.Pix)
+		r.slice = glhf.MakeVertexSlice(r.shader, 0, 1024)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Toggle opens or closes the inventory screen
+func (r *InventoryRenderer) Toggle() {
+	r.mx.Lock()
+	r.visible = !r.visible
+	r.mx.Unlock()
+}
+
+func (r *InventoryRenderer) Visible() bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.visible
+}
+
+// SetCursor moves the cursor of the screen, x and y are in window
+// coordinates like those of the cursor callback
+func (r *InventoryRenderer) SetCursor(x, y float64) {
+	win, _ := r.ctx.Game().Window().GetSize()
+	fb, _ := r.ctx.Game().Window().GetFramebufferSize()
+	if win <= 0 {
+		return
+	}
+	// gui pixels per window pixel
+	k := float64(fb) / float64(win) / float64(GuiScale(fb, win))
+	r.mx.Lock()
+	r.cursor = image.Pt(int(x*k), int(y*k))
+	r.mx.Unlock()
+}
+
+// HoveredSlot returns the inventory slot under the cursor, or -1 when
+// the screen is closed or the cursor is not over a slot
+func (r *InventoryRenderer) HoveredSlot() int {
+	if !r.Visible() {
+		return -1
+	}
+	text := r.ctx.Game().TextRenderer()
+	width, height := text.Size()
+	n := len(r.ctx.Game().Inventory().Stacks())
+	_, slots := inventoryLayout(n, width, height, text.LineHeight())
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return slotAt(slots, r.cursor)
+}
+
+func (r *InventoryRenderer) box(rect image.Rectangle, c mgl32.Vec4) {
+	r.data = appendQuad(r.data, r.icons.Image.Rect.Size(), rect, r.icons.Solid(), c)
+}
+
+// icon adds the icon of s in slot rect, the count is added to text
+func (r *InventoryRenderer) icon(text types.ITextRenderer, rect image.Rectangle, s types.ItemStack) {
+	if s.Empty() {
+		return
+	}
+	if src, ok := r.icons.Icon(s.ID); ok {
+		inset := (slotSize - iconSize) / 2
+		r.data = appendQuad(r.data, r.icons.Image.Rect.Size(), rect.Inset(inset), src, White)
+	}
+	if s.Count > 1 && text != nil {
+		count := strconv.Itoa(s.Count)
+		text.Text(count, rect.Max.X-text.Width(count), rect.Max.Y-text.LineHeight()+2, true)
+	}
+}
+
+// hotbar lays out the hotbar on a screen of width x height gui pixels
+func (r *InventoryRenderer) hotbar(text types.ITextRenderer, slots []types.ItemStack, selected, width, height int) {
+	rects := hotbarSlots(len(slots), width, height)
+	if len(rects) == 0 {
+		return
+	}
+	bar := image.Rect(rects[0].Min.X, rects[0].Min.Y, rects[len(rects)-1].Max.X, rects[0].Max.Y).Inset(-1)
+	r.box(bar, hotbarBackground)
+	for i, rect := range rects {
+		r.box(rect.Inset(1), slotBackground)
+		if i == selected {
+			for _, edge := range frame(rect.Inset(-1), 2) {
+				r.box(edge, slotHighlight)
+			}
+		}
+		r.icon(text, rect, slots[i])
+	}
+}
+
+// screen lays out the inventory screen, with the held stack at the
+// cursor
+func (r *InventoryRenderer) screen(text types.ITextRenderer, inv types.IInventory, width, height int) {
+	stacks := inv.Stacks()
+	panel, rects := inventoryLayout(len(stacks), width, height, text.LineHeight())
+	r.mx.Lock()
+	cursor := r.cursor
+	r.mx.Unlock()
+
+	r.box(image.Rect(0, 0, width, height), screenDim)
+	r.box(panel, panelBackground)
+	text.Text("Inventory", panel.Min.X+panelPadding, panel.Min.Y+panelPadding-2, true)
+	hovered := slotAt(rects, cursor)
+	for i, rect := range rects {
+		r.box(rect.Inset(1), slotBackground)
+		r.icon(text, rect, stacks[i])
+		if i == hovered {
+			r.box(rect.Inset(1), slotHover)
+		}
+	}
+	held := image.Rect(0, 0, slotSize, slotSize).Add(cursor.Sub(image.Pt(slotSize/2, slotSize/2)))
+	r.icon(text, held, inv.Held())
+}
+
+// Render draws the hotbar and the open screen, before the text so the
+// counts are drawn over them
+func (r *InventoryRenderer) Render() {
+	game := r.ctx.Game()
+	text := game.TextRenderer()
+	hotbar := game.Hotbar()
+	win, _ := game.Window().GetSize()
+	width, height := game.Window().GetFramebufferSize()
+	scale := GuiScale(width, win)
+
+	r.data = r.data[:0]
+	if !r.Visible() {
+		r.hotbar(text, hotbar.Slots(), hotbar.Selected(), width/scale, height/scale)
+	} else {
+		// the counts of the hotbar would be drawn over the screen
+		r.hotbar(nil, hotbar.Slots(), hotbar.Selected(), width/scale, height/scale)
+		r.screen(text, game.Inventory(), width/scale, height/scale)
+	}
+	drawGui(r.shader, r.texture, r.slice, r.data, width, height, scale)
+}
@@ -72,4 +72,4 @@ func (l *Lines) Release() {
 		l.vao = 0
 		l.vbo = 0
 	}
-}
\ No newline at end of file
+}
@@ -15,7 +15,7 @@ type LineRenderer struct {
 	shader    *glhf.Shader
 	cross     *Lines
 	wireFrame *Lines
-	lastBlock Vec3
+	outline   outline // the wireFrame is made for
 }
 
 // NewLineRenderer creates a new instance of LineRenderer
@@ -57,36 +57,44 @@ func (r *LineRenderer) renderCrosshairs() {
 	r.cross.Render(project.Mul4(model))
 }
 
-// renderWireFrame will render a wireframe around blocks currently
-// pointed at by player's crosshairs
+// outline is what the wireframe around a block is made for, it is
+// made again when the block or its neighbors change
+type outline struct {
+	id    Vec3
+	block *block.Block
+	show  block.Side
+}
+
+// renderWireFrame will render a wireframe around the shape of the
+// block currently pointed at by player's crosshairs
 func (r *LineRenderer) renderWireFrame(mat mgl32.Mat4) {
-	var vertices []float32
-	b, _ := r.ctx.Game().World().HitTest(r.ctx.Game().Camera().Pos(), r.ctx.Game().Camera().Front())
+	world := r.ctx.Game().World()
+	b, _ := world.HitTest(r.ctx.Game().Camera().Pos(), r.ctx.Game().Camera().Front())
 	if b == nil {
 		return
 	}
 
-	mat = mat.Mul4(mgl32.Translate3D(float32(b.X), float32(b.Y), float32(b.Z)))
-	mat = mat.Mul4(mgl32.Scale3D(1.06, 1.06, 1.06))
-	if *b == r.lastBlock {
+	id := *b
+	hidden := func(id Vec3) bool {
+		n := world.Block(id)
+		return !n.Visible || n.Transparent
+	}
+	o := outline{
+		id:    id,
+		block: world.Block(id),
+		show:  block.Sides(hidden(id.Left()), hidden(id.Right()), hidden(id.Up()), hidden(id.Down()), hidden(id.Front()), hidden(id.Back())),
+	}
+	mat = mat.Mul4(mgl32.Translate3D(id.X, id.Y, id.Z))
+	if o == r.outline && r.wireFrame != nil {
 		r.wireFrame.Render(mat)
 		return
 	}
 
-	id := *b
-	show := block.Sides(
-		!r.ctx.Game().World().Block(id.Left()).Visible || r.ctx.Game().World().Block(id.Left()).Transparent,
-		!r.ctx.Game().World().Block(id.Right()).Visible || r.ctx.Game().World().Block(id.Right()).Transparent,
-		!r.ctx.Game().World().Block(id.Up()).Visible || r.ctx.Game().World().Block(id.Up()).Transparent,
-		!r.ctx.Game().World().Block(id.Down()).Visible || r.ctx.Game().World().Block(id.Down()).Transparent,
-		!r.ctx.Game().World().Block(id.Front()).Visible || r.ctx.Game().World().Block(id.Front()).Transparent,
-		!r.ctx.Game().World().Block(id.Back()).Visible || r.ctx.Game().World().Block(id.Back()).Transparent,
-	)
-	vertices = WireFrameData(vertices, show)
+	vertices := OutlineData(nil, o.block.Shape(), o.show)
 	if len(vertices) == 0 {
 		return
 	}
-	r.lastBlock = *b
+	r.outline = o
 	if r.wireFrame != nil {
 		r.wireFrame.Release()
 	}
@@ -98,9 +106,9 @@ func (r *LineRenderer) renderWireFrame(mat mgl32.Mat4) {
 // Render lines (crosshairs and wireframe) to screen
 func (r *LineRenderer) Render() {
 	width, height := r.ctx.Game().Window().GetSize()
-	projection := mgl32.Perspective(Radian(45), float32(width)/float32(height), 0.01, ChunkWidth)
-	camera := r.ctx.Game().Camera().Matrix()
-	mat := projection.Mul4(camera)
+	camera := r.ctx.Game().Camera()
+	projection := mgl32.Perspective(Radian(camera.FOV()), float32(width)/float32(height), 0.01, ChunkWidth)
+	mat := projection.Mul4(camera.Matrix())
 
 	r.shader.Begin()
 	r.renderCrosshairs()
@@ -21,5 +21,43 @@ out vec4 color;
 void main() {
     color = vec4(1.0, 1.0, 1.0, 1.0);
 }
+`
+
+	textVertexSource = `
+#version 330 core
+
+in vec2 pos;
+in vec2 tex;
+in vec4 color;
+
+uniform mat4 matrix;
+
+out vec2 Tex;
+out vec4 Color;
+
+void main() {
+    gl_Position = matrix * vec4(pos, 0.0, 1.0);
+    Tex = tex;
+    Color = color;
+}
+`
+
+	textFragmentSource = `
+#version 330 core
+
+in vec2 Tex;
+in vec4 Color;
+uniform sampler2D tex;
+
+out vec4 FragColor;
+
+void main() {
+    // glyphs are white, icons keep their colors
+    vec4 c = Color * texture(tex, Tex);
+    if (c.a == 0) {
+        discard;
+    }
+    FragColor = c;
+}
 `
 )
@@ -0,0 +1,169 @@
+package hud
+
+import (
+	"image"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ColorCode starts a color code in text, it is followed by a hex digit
+// for one of the 16 colors, or r to reset to the color of the text.
+// Twice it is shown once, not starting a code
+const ColorCode = '§'
+
+// Colors are the colors of the codes 0-9 and a-f
+var Colors = [16]mgl32.Vec4{
+	rgb(0x000000), rgb(0x0000aa), rgb(0x00aa00), rgb(0x00aaaa),
+	rgb(0xaa0000), rgb(0xaa00aa), rgb(0xffaa00), rgb(0xaaaaaa),
+	rgb(0x555555), rgb(0x5555ff), rgb(0x55ff55), rgb(0x55ffff),
+	rgb(0xff5555), rgb(0xff55ff), rgb(0xffff55), rgb(0xffffff),
+}
+
+// White is the default color of text
+var White = mgl32.Vec4{1, 1, 1, 1}
+
+func rgb(c uint32) mgl32.Vec4 {
+	return mgl32.Vec4{float32(c>>16&0xff) / 255, float32(c>>8&0xff) / 255, float32(c&0xff) / 255, 1}
+}
+
+// shadowDarken is how much darker a shadow is than its text
+const shadowDarken = 0.25
+
+// Quad is a glyph placed in a line of text, Dst is in pixels from the
+// top left corner of the line and Src in the atlas
+type Quad struct {
+	Dst, Src image.Rectangle
+	Color    mgl32.Vec4
+}
+
+// colorCode returns the color selected by the code after ColorCode
+func colorCode(code rune, base mgl32.Vec4) (mgl32.Vec4, bool) {
+	switch {
+	case code >= '0' && code <= '9':
+		return Colors[code-'0'], true
+	case code >= 'a' && code <= 'f':
+		return Colors[code-'a'+10], true
+	case code == 'r':
+		return base, true
+	}
+	return base, false
+}
+
+// Layout places the glyphs of s on a line in color c, changed by the
+// color codes in s. Runes the font doesn't have are shown as '?'. It
+// returns the quads and the width of the line
+func (f *Font) Layout(s string, c mgl32.Vec4) ([]Quad, int) {
+	quads := make([]Quad, 0, len(s))
+	x := 0
+	runes := []rune(s)
+	current := c
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == ColorCode && i+1 < len(runes) {
+			if runes[i+1] == ColorCode {
+				i++
+			} else if code, ok := colorCode(runes[i+1], c); ok {
+				current = code
+				i++
+				continue
+			}
+		}
+		if !printable(r) {
+			r = '?'
+		}
+		if r != ' ' {
+			quads = append(quads, Quad{
+				Dst:   image.Rect(x, 0, x+f.Width, f.Height),
+				Src:   f.cell(r),
+				Color: current,
+			})
+		}
+		x += f.Advance(r)
+	}
+	return quads, x
+}
+
+// Measure returns how wide s is, without its color codes
+func (f *Font) Measure(s string) int {
+	_, w := f.Layout(s, White)
+	return w
+}
+
+// TextBatch collects the quads of text and boxes as triangles of
+// position, atlas uv and color, so they can be drawn together
+type TextBatch struct {
+	Font *Font
+	data []float32
+}
+
+// textVertexSize is the number of floats of a vertex
+const textVertexSize = 8
+
+// NewTextBatch returns an empty batch of text in font f
+func NewTextBatch(f *Font) *TextBatch {
+	return &TextBatch{Font: f}
+}
+
+// Text adds s with its top left corner at x, y, with y down, and a
+// shadow when shadow is set. It returns the width of s
+func (b *TextBatch) Text(s string, x, y int, shadow bool) int {
+	return b.TextAlpha(s, x, y, shadow, 1)
+}
+
+// TextAlpha adds s like Text, with the opacity of all its colors
+// multiplied by alpha
+func (b *TextBatch) TextAlpha(s string, x, y int, shadow bool, alpha float32) int {
+	quads, width := b.Font.Layout(s, White)
+	if shadow {
+		for _, q := range quads {
+			c := q.Color.Mul(shadowDarken)
+			c[3] = q.Color[3] * alpha
+			b.quad(q.Dst.Add(image.Pt(x+1, y+1)), q.Src, c)
+		}
+	}
+	for _, q := range quads {
+		c := q.Color
+		c[3] *= alpha
+		b.quad(q.Dst.Add(image.Pt(x, y)), q.Src, c)
+	}
+	return width
+}
+
+// Box adds a box of color c
+func (b *TextBatch) Box(r image.Rectangle, c mgl32.Vec4) {
+	b.quad(r, b.Font.Solid(), c)
+}
+
+func (b *TextBatch) quad(dst, src image.Rectangle, c mgl32.Vec4) {
+	b.data = appendQuad(b.data, b.Font.Atlas.Rect.Size(), dst, src, c)
+}
+
+// appendQuad appends the two triangles drawing the src part of an
+// atlas of size atlas at dst, tinted by c
+func appendQuad(data []float32, atlas image.Point, dst, src image.Rectangle, c mgl32.Vec4) []float32 {
+	u0, v0 := float32(src.Min.X)/float32(atlas.X), float32(src.Min.Y)/float32(atlas.Y)
+	u1, v1 := float32(src.Max.X)/float32(atlas.X), float32(src.Max.Y)/float32(atlas.Y)
+	x0, y0 := float32(dst.Min.X), float32(dst.Min.Y)
+	x1, y1 := float32(dst.Max.X), float32(dst.Max.Y)
+	corners := [4][4]float32{{x0, y1, u0, v1}, {x1, y1, u1, v1}, {x1, y0, u1, v0}, {x0, y0, u0, v0}}
+	for _, i := range []int{0, 1, 2, 0, 2, 3} {
+		p := corners[i]
+		data = append(data, p[0], p[1], p[2], p[3], c[0], c[1], c[2], c[3])
+	}
+	return data
+}
+
+// Vertices returns the triangles added since the last Reset
+func (b *TextBatch) Vertices() []float32 {
+	return b.data
+}
+
+// Len returns the number of vertices in the batch
+func (b *TextBatch) Len() int {
+	return len(b.data) / textVertexSize
+}
+
+// Reset empties the batch
+func (b *TextBatch) Reset() {
+	b.data = b.data[:0]
+}
@@ -0,0 +1,163 @@
+package hud
+
+import (
+	"image"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/faiface/glhf"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	TextVertexFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "pos", Type: glhf.Vec2},
+		glhf.Attr{Name: "tex", Type: glhf.Vec2},
+		glhf.Attr{Name: "color", Type: glhf.Vec4},
+	}
+	textUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+	}
+)
+
+// GuiScale returns how many framebuffer pixels a font pixel covers,
+// a whole number so the glyphs stay sharp, larger on HiDPI screens
+// where the framebuffer is larger than the window
+func GuiScale(fbWidth, winWidth int) int {
+	if winWidth <= 0 {
+		return 1
+	}
+	scale := (fbWidth + winWidth/2) / winWidth
+	if scale < 1 {
+		return 1
+	}
+	return scale
+}
+
+// TextRenderer draws the text added during a frame over the screen,
+// with a single draw call. Positions are in gui pixels, see Size
+type TextRenderer struct {
+	ctx     *ctx.Context
+	shader  *glhf.Shader
+	texture *glhf.Texture
+	slice   *glhf.VertexSlice
+
+	mx    sync.Mutex
+	batch *TextBatch
+}
+
+// NewTextRenderer creates a text renderer using GameFont
+func NewTextRenderer(ctx *ctx.Context) (*TextRenderer, error) {
+	font := GameFont()
+	r := &TextRenderer{
+		ctx:   ctx,
+		batch: NewTextBatch(font),
+	}
+	var err error
+	mainthread.Call(func() {
+		r.shader, err = glhf.NewShader(TextVertexFormat, textUniformFormat, textVertexSource, textFragmentSource)
+		if err != nil {
+			return
+		}
+		r.texture = glhf.NewTexture(font.Atlas.Rect.Dx(), font.Atlas.Rect.Dy(), false, font.Atlas.Pix)
+		r.slice = glhf.MakeVertexSlice(r.shader, 0, 1024)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Font returns the font of the text
+func (r *TextRenderer) Font() *Font {
+	return r.batch.Font
+}
+
+// Scale returns the gui scale of the window
+func (r *TextRenderer) Scale() int {
+	win, _ := r.ctx.Game().Window().GetSize()
+	fb, _ := r.ctx.Game().Window().GetFramebufferSize()
+	return GuiScale(fb, win)
+}
+
+// Size returns the size of the screen in gui pixels
+func (r *TextRenderer) Size() (int, int) {
+	width, height := r.ctx.Game().Window().GetFramebufferSize()
+	scale := r.Scale()
+	return width / scale, height / scale
+}
+
+// Width returns how wide s is in gui pixels
+func (r *TextRenderer) Width(s string) int {
+	return r.batch.Font.Measure(s)
+}
+
+// LineHeight returns the height of a line of text in gui pixels
+func (r *TextRenderer) LineHeight() int {
+	return r.batch.Font.Height
+}
+
+// Text adds s at x, y for the next Render, see TextBatch.Text
+func (r *TextRenderer) Text(s string, x, y int, shadow bool) int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.batch.Text(s, x, y, shadow)
+}
+
+// TextAlpha adds s faded to alpha, see TextBatch.TextAlpha
+func (r *TextRenderer) TextAlpha(s string, x, y int, shadow bool, alpha float32) int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.batch.TextAlpha(s, x, y, shadow, alpha)
+}
+
+// Box adds a box of color c for the next Render, boxes are drawn
+// in the order they are added, under the text added after them
+func (r *TextRenderer) Box(rect image.Rectangle, c mgl32.Vec4) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.batch.Box(rect, c)
+}
+
+// Render draws the text and boxes added since the last Render
+func (r *TextRenderer) Render() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	defer r.batch.Reset()
+	if r.batch.Len() == 0 {
+		return
+	}
+
+	width, height := r.ctx.Game().Window().GetFramebufferSize()
+	drawGui(r.shader, r.texture, r.slice, r.batch.Vertices(), width, height, r.Scale())
+}
+
+// drawGui draws triangles of TextVertexFormat over the framebuffer
+// of width x height, in gui pixels of scale framebuffer pixels
+func drawGui(shader *glhf.Shader, texture *glhf.Texture, slice *glhf.VertexSlice, data []float32, width, height, scale int) {
+	w, h := float32(width)/float32(scale), float32(height)/float32(scale)
+	matrix := mgl32.Ortho2D(0, w, h, 0)
+
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.CULL_FACE)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	defer func() {
+		gl.Disable(gl.BLEND)
+		gl.Enable(gl.CULL_FACE)
+		gl.Enable(gl.DEPTH_TEST)
+	}()
+
+	shader.Begin()
+	shader.SetUniformAttr(0, matrix)
+	texture.Begin()
+	slice.Begin()
+	slice.SetLen(len(data) / textVertexSize)
+	slice.SetVertexData(data)
+	slice.Draw()
+	slice.End()
+	texture.End()
+	shader.End()
+}
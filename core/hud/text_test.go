@@ -0,0 +1,87 @@
+package hud
+
+import (
+	"image"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayout(t *testing.T) {
+	f := DefaultFont()
+	quads, width := f.Layout("a b€", White)
+	assert.Equal(t, 4*f.Advance('a'), width)
+	// the space has no quad, and the euro sign isn't in Latin-1
+	require.Len(t, quads, 3)
+	assert.Equal(t, image.Rect(0, 0, f.Width, f.Height), quads[0].Dst)
+	assert.Equal(t, f.cell('a'), quads[0].Src)
+	assert.Equal(t, 2*f.Advance('b'), quads[1].Dst.Min.X)
+	assert.Equal(t, f.cell('?'), quads[2].Src)
+}
+
+func TestLayoutColorCodes(t *testing.T) {
+	f := DefaultFont()
+	base := mgl32.Vec4{0.5, 0.5, 0.5, 1}
+	quads, width := f.Layout("a§cb§rc§zd§", base)
+	assert.Equal(t, 7*f.Advance('a'), width, "only valid codes are hidden")
+	require.Len(t, quads, 7)
+	assert.Equal(t, base, quads[0].Color)
+	assert.Equal(t, Colors[12], quads[1].Color)
+	assert.Equal(t, base, quads[2].Color)
+	assert.Equal(t, f.cell('§'), quads[3].Src)
+	assert.Equal(t, f.cell('§'), quads[6].Src)
+	assert.Equal(t, width, f.Measure("a§cb§rc§zd§"))
+
+	quads, width = f.Layout("§§cx", base)
+	assert.Equal(t, 3*f.Advance('a'), width, "a doubled § is shown once")
+	require.Len(t, quads, 3)
+	assert.Equal(t, f.cell('§'), quads[0].Src)
+	assert.Equal(t, base, quads[2].Color, "and starts no code")
+}
+
+// vertex returns vertex i of the batch as x, y, u, v, r, g, b, a
+func vertex(b *TextBatch, i int) []float32 {
+	return b.Vertices()[i*textVertexSize : (i+1)*textVertexSize]
+}
+
+func TestTextBatchShadowsGoFirst(t *testing.T) {
+	f := DefaultFont()
+	b := NewTextBatch(f)
+	width := b.Text("§ex", 10, 20, true)
+	assert.Equal(t, f.Advance('x'), width)
+	require.Equal(t, 12, b.Len())
+
+	shadow, text := vertex(b, 0), vertex(b, 6)
+	assert.Equal(t, []float32{11, float32(21 + f.Height)}, shadow[:2], "one pixel down right")
+	assert.Equal(t, []float32{10, float32(20 + f.Height)}, text[:2])
+	yellow := Colors[14]
+	assert.Equal(t, yellow[:], text[4:])
+	assert.Equal(t, []float32{yellow[0] / 4, yellow[1] / 4, yellow[2] / 4, 1}, shadow[4:])
+
+	b.Reset()
+	assert.Zero(t, b.Len())
+}
+
+func TestTextBatchBoxUsesTheSolidCell(t *testing.T) {
+	f := DefaultFont()
+	b := NewTextBatch(f)
+	c := mgl32.Vec4{0, 0, 0, 0.5}
+	b.Box(image.Rect(0, 0, 100, 10), c)
+	require.Equal(t, 6, b.Len())
+	size := f.Atlas.Rect.Size()
+	for i := 0; i < 6; i++ {
+		v := vertex(b, i)
+		u, w := int(v[2]*float32(size.X)+0.5), int(v[3]*float32(size.Y)+0.5)
+		assert.True(t, image.Pt(u, w).In(f.Solid().Inset(-1)), "uv %v %v", u, w)
+		assert.Equal(t, c[:], v[4:])
+	}
+}
+
+func TestGuiScale(t *testing.T) {
+	assert.Equal(t, 1, GuiScale(800, 800))
+	assert.Equal(t, 2, GuiScale(1600, 800), "HiDPI")
+	assert.Equal(t, 2, GuiScale(1200, 800), "rounded to whole pixels")
+	assert.Equal(t, 1, GuiScale(0, 0))
+}
@@ -0,0 +1,64 @@
+package inventory
+
+import (
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/types"
+)
+
+// HotbarSlots is the number of slots of the hotbar
+const HotbarSlots = 9
+
+// Hotbar is the first row of an inventory, the blocks at hand. The
+// block in the selected slot is the one placed
+type Hotbar struct {
+	inv *Inventory
+
+	mx       sync.Mutex
+	selected int
+}
+
+// NewHotbar returns the hotbar of inv
+func NewHotbar(inv *Inventory) *Hotbar {
+	return &Hotbar{inv: inv}
+}
+
+// Slots returns the stack in each slot
+func (h *Hotbar) Slots() []types.ItemStack {
+	return h.inv.Stacks()[:HotbarSlots]
+}
+
+func (h *Hotbar) Selected() int {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	return h.selected
+}
+
+// Select selects slot i, slots out of range are ignored
+func (h *Hotbar) Select(i int) {
+	if i < 0 || i >= HotbarSlots {
+		return
+	}
+	h.mx.Lock()
+	h.selected = i
+	h.mx.Unlock()
+}
+
+// Scroll moves the selection by n slots, wrapping around the ends
+func (h *Hotbar) Scroll(n int) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	h.selected = ((h.selected+n)%HotbarSlots + HotbarSlots) % HotbarSlots
+}
+
+// Item returns the block in the selected slot, "" when it is empty
+func (h *Hotbar) Item() string {
+	return h.inv.Slot(h.Selected()).ID
+}
+
+// Consume takes a block of the selected slot, it returns the block
+// and false when the slot is empty
+func (h *Hotbar) Consume() (string, bool) {
+	taken := h.inv.Take(h.Selected(), 1)
+	return taken.ID, !taken.Empty()
+}
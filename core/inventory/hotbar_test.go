@@ -0,0 +1,44 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotbarSelect(t *testing.T) {
+	inv := New()
+	inv.SetSlot(0, types.ItemStack{ID: "a", Count: 1})
+	inv.SetSlot(2, types.ItemStack{ID: "c", Count: 1})
+	inv.SetSlot(HotbarSlots, types.ItemStack{ID: "x", Count: 1})
+	h := NewHotbar(inv)
+	assert.Equal(t, "a", h.Item())
+	assert.Len(t, h.Slots(), HotbarSlots)
+
+	h.Select(2)
+	assert.Equal(t, "c", h.Item())
+	h.Select(HotbarSlots)
+	assert.Equal(t, 2, h.Selected(), "slots out of range are ignored")
+
+	h.Scroll(-3)
+	assert.Equal(t, HotbarSlots-1, h.Selected(), "scrolling wraps around")
+	assert.Equal(t, "", h.Item())
+	h.Scroll(2)
+	assert.Equal(t, 1, h.Selected())
+}
+
+func TestHotbarConsume(t *testing.T) {
+	inv := New()
+	inv.SetSlot(0, types.ItemStack{ID: "a", Count: 2})
+	h := NewHotbar(inv)
+
+	for i := 0; i < 2; i++ {
+		id, ok := h.Consume()
+		assert.True(t, ok)
+		assert.Equal(t, "a", id)
+	}
+	_, ok := h.Consume()
+	assert.False(t, ok, "the stack is used up")
+	assert.Equal(t, "", h.Item())
+}
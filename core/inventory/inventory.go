@@ -0,0 +1,211 @@
+// Package inventory has the blocks a player carries
+package inventory
+
+import (
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+)
+
+const (
+	// Slots is the number of slots of an inventory, the first
+	// HotbarSlots of them are the hotbar
+	Slots = 36
+	// MaxStack is how many blocks fit in a slot
+	MaxStack = 64
+)
+
+// MaxStackOf returns how many of block id fit in a slot, liquids
+// don't stack
+func MaxStackOf(id string) int {
+	if b := block.GetBlock(id); b != nil && b.Liquid {
+		return 1
+	}
+	return MaxStack
+}
+
+// Inventory is the slots of blocks of a player, and the stack held by
+// the cursor of the inventory screen
+type Inventory struct {
+	mx    sync.Mutex
+	slots [Slots]types.ItemStack
+	held  types.ItemStack
+}
+
+func New() *Inventory {
+	return new(Inventory)
+}
+
+// Stacks returns the stack in each slot
+func (inv *Inventory) Stacks() []types.ItemStack {
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	return append([]types.ItemStack(nil), inv.slots[:]...)
+}
+
+// Slot returns the stack in slot i
+func (inv *Inventory) Slot(i int) types.ItemStack {
+	if i < 0 || i >= Slots {
+		return types.ItemStack{}
+	}
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	return inv.slots[i]
+}
+
+// SetSlot puts s in slot i
+func (inv *Inventory) SetSlot(i int, s types.ItemStack) {
+	if i < 0 || i >= Slots {
+		return
+	}
+	inv.mx.Lock()
+	inv.slots[i] = normalize(s)
+	inv.mx.Unlock()
+}
+
+func (inv *Inventory) Held() types.ItemStack {
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	return inv.held
+}
+
+// Add merges s into the stacks of the same block, hotbar first, and
+// puts the rest in the first empty slots. It returns what didn't fit
+func (inv *Inventory) Add(s types.ItemStack) types.ItemStack {
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	return inv.add(s)
+}
+
+func (inv *Inventory) add(s types.ItemStack) types.ItemStack {
+	for i := range inv.slots {
+		if s.Empty() {
+			break
+		}
+		if inv.slots[i].ID == s.ID {
+			inv.slots[i], s = Merge(inv.slots[i], s)
+		}
+	}
+	for i := range inv.slots {
+		if s.Empty() {
+			break
+		}
+		if inv.slots[i].Empty() {
+			inv.slots[i], s = Merge(types.ItemStack{}, s)
+		}
+	}
+	return normalize(s)
+}
+
+// Take removes up to n blocks from slot i and returns them
+func (inv *Inventory) Take(i, n int) types.ItemStack {
+	if i < 0 || i >= Slots {
+		return types.ItemStack{}
+	}
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	var taken types.ItemStack
+	taken, inv.slots[i] = Split(inv.slots[i], n)
+	return taken
+}
+
+// Click picks up, puts down, merges or swaps the stack of slot i with
+// the held stack. A right click picks up half of the stack or puts
+// down one block
+func (inv *Inventory) Click(i int, right bool) {
+	if i < 0 || i >= Slots {
+		return
+	}
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	slot, held := inv.slots[i], inv.held
+	switch {
+	case held.Empty() && right:
+		held, slot = Split(slot, (slot.Count+1)/2)
+	case held.Empty():
+		held, slot = slot, types.ItemStack{}
+	case right && (slot.Empty() || slot.ID == held.ID):
+		var one types.ItemStack
+		one, held = Split(held, 1)
+		if slot, one = Merge(slot, one); !one.Empty() {
+			// the slot is full
+			held.ID, held.Count = one.ID, held.Count+one.Count
+		}
+	case slot.Empty() || slot.ID == held.ID:
+		slot, held = Merge(slot, held)
+	default:
+		slot, held = held, slot
+	}
+	inv.slots[i], inv.held = normalize(slot), normalize(held)
+}
+
+// ReturnHeld puts the held stack back into the slots, it returns what
+// didn't fit
+func (inv *Inventory) ReturnHeld() types.ItemStack {
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	rest := inv.add(inv.held)
+	inv.held = types.ItemStack{}
+	return rest
+}
+
+// Restore sets the slots saved with the player state
+func (inv *Inventory) Restore(stacks []types.ItemStack) {
+	inv.mx.Lock()
+	defer inv.mx.Unlock()
+	inv.slots = [Slots]types.ItemStack{}
+	inv.held = types.ItemStack{}
+	for i, s := range stacks {
+		if i >= Slots {
+			break
+		}
+		inv.slots[i] = normalize(s)
+	}
+}
+
+// Merge moves as much of from onto to as fits, it returns both stacks
+// after. Stacks of different blocks are left as they are
+func Merge(to, from types.ItemStack) (types.ItemStack, types.ItemStack) {
+	if from.Empty() {
+		return normalize(to), types.ItemStack{}
+	}
+	if to.Empty() {
+		to = types.ItemStack{ID: from.ID}
+	}
+	if to.ID != from.ID {
+		return to, from
+	}
+	n := MaxStackOf(to.ID) - to.Count
+	if n > from.Count {
+		n = from.Count
+	}
+	if n < 0 {
+		n = 0
+	}
+	to.Count += n
+	from.Count -= n
+	return normalize(to), normalize(from)
+}
+
+// Split takes up to n blocks off s, it returns the taken and the
+// remaining stack
+func Split(s types.ItemStack, n int) (types.ItemStack, types.ItemStack) {
+	if s.Empty() || n <= 0 {
+		return types.ItemStack{}, normalize(s)
+	}
+	if n > s.Count {
+		n = s.Count
+	}
+	taken := types.ItemStack{ID: s.ID, Count: n}
+	s.Count -= n
+	return taken, normalize(s)
+}
+
+// normalize makes any empty stack the zero stack
+func normalize(s types.ItemStack) types.ItemStack {
+	if s.Empty() {
+		return types.ItemStack{}
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package inventory
+
+import (
+	"os"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	block.InitRegister()
+	os.Exit(m.Run())
+}
+
+func stack(id string, n int) types.ItemStack {
+	return types.ItemStack{ID: id, Count: n}
+}
+
+func TestMergeAndSplit(t *testing.T) {
+	to, from := Merge(stack("a", 60), stack("a", 10))
+	assert.Equal(t, stack("a", MaxStack), to)
+	assert.Equal(t, stack("a", 6), from)
+
+	to, from = Merge(types.ItemStack{}, stack("a", 3))
+	assert.Equal(t, stack("a", 3), to)
+	assert.True(t, from.Empty())
+
+	to, from = Merge(stack("a", 1), stack("b", 1))
+	assert.Equal(t, stack("a", 1), to, "different blocks don't merge")
+	assert.Equal(t, stack("b", 1), from)
+
+	to, from = Merge(types.ItemStack{}, stack(block.WaterID, 2))
+	assert.Equal(t, stack(block.WaterID, 1), to, "liquids don't stack")
+	assert.Equal(t, stack(block.WaterID, 1), from)
+
+	taken, rest := Split(stack("a", 5), 2)
+	assert.Equal(t, stack("a", 2), taken)
+	assert.Equal(t, stack("a", 3), rest)
+	taken, rest = Split(stack("a", 5), 9)
+	assert.Equal(t, stack("a", 5), taken)
+	assert.Equal(t, types.ItemStack{}, rest, "empty stacks are the zero stack")
+}
+
+func TestAdd(t *testing.T) {
+	inv := New()
+	inv.SetSlot(3, stack("a", 60))
+	inv.SetSlot(0, stack("b", 1))
+
+	rest := inv.Add(stack("a", 10))
+	assert.True(t, rest.Empty())
+	assert.Equal(t, stack("a", MaxStack), inv.Slot(3), "existing stacks are filled first")
+	assert.Equal(t, stack("a", 6), inv.Slot(1), "then the first empty slot")
+
+	for i := 0; i < Slots; i++ {
+		inv.SetSlot(i, stack("c", MaxStack))
+	}
+	assert.Equal(t, stack("a", 1), inv.Add(stack("a", 1)), "a full inventory returns the rest")
+}
+
+func TestTake(t *testing.T) {
+	inv := New()
+	inv.SetSlot(4, stack("a", 3))
+	assert.Equal(t, stack("a", 2), inv.Take(4, 2))
+	assert.Equal(t, stack("a", 1), inv.Take(4, 2))
+	assert.True(t, inv.Take(4, 1).Empty())
+	assert.True(t, inv.Take(Slots, 1).Empty())
+}
+
+func TestClick(t *testing.T) {
+	inv := New()
+	inv.SetSlot(0, stack("a", 10))
+	inv.SetSlot(1, stack("a", 60))
+	inv.SetSlot(2, stack("b", 1))
+
+	inv.Click(0, true)
+	assert.Equal(t, stack("a", 5), inv.Held(), "a right click picks up half")
+	assert.Equal(t, stack("a", 5), inv.Slot(0))
+
+	inv.Click(5, true)
+	assert.Equal(t, stack("a", 1), inv.Slot(5), "a right click puts down one")
+	assert.Equal(t, stack("a", 4), inv.Held())
+
+	inv.Click(1, false)
+	assert.Equal(t, stack("a", MaxStack), inv.Slot(1), "the same blocks merge")
+	assert.True(t, inv.Held().Empty())
+
+	inv.Click(1, true)
+	assert.Equal(t, stack("a", 32), inv.Held())
+	inv.Click(2, false)
+	assert.Equal(t, stack("a", 32), inv.Slot(2), "different blocks swap")
+	assert.Equal(t, stack("b", 1), inv.Held())
+
+	inv.Click(3, false)
+	assert.Equal(t, stack("b", 1), inv.Slot(3))
+	assert.True(t, inv.Held().Empty())
+
+	inv.Click(3, false)
+	assert.Equal(t, stack("b", 1), inv.Held(), "a left click picks up the whole stack")
+	assert.True(t, inv.ReturnHeld().Empty())
+	assert.True(t, inv.Held().Empty())
+	assert.Equal(t, stack("b", 1), inv.Slot(3), "the held stack goes back")
+}
+
+func TestRightClickOnFullStack(t *testing.T) {
+	inv := New()
+	inv.SetSlot(0, stack("a", 1))
+	inv.SetSlot(1, stack("a", MaxStack))
+	inv.Click(0, false)
+	inv.Click(1, true)
+	assert.Equal(t, stack("a", 1), inv.Held(), "nothing is put on a full stack")
+	assert.Equal(t, stack("a", MaxStack), inv.Slot(1))
+}
+
+func TestRestore(t *testing.T) {
+	inv := New()
+	inv.SetSlot(0, stack("a", 1))
+	inv.Restore([]types.ItemStack{{}, stack("b", 2), stack("c", 0)})
+	stacks := inv.Stacks()
+	assert.Len(t, stacks, Slots)
+	assert.True(t, stacks[0].Empty())
+	assert.Equal(t, stack("b", 2), stacks[1])
+	assert.Equal(t, types.ItemStack{}, stacks[2])
+}
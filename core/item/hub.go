@@ -54,7 +54,11 @@ func (h *Hub) AddTexture(w string, l, r, u, d, f, b int) {
 func (h *Hub) Texture(w string) *texture.BlockTexture {
 	t, ok := h.tex[w]
 	if !ok {
-		log.Printf("%d not found", w)
+		// flowing liquids look like their source
+		if b := block.GetBlock(w); b != nil && b.Liquid && b.Fluid != w {
+			return h.Texture(b.Fluid)
+		}
+		log.Printf("%s not found", w)
 		return h.tex[block.AirID]
 	}
 	return t
@@ -86,6 +90,11 @@ var itemDesc = map[string][6]int{
 	//14: {13, 13, 13, 13, 13, 13},
 	"core:leaves": {14, 14, 14, 14, 14, 14}, // leaves
 	"core:cloud": {15, 15, 15, 15, 15, 15},
+	"core:torch": {17, 17, 0, 0, 17, 17},
+	"core:lamp": {18, 18, 18, 18, 18, 18},
+	"core:ice": {19, 19, 19, 19, 19, 19},
+	"core:water": {21, 21, 21, 21, 21, 21},
+	"core:lava": {22, 22, 22, 22, 22, 22},
 	"core:grass": {48, 48, 0, 0, 48, 48},   // grass
 	"core:dandelion": {49, 49, 0, 0, 49, 49},
 	"core:tulip": {50, 50, 0, 0, 50, 50},
@@ -0,0 +1,72 @@
+// Package minimap draws a map of the terrain around the player in a
+// corner of the screen, from the top block of each column
+package minimap
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/item"
+	"github.com/artheus/go-minecraft/core/texture"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+// Colors returns the color of each block seen from above, the average
+// of the opaque pixels of its top face in the texture atlas pix of
+// size rect. Blocks drawn by something else than a texture, like air,
+// and plants, which are too small to be seen on a map, are left out
+func Colors(pix []uint8, rect image.Rectangle) map[string]color.NRGBA {
+	colors := make(map[string]color.NRGBA)
+	block.RangeBlocks(func(b *block.Block) bool {
+		if !mapped(b) {
+			return true
+		}
+		tex, ok := item.Tex.Tex()[b.ID]
+		if !ok && b.Liquid {
+			tex, ok = item.Tex.Tex()[b.Fluid]
+		}
+		if !ok {
+			return true
+		}
+		if c, ok := faceColor(pix, rect, tex.Up); ok {
+			colors[b.ID] = c
+		}
+		return true
+	})
+	return colors
+}
+
+// mapped tells if b shows on the map
+func mapped(b *block.Block) bool {
+	return b != nil && b.Visible && !b.Plant && b.ID != block.AirID && b.ID != block.CloudID
+}
+
+// faceColor returns the average of the opaque pixels of the atlas pix
+// covered by face, false when there are none
+func faceColor(pix []uint8, rect image.Rectangle, face texture.FaceTexture) (color.NRGBA, bool) {
+	u0, v0, u1, v1 := face[0][0], face[0][1], face[0][0], face[0][1]
+	for _, uv := range face {
+		u0, u1 = Min(u0, uv[0]), Max(u1, uv[0])
+		v0, v1 = Min(v0, uv[1]), Max(v1, uv[1])
+	}
+	// the shaders flip v, it goes up from the bottom row of the atlas
+	w, h := rect.Dx(), rect.Dy()
+	x0, y0 := int(u0*float32(w)), int((1-v1)*float32(h))
+	x1, y1 := int(u1*float32(w)+0.5), int((1-v0)*float32(h)+0.5)
+
+	var r, g, b, n int
+	for y := y0; y < y1 && y < h; y++ {
+		for x := x0; x < x1 && x < w; x++ {
+			i := (y*w + x) * 4
+			if pix[i+3] == 0 {
+				continue
+			}
+			r, g, b, n = r+int(pix[i]), g+int(pix[i+1]), b+int(pix[i+2]), n+1
+		}
+	}
+	if n == 0 {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}, true
+}
@@ -0,0 +1,128 @@
+package minimap
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/chunk"
+	"github.com/artheus/go-minecraft/core/item"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	block.InitRegister()
+	_ = item.LoadTextureDesc()
+	os.Exit(m.Run())
+}
+
+// atlas returns an atlas of 16x16 cells of 2x2 pixels, counted from
+// the bottom left like the shaders do. Cell i has the color of i in
+// red and is fully transparent when i is 0
+func atlas() ([]uint8, image.Rectangle) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			i := (31-y)/2*16 + x/2
+			if i != 0 {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(i), A: 255})
+			}
+		}
+	}
+	return img.Pix, img.Rect
+}
+
+func TestColors(t *testing.T) {
+	colors := Colors(atlas())
+	assert.Equal(t, color.NRGBA{R: 32, A: 255}, colors[block.GrassBlockID], "the top of a grass block")
+	assert.Equal(t, color.NRGBA{R: 5, A: 255}, colors[block.StoneID])
+	assert.Equal(t, colors[block.WaterID], colors[block.GetBlock(block.WaterID).Fluid])
+	assert.NotContains(t, colors, block.AirID)
+	assert.NotContains(t, colors, block.CloudID)
+	assert.NotContains(t, colors, block.DandelionID, "plants are left out")
+}
+
+func TestTile(t *testing.T) {
+	stone, grass := block.GetBlock(block.StoneID), block.GetBlock(block.GrassBlockID)
+	colors := map[string]color.NRGBA{
+		block.StoneID:      {R: 100, G: 100, B: 100, A: 255},
+		block.GrassBlockID: {G: 200, A: 255},
+	}
+	c := chunk.NewChunk(Vec3{X: -1, Z: 2})
+	x0, z0 := float32(-ChunkWidth), float32(2*ChunkWidth)
+	for x := float32(0); x < ChunkWidth; x++ {
+		for z := float32(0); z < ChunkWidth; z++ {
+			c.Add(Vec3{X: x0 + x, Y: 0, Z: z0 + z}, stone)
+		}
+	}
+	c.Add(Vec3{X: x0 + 3, Y: 1, Z: z0 + 4}, grass)
+	c.Add(Vec3{X: x0 + 5, Y: 1, Z: z0 + 5}, block.GetBlock(block.DandelionID))
+	c.Del(Vec3{X: x0 + 7, Y: 0, Z: z0 + 7})
+
+	img := Tile(c, colors)
+	assert.Equal(t, image.Rect(0, 0, ChunkWidth, ChunkWidth), img.Rect)
+	assert.Equal(t, colors[block.StoneID], img.NRGBAAt(0, 0))
+	assert.Equal(t, shade(colors[block.GrassBlockID], slopeLight), img.NRGBAAt(3, 4), "the top block, higher than north")
+	assert.Equal(t, shade(colors[block.StoneID], slopeDark), img.NRGBAAt(3, 5), "lower than north")
+	assert.Equal(t, colors[block.StoneID], img.NRGBAAt(5, 5), "blocks without a color are seen through")
+	assert.Equal(t, uint8(0), img.NRGBAAt(7, 7).A, "empty columns are transparent")
+}
+
+func TestShade(t *testing.T) {
+	c := color.NRGBA{R: 100, G: 250, B: 0, A: 255}
+	assert.Equal(t, color.NRGBA{R: 115, G: 255, B: 0, A: 255}, shade(c, slopeLight))
+}
+
+// topDown is the top-down matrix of the chunk renderer
+func topDown(pos mgl32.Vec3, n float32) mgl32.Mat4 {
+	view := mgl32.LookAtV(pos.Add(mgl32.Vec3{0, n, 0}), pos, mgl32.Vec3{0, 0, -1})
+	return mgl32.Ortho(-n, n, -n, n, 0, 2*n).Mul4(view)
+}
+
+func TestMapMatrix(t *testing.T) {
+	pos := mgl32.Vec3{100, 20, -40}
+	top := topDown(pos, 256)
+	assertNear := func(want, got mgl32.Vec2, msg string) {
+		assert.InDelta(t, want.X(), got.X(), 1e-4, msg)
+		assert.InDelta(t, want.Y(), got.Y(), 1e-4, msg)
+	}
+
+	north := mapMatrix(top, 256, 64, 0)
+	assertNear(mgl32.Vec2{0, 0}, project(north, pos), "the player is in the middle")
+	assertNear(mgl32.Vec2{0, 1}, project(north, pos.Add(mgl32.Vec3{0, -10, -64})), "north is up")
+	assertNear(mgl32.Vec2{0.5, 0}, project(north, pos.Add(mgl32.Vec3{32, 0, 0})), "east is right")
+
+	// looking east, east is up on a rotating map
+	angle := heading(mgl32.Vec3{1, 0, 0})
+	assert.InDelta(t, Radian(90), angle, 1e-5)
+	rotating := mapMatrix(top, 256, 64, angle)
+	assertNear(mgl32.Vec2{0, 1}, project(rotating, pos.Add(mgl32.Vec3{64, 0, 0})), "ahead is up")
+	assertNear(mgl32.Vec2{-1, 0}, project(rotating, pos.Add(mgl32.Vec3{0, 0, -64})), "north is left")
+}
+
+func TestClampEdge(t *testing.T) {
+	p, far := clampEdge(mgl32.Vec2{0.5, -0.2}, 0.9)
+	assert.False(t, far)
+	assert.Equal(t, mgl32.Vec2{0.5, -0.2}, p)
+
+	p, far = clampEdge(mgl32.Vec2{-3, 1.5}, 0.9)
+	assert.True(t, far)
+	assert.InDelta(t, -0.9, p.X(), 1e-6)
+	assert.InDelta(t, 0.45, p.Y(), 1e-6, "the direction is kept")
+}
+
+func TestTileRange(t *testing.T) {
+	ids := tileRange(mgl32.Vec3{16, 0, 16}, 32)
+	assert.Contains(t, ids, Vec3{})
+	assert.Contains(t, ids, Vec3{X: 1, Z: 1}, "the corners of a turned map")
+	assert.Contains(t, ids, Vec3{X: -1, Z: 0})
+	assert.NotContains(t, ids, Vec3{X: 2, Z: 2})
+	assert.NotContains(t, ids, Vec3{X: -3, Z: 0})
+	for _, id := range ids {
+		assert.Zero(t, id.Y)
+	}
+}
@@ -0,0 +1,392 @@
+package minimap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/chunk"
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/hud"
+	"github.com/artheus/go-minecraft/core/texture"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/glhf"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// mapSize is the size in gui pixels of the map, mapMargin its gap
+	// to the top right corner of the screen
+	mapSize   = 96
+	mapMargin = 4
+	// markerSize is half the size of the markers of players, markerEdge
+	// is how far out they go, both across the map from -1 to 1. Players
+	// further away are shown on the edge
+	markerSize = 0.05
+	markerEdge = 0.92
+)
+
+var (
+	mapBackground = mgl32.Vec4{0.1, 0.1, 0.1, 1}
+	mapFrame      = mgl32.Vec4{0, 0, 0, 0.8}
+	playerMarker  = mgl32.Vec4{1, 1, 1, 1}
+	remoteMarker  = mgl32.Vec4{1, 0.85, 0.2, 1}
+	// remoteFar is the marker of players beyond the edge of the map
+	remoteFar = mgl32.Vec4{1, 0.85, 0.2, 0.6}
+)
+
+// tile is the map of a chunk, img is set when it was drawn again and
+// has to be copied to the texture
+type tile struct {
+	img *image.NRGBA
+	tex *glhf.Texture
+}
+
+// Renderer draws the map of the chunks around the player in the top
+// right corner of the screen, with markers for the player and the
+// other players. The map of each chunk is drawn once, by UpdateLoop,
+// and again after DirtyChunk
+type Renderer struct {
+	ctx    *ctx.Context
+	shader *glhf.Shader
+	white  *glhf.Texture // of the markers
+	slice  *glhf.VertexSlice
+	colors map[string]color.NRGBA
+
+	mx     sync.Mutex
+	tiles  map[Vec3]*tile
+	dirty  map[Vec3]bool
+	zoom   int // index in zoomLevels, len(zoomLevels) hides the map
+	rotate bool
+
+	sigch chan struct{}
+}
+
+func NewRenderer(ctx *ctx.Context) (*Renderer, error) {
+	img, rect, err := texture.LoadImage(*texture.TexturePath)
+	if err != nil {
+		return nil, err
+	}
+	r := &Renderer{
+		ctx:    ctx,
+		colors: Colors(img, rect),
+		tiles:  make(map[Vec3]*tile),
+		dirty:  make(map[Vec3]bool),
+		zoom:   1,
+		sigch:  make(chan struct{}, 1),
+	}
+	mainthread.Call(func() {
+		r.shader, err = glhf.NewShader(mapVertexFormat, mapUniformFormat, mapVertexSource, mapFragmentSource)
+		if err != nil {
+			return
+		}
+		r.white = glhf.NewTexture(1, 1, false, []uint8{255, 255, 255, 255})
+		r.slice = glhf.MakeVertexSlice(r.shader, 0, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DirtyChunk draws the map of chunk id again
+func (r *Renderer) DirtyChunk(id Vec3) {
+	r.mx.Lock()
+	if _, ok := r.tiles[id]; ok {
+		r.dirty[id] = true
+	}
+	r.mx.Unlock()
+}
+
+// Zoom shows more of the map, after the furthest zoom level it hides
+// the map and then shows it from the closest again
+func (r *Renderer) Zoom() {
+	r.mx.Lock()
+	r.zoom = (r.zoom + 1) % (len(zoomLevels) + 1)
+	r.mx.Unlock()
+}
+
+// Rotate switches between a map with north up and one turning with
+// the player, so ahead is up
+func (r *Renderer) Rotate() {
+	r.mx.Lock()
+	r.rotate = !r.rotate
+	r.mx.Unlock()
+}
+
+// radius returns how many blocks the map shows around the player,
+// false when it is hidden
+func (r *Renderer) radius() (float32, bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.zoom >= len(zoomLevels) {
+		return 0, false
+	}
+	return zoomLevels[r.zoom], true
+}
+
+// UpdateLoop draws the maps of the chunks around the player which are
+// missing or dirty, each time Render asks for it
+func (r *Renderer) UpdateLoop() {
+	for {
+		select {
+		case <-r.ctx.Context().Done():
+			return
+		case <-r.sigch:
+		}
+		r.update()
+	}
+}
+
+// update draws the missing and dirty tiles of the loaded chunks within
+// the map and drops the tiles out of it
+func (r *Renderer) update() {
+	radius, ok := r.radius()
+	if !ok {
+		return
+	}
+	needed := make(map[Vec3]bool)
+	var build []Vec3
+	r.mx.Lock()
+	for _, id := range tileRange(r.ctx.Game().Camera().Pos(), radius) {
+		needed[id] = true
+		if _, ok := r.tiles[id]; !ok || r.dirty[id] {
+			build = append(build, id)
+			// changes while it is drawn make it dirty again
+			delete(r.dirty, id)
+		}
+	}
+	for id := range r.tiles {
+		if !needed[id] {
+			delete(r.tiles, id)
+			delete(r.dirty, id)
+		}
+	}
+	r.mx.Unlock()
+
+	world := r.ctx.Game().World()
+	for _, id := range build {
+		// only chunks the world already loaded are drawn, the map
+		// doesn't load chunks the renderer doesn't draw
+		c := world.BlockChunk(Vec3{X: id.X * ChunkWidth, Z: id.Z * ChunkWidth})
+		if c == nil {
+			continue
+		}
+		img := Tile(c, r.colors)
+		r.mx.Lock()
+		t, ok := r.tiles[id]
+		if !ok {
+			t = new(tile)
+			r.tiles[id] = t
+		}
+		t.img = img
+		r.mx.Unlock()
+	}
+}
+
+// signal asks UpdateLoop to update the tiles
+func (r *Renderer) signal() {
+	select {
+	case r.sigch <- struct{}{}:
+	default:
+	}
+}
+
+// textures copies the tiles drawn again to their textures and returns
+// the textures by chunk, must be called on the main thread
+func (r *Renderer) textures() map[Vec3]*glhf.Texture {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	textures := make(map[Vec3]*glhf.Texture, len(r.tiles))
+	for id, t := range r.tiles {
+		if t.img != nil {
+			if t.tex == nil {
+				t.tex = glhf.NewTexture(ChunkWidth, ChunkWidth, false, t.img.Pix)
+			} else {
+				t.tex.SetPixels(0, 0, ChunkWidth, ChunkWidth, t.img.Pix)
+			}
+			t.img = nil
+		}
+		textures[id] = t.tex
+	}
+	return textures
+}
+
+// Render draws the map, unless it is hidden or the debug overlay,
+// which shows its lines in the same corner, is shown. It must be
+// called before the text is rendered
+func (r *Renderer) Render() {
+	radius, ok := r.radius()
+	game := r.ctx.Game()
+	if !ok || game.DebugOverlay().Visible() {
+		return
+	}
+	r.signal()
+	textures := r.textures()
+
+	text := game.TextRenderer()
+	width, _ := text.Size()
+	area := image.Rect(width-mapSize-mapMargin, mapMargin, width-mapMargin, mapMargin+mapSize)
+	fbWidth, fbHeight := game.Window().GetFramebufferSize()
+	winWidth, _ := game.Window().GetSize()
+	scale := hud.GuiScale(fbWidth, winWidth)
+
+	camera := game.Camera()
+	pos, front := camera.Pos(), camera.Front()
+	var angle float32
+	r.mx.Lock()
+	if r.rotate {
+		angle = heading(front)
+	}
+	r.mx.Unlock()
+	n := float32(settings.Current().RenderRadius * ChunkWidth)
+	mat := mapMatrix(game.ChunkRenderer().Get2dMat(), n, radius, angle)
+
+	// gl puts the origin of the viewport at the bottom left
+	x, y := int32(area.Min.X*scale), int32(fbHeight-area.Max.Y*scale)
+	size := int32(mapSize * scale)
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(x, y, size, size)
+	gl.ClearColor(mapBackground[0], mapBackground[1], mapBackground[2], mapBackground[3])
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	gl.Viewport(x, y, size, size)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.CULL_FACE)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	defer func() {
+		gl.Viewport(0, 0, int32(fbWidth), int32(fbHeight))
+		gl.Disable(gl.SCISSOR_TEST)
+		gl.Disable(gl.BLEND)
+		gl.Enable(gl.CULL_FACE)
+		gl.Enable(gl.DEPTH_TEST)
+	}()
+
+	r.shader.Begin()
+	r.drawTiles(mat, pos.Y(), textures)
+	r.drawMarkers(mat, pos, front)
+	r.shader.End()
+
+	r.labels(area, mat, pos, radius)
+}
+
+// drawTiles draws the textures of the chunks flat at height y
+func (r *Renderer) drawTiles(mat mgl32.Mat4, y float32, textures map[Vec3]*glhf.Texture) {
+	var (
+		data  []float32
+		order []*glhf.Texture
+	)
+	for id, tex := range textures {
+		if tex == nil {
+			continue
+		}
+		// blocks are centered on whole coordinates
+		x0, z0 := id.X*ChunkWidth-0.5, id.Z*ChunkWidth-0.5
+		x1, z1 := x0+ChunkWidth, z0+ChunkWidth
+		data = append(data,
+			x0, y, z0, 0, 0,
+			x0, y, z1, 0, 1,
+			x1, y, z1, 1, 1,
+			x0, y, z0, 0, 0,
+			x1, y, z1, 1, 1,
+			x1, y, z0, 1, 0,
+		)
+		order = append(order, tex)
+	}
+	if len(order) == 0 {
+		return
+	}
+	r.shader.SetUniformAttr(0, mat)
+	r.shader.SetUniformAttr(1, mgl32.Vec4{1, 1, 1, 1})
+	r.slice.Begin()
+	r.slice.SetLen(len(order) * 6)
+	r.slice.SetVertexData(data)
+	for i, tex := range order {
+		tex.Begin()
+		r.slice.Slice(i*6, i*6+6).Draw()
+		tex.End()
+	}
+	r.slice.End()
+}
+
+// drawMarkers draws an arrow where the player is looking and a square
+// for each other player, across the map from -1 to 1
+func (r *Renderer) drawMarkers(mat mgl32.Mat4, pos, front mgl32.Vec3) {
+	center := project(mat, pos)
+	ahead := mgl32.Vec3{front.X(), 0, front.Z()}
+	dir := mgl32.Vec2{0, 1}
+	if ahead.Len() > 0 {
+		if d := project(mat, pos.Add(ahead.Normalize())).Sub(center); d.Len() > 0 {
+			dir = d.Normalize()
+		}
+	}
+	r.drawShape(arrow(center, dir, markerSize), playerMarker)
+
+	for _, p := range r.ctx.Game().PlayerRenderer().Players() {
+		at, far := clampEdge(project(mat, p.Pos), markerEdge)
+		c := remoteMarker
+		if far {
+			c = remoteFar
+		}
+		r.drawShape(square(at, markerSize*0.7), c)
+	}
+}
+
+// drawShape draws the triangles of points in a solid color
+func (r *Renderer) drawShape(points []mgl32.Vec2, c mgl32.Vec4) {
+	data := make([]float32, 0, len(points)*5)
+	for _, p := range points {
+		data = append(data, p.X(), p.Y(), 0, 0.5, 0.5)
+	}
+	r.shader.SetUniformAttr(0, mgl32.Ident4())
+	r.shader.SetUniformAttr(1, c)
+	r.white.Begin()
+	r.slice.Begin()
+	r.slice.SetLen(len(points))
+	r.slice.SetVertexData(data)
+	r.slice.Draw()
+	r.slice.End()
+	r.white.End()
+}
+
+// arrow returns the triangles of an arrow at p pointing to dir
+func arrow(p, dir mgl32.Vec2, size float32) []mgl32.Vec2 {
+	side := mgl32.Vec2{-dir.Y(), dir.X()}.Mul(size)
+	tip := p.Add(dir.Mul(size * 1.5))
+	back := p.Sub(dir.Mul(size * 0.5))
+	left, right := p.Sub(dir.Mul(size)).Add(side), p.Sub(dir.Mul(size)).Sub(side)
+	return []mgl32.Vec2{tip, left, back, tip, back, right}
+}
+
+// square returns the triangles of a square of half size size around p
+func square(p mgl32.Vec2, size float32) []mgl32.Vec2 {
+	x0, y0, x1, y1 := p.X()-size, p.Y()-size, p.X()+size, p.Y()+size
+	return []mgl32.Vec2{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y0}, {x1, y1}, {x0, y1}}
+}
+
+// labels adds the frame of the map, a mark for north and the position
+// of the player below the map to the text renderer
+func (r *Renderer) labels(area image.Rectangle, mat mgl32.Mat4, pos mgl32.Vec3, radius float32) {
+	text := r.ctx.Game().TextRenderer()
+	for _, edge := range []image.Rectangle{
+		image.Rect(area.Min.X-1, area.Min.Y-1, area.Max.X+1, area.Min.Y),
+		image.Rect(area.Min.X-1, area.Max.Y, area.Max.X+1, area.Max.Y+1),
+		image.Rect(area.Min.X-1, area.Min.Y, area.Min.X, area.Max.Y),
+		image.Rect(area.Max.X, area.Min.Y, area.Max.X+1, area.Max.Y),
+	} {
+		text.Box(edge, mapFrame)
+	}
+
+	north, _ := clampEdge(project(mat, pos.Add(mgl32.Vec3{0, 0, -radius})), markerEdge)
+	x := area.Min.X + int((north.X()+1)/2*mapSize)
+	y := area.Min.Y + int((1-north.Y())/2*mapSize)
+	text.Text("N", x-text.Width("N")/2, y-text.LineHeight()/2, true)
+
+	b := chunk.NearBlock(pos)
+	coords := fmt.Sprintf("%.0f %.0f %.0f", b.X, b.Y, b.Z)
+	text.Text(coords, area.Min.X+(mapSize-text.Width(coords))/2, area.Max.Y+2, true)
+}
@@ -0,0 +1,49 @@
+package minimap
+
+import "github.com/faiface/glhf"
+
+var (
+	mapVertexFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "pos", Type: glhf.Vec3},
+		glhf.Attr{Name: "tex", Type: glhf.Vec2},
+	}
+
+	mapUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "color", Type: glhf.Vec4},
+	}
+
+	mapVertexSource = `
+#version 330 core
+
+in vec3 pos;
+in vec2 tex;
+
+uniform mat4 matrix;
+
+out vec2 Tex;
+
+void main() {
+    gl_Position = matrix * vec4(pos, 1.0);
+    Tex = tex;
+}
+`
+
+	mapFragmentSource = `
+#version 330 core
+
+in vec2 Tex;
+uniform sampler2D tex;
+uniform vec4 color;
+
+out vec4 FragColor;
+
+void main() {
+    vec4 c = color * texture(tex, Tex);
+    if (c.a == 0) {
+        discard;
+    }
+    FragColor = c;
+}
+`
+)
@@ -0,0 +1,89 @@
+package minimap
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+const (
+	// slopeLight and slopeDark are the shades of columns higher and
+	// lower than the one north of them
+	slopeLight = 1.15
+	slopeDark  = 0.8
+)
+
+// column is the top block of a column shown on the map, b is nil for
+// empty columns
+type column struct {
+	b *block.Block
+	y float32
+}
+
+// topBlocks returns the highest block with a color of each column of
+// c, by z*ChunkWidth+x within the chunk
+func topBlocks(c types.IChunk, colors map[string]color.NRGBA) []column {
+	cols := make([]column, ChunkWidth*ChunkWidth)
+	origin := c.ID()
+	c.RangeBlocks(func(id Vec3, b *block.Block) {
+		if _, ok := colors[b.ID]; !ok {
+			return
+		}
+		x, z := int(id.X-origin.X*ChunkWidth), int(id.Z-origin.Z*ChunkWidth)
+		i := z*ChunkWidth + x
+		if cols[i].b == nil || id.Y > cols[i].y {
+			cols[i] = column{b: b, y: id.Y}
+		}
+	})
+	return cols
+}
+
+// Tile draws the map of chunk c from above, a pixel per column with
+// north (-z) up. Columns higher than the one north of them are lighter
+// and lower ones darker, so hills stand out. Empty columns are
+// transparent
+func Tile(c types.IChunk, colors map[string]color.NRGBA) *image.NRGBA {
+	const w = ChunkWidth
+	cols := topBlocks(c, colors)
+	img := image.NewNRGBA(image.Rect(0, 0, w, w))
+	for z := 0; z < w; z++ {
+		for x := 0; x < w; x++ {
+			col := cols[z*w+x]
+			if col.b == nil {
+				continue
+			}
+			// the first row has no column north of it in the chunk,
+			// it takes the slope of the next one
+			north, south := z-1, z
+			if z == 0 {
+				north, south = 0, 1
+			}
+			img.SetNRGBA(x, z, shade(colors[col.b.ID], slope(cols[north*w+x], cols[south*w+x])))
+		}
+	}
+	return img
+}
+
+// slope returns the shade of a column going from north to south
+func slope(north, south column) float32 {
+	switch {
+	case north.b == nil || south.b == nil:
+		return 1
+	case south.y > north.y:
+		return slopeLight
+	case south.y < north.y:
+		return slopeDark
+	}
+	return 1
+}
+
+// shade multiplies the color c by f
+func shade(c color.NRGBA, f float32) color.NRGBA {
+	channel := func(v uint8) uint8 {
+		return uint8(Min(float32(v)*f, 255))
+	}
+	return color.NRGBA{R: channel(c.R), G: channel(c.G), B: channel(c.B), A: c.A}
+}
@@ -0,0 +1,65 @@
+package minimap
+
+import (
+	"math"
+
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// zoomLevels are how many blocks the map shows from the player to its
+// edges, closest first
+var zoomLevels = []float32{32, 64, 128}
+
+// heading returns the angle in radians of front from north, clockwise
+// seen from above
+func heading(front mgl32.Vec3) float32 {
+	return float32(math.Atan2(float64(front.X()), float64(-front.Z())))
+}
+
+// mapMatrix returns the matrix of the map showing radius blocks around
+// the player, from top, the top-down matrix of the chunk renderer
+// which shows n blocks around. A rotating map turns by angle so the
+// heading of the player is up
+func mapMatrix(top mgl32.Mat4, n, radius, angle float32) mgl32.Mat4 {
+	s := n / radius
+	return mgl32.Scale3D(s, s, 1).Mul4(mgl32.HomogRotate3DZ(angle)).Mul4(top)
+}
+
+// project returns where p is on the map drawn with mat, from -1 to 1
+// across
+func project(mat mgl32.Mat4, p mgl32.Vec3) mgl32.Vec2 {
+	v := mat.Mul4x1(p.Vec4(1))
+	return mgl32.Vec2{v.X() / v.W(), v.Y() / v.W()}
+}
+
+// clampEdge moves p onto the square from -edge to edge when it is
+// outside, it tells if it was
+func clampEdge(p mgl32.Vec2, edge float32) (mgl32.Vec2, bool) {
+	m := Max(Abs(p.X()), Abs(p.Y()))
+	if m <= edge {
+		return p, false
+	}
+	return p.Mul(edge / m), true
+}
+
+// tileRange returns the chunks with blocks within radius of the block
+// center on x and z, any way the map turns
+func tileRange(center mgl32.Vec3, radius float32) []Vec3 {
+	// the corners of a turned map are further than its edges
+	r := radius * math.Sqrt2
+	min := Vec3{X: center.X() - r, Z: center.Z() - r}.ChunkID()
+	max := Vec3{X: center.X() + r, Z: center.Z() + r}.ChunkID()
+	var ids []Vec3
+	for x := min.X; x <= max.X; x++ {
+		for z := min.Z; z <= max.Z; z++ {
+			// distance from the center to the closest block of the chunk
+			dx := Max(0, Max(x*ChunkWidth-center.X(), center.X()-(x+1)*ChunkWidth))
+			dz := Max(0, Max(z*ChunkWidth-center.Z(), center.Z()-(z+1)*ChunkWidth))
+			if dx*dx+dz*dz <= r*r {
+				ids = append(ids, Vec3{X: x, Z: z})
+			}
+		}
+	}
+	return ids
+}
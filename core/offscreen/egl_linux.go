@@ -0,0 +1,56 @@
+package offscreen
+
+/*
+#cgo LDFLAGS: -lEGL
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+
+// makeContext makes a surfaceless OpenGL 3.3 core context current,
+// it returns the failing step
+static int makeContext() {
+	PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+		(PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+	if (getPlatformDisplay == NULL) {
+		return 1;
+	}
+	EGLDisplay display = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+	if (display == EGL_NO_DISPLAY || !eglInitialize(display, NULL, NULL)) {
+		return 2;
+	}
+	if (!eglBindAPI(EGL_OPENGL_API)) {
+		return 3;
+	}
+	EGLint attribs[] = {
+		EGL_CONTEXT_MAJOR_VERSION, 3,
+		EGL_CONTEXT_MINOR_VERSION, 3,
+		EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT,
+		EGL_NONE,
+	};
+	EGLContext context = eglCreateContext(display, EGL_NO_CONFIG_KHR, EGL_NO_CONTEXT, attribs);
+	if (context == EGL_NO_CONTEXT) {
+		return 4;
+	}
+	if (!eglMakeCurrent(display, EGL_NO_SURFACE, EGL_NO_SURFACE, context)) {
+		return 5;
+	}
+	return 0;
+}
+*/
+import "C"
+
+import "github.com/pkg/errors"
+
+var steps = map[C.int]string{
+	1: "no EGL platform display support",
+	2: "no surfaceless EGL display",
+	3: "no desktop OpenGL in EGL",
+	4: "can't create an OpenGL 3.3 core context",
+	5: "can't make the context current",
+}
+
+func makeContext() error {
+	if step := C.makeContext(); step != 0 {
+		return errors.Errorf("offscreen context: %s (egl error 0x%x)", steps[step], C.eglGetError())
+	}
+	return nil
+}
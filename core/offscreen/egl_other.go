@@ -0,0 +1,9 @@
+//go:build !linux
+
+package offscreen
+
+import "github.com/pkg/errors"
+
+func makeContext() error {
+	return errors.New("offscreen context: only supported on linux")
+}
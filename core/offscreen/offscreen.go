@@ -0,0 +1,28 @@
+// Package offscreen creates an OpenGL context without a window or display,
+// for rendering into framebuffers on machines without a GPU, like the
+// golden image tests running on Mesa llvmpipe
+package offscreen
+
+import (
+	"sync"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+var (
+	once    sync.Once
+	initErr error
+)
+
+// Init makes an OpenGL 3.3 core context current on the calling thread,
+// which has to stay locked to it, and loads the gl functions. Only the
+// first call creates the context, later calls return its error
+func Init() error {
+	once.Do(func() {
+		if initErr = makeContext(); initErr != nil {
+			return
+		}
+		initErr = gl.Init()
+	})
+	return initErr
+}
@@ -0,0 +1,108 @@
+package particle
+
+import (
+	"math/rand"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/item"
+	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Gravity is the acceleration of falling block particles
+const Gravity = 16
+
+// faceRect returns the part of the atlas a face texture covers
+func faceRect(f texture.FaceTexture) mgl32.Vec4 {
+	r := mgl32.Vec4{f[0][0], f[0][1], f[0][0], f[0][1]}
+	for _, uv := range f[1:] {
+		r[0], r[1] = Min(r[0], uv[0]), Min(r[1], uv[1])
+		r[2], r[3] = Max(r[2], uv[0]), Max(r[3], uv[1])
+	}
+	return r
+}
+
+// chip returns a random quarter by quarter piece of rect, so particles
+// of one block show different parts of its texture
+func chip(rect mgl32.Vec4, rnd *rand.Rand) mgl32.Vec4 {
+	w, h := (rect[2]-rect[0])/4, (rect[3]-rect[1])/4
+	u, v := rect[0]+float32(rnd.Intn(4))*w, rect[1]+float32(rnd.Intn(4))*h
+	return mgl32.Vec4{u, v, u + w, v + h}
+}
+
+// Break returns the particles of block b at id falling apart, a grid
+// of pieces of its side texture flying outwards from its center
+func Break(id Vec3, b *block.Block, rnd *rand.Rand) []types.Particle {
+	const n = 4
+	rect := faceRect(item.Tex.Texture(b.ID).Front)
+	center := mgl32.Vec3{id.X, id.Y, id.Z}
+	ps := make([]types.Particle, 0, n*n*n)
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			for z := 0; z < n; z++ {
+				offset := mgl32.Vec3{
+					(float32(x)+0.5)/n - 0.5,
+					(float32(y)+0.5)/n - 0.5,
+					(float32(z)+0.5)/n - 0.5,
+				}
+				vel := offset.Mul(4).Add(mgl32.Vec3{
+					rnd.Float32() - 0.5, rnd.Float32() * 2, rnd.Float32() - 0.5,
+				})
+				ps = append(ps, types.Particle{
+					Pos:     center.Add(offset),
+					Vel:     vel,
+					Gravity: Gravity,
+					Life:    0.6 + rnd.Float32()*0.8,
+					Size:    0.1 + rnd.Float32()*0.1,
+					Tex:     chip(rect, rnd),
+				})
+			}
+		}
+	}
+	return ps
+}
+
+// Place returns the puff of particles around the bottom edges of
+// block b just placed at id
+func Place(id Vec3, b *block.Block, rnd *rand.Rand) []types.Particle {
+	const n = 12
+	rect := faceRect(item.Tex.Texture(b.ID).Front)
+	ps := make([]types.Particle, 0, n)
+	for i := 0; i < n; i++ {
+		// a point on the edge of the bottom face, pushed outwards
+		a := float32(i) / n * 4
+		side, along := int(a), a-float32(int(a))-0.5
+		dir := [4]mgl32.Vec3{{1, 0, 0}, {0, 0, 1}, {-1, 0, 0}, {0, 0, -1}}[side]
+		edge := dir.Mul(0.55).Add(mgl32.Vec3{-dir.Z(), 0, dir.X()}.Mul(along))
+		ps = append(ps, types.Particle{
+			Pos:     mgl32.Vec3{id.X, id.Y - 0.45, id.Z}.Add(edge),
+			Vel:     dir.Mul(0.8 + rnd.Float32()).Add(mgl32.Vec3{0, 1 + rnd.Float32(), 0}),
+			Gravity: Gravity,
+			Life:    0.3 + rnd.Float32()*0.3,
+			Size:    0.08 + rnd.Float32()*0.06,
+			Tex:     chip(rect, rnd),
+		})
+	}
+	return ps
+}
+
+// Footstep returns the few particles kicked up from the top of
+// block b by a step at pos
+func Footstep(pos mgl32.Vec3, b *block.Block, rnd *rand.Rand) []types.Particle {
+	const n = 3
+	rect := faceRect(item.Tex.Texture(b.ID).Up)
+	ps := make([]types.Particle, 0, n)
+	for i := 0; i < n; i++ {
+		ps = append(ps, types.Particle{
+			Pos:     pos.Add(mgl32.Vec3{rnd.Float32()*0.6 - 0.3, 0.05, rnd.Float32()*0.6 - 0.3}),
+			Vel:     mgl32.Vec3{rnd.Float32() - 0.5, 1.5 + rnd.Float32(), rnd.Float32() - 0.5},
+			Gravity: Gravity,
+			Life:    0.3 + rnd.Float32()*0.2,
+			Size:    0.06 + rnd.Float32()*0.04,
+			Tex:     chip(rect, rnd),
+		})
+	}
+	return ps
+}
@@ -0,0 +1,170 @@
+package particle
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/item"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	block.InitRegister()
+	_ = item.LoadTextureDesc()
+	os.Exit(m.Run())
+}
+
+// testBlocks is a world of the blocks in the map, and air
+type testBlocks map[Vec3]*block.Block
+
+func (w testBlocks) Block(id Vec3) *block.Block {
+	if b, ok := w[id]; ok {
+		return b
+	}
+	return block.GetBlock(block.AirID)
+}
+
+// floor is a stone floor at y=0 around the origin
+func floor() testBlocks {
+	w := testBlocks{}
+	for x := -4; x <= 4; x++ {
+		for z := -4; z <= 4; z++ {
+			w[Vec3{X: float32(x), Z: float32(z)}] = block.GetBlock(block.StoneID)
+		}
+	}
+	return w
+}
+
+func simulate(s *System, w Blocks, seconds float32) {
+	for t := float32(0); t < seconds; t += 1.0 / 60 {
+		s.Update(1.0/60, w)
+	}
+}
+
+func TestParticlesFallAndLand(t *testing.T) {
+	s := NewSystem(10)
+	s.Spawn(types.Particle{Pos: mgl32.Vec3{0.2, 3, 0.2}, Gravity: Gravity, Life: 10})
+
+	simulate(s, floor(), 2)
+
+	require.Equal(t, 1, s.Len())
+	s.Range(func(p *types.Particle) {
+		assert.Equal(t, float32(1), Round(p.Pos.Y()), "lies on the floor")
+		assert.Equal(t, float32(0), p.Vel.Y())
+		assert.Equal(t, mgl32.Vec3{0.2, 0, 0.2}, mgl32.Vec3{p.Pos.X(), 0, p.Pos.Z()})
+	})
+}
+
+func TestParticlesStopAtWalls(t *testing.T) {
+	w := floor()
+	w[Vec3{X: 2, Y: 1}] = block.GetBlock(block.StoneID)
+	s := NewSystem(10)
+	s.Spawn(types.Particle{Pos: mgl32.Vec3{0, 1, 0}, Vel: mgl32.Vec3{5, 0, 0}, Gravity: Gravity, Life: 10})
+
+	simulate(s, w, 1)
+
+	s.Range(func(p *types.Particle) {
+		assert.Equal(t, float32(1), Round(p.Pos.X()), "stops before the wall")
+		assert.Equal(t, float32(0), p.Vel.X())
+	})
+}
+
+func TestParticlesSlowDownOnTheGround(t *testing.T) {
+	s := NewSystem(10)
+	s.Spawn(types.Particle{Pos: mgl32.Vec3{0, 0.6, 0}, Vel: mgl32.Vec3{0, 0, 1}, Gravity: Gravity, Life: 10})
+
+	simulate(s, floor(), 1)
+
+	s.Range(func(p *types.Particle) {
+		assert.Less(t, p.Vel.Z(), float32(0.1))
+		assert.Less(t, p.Pos.Z(), float32(1))
+	})
+}
+
+func TestParticlesDieOfAge(t *testing.T) {
+	s := NewSystem(10)
+	s.Spawn(
+		types.Particle{Pos: mgl32.Vec3{0, 1, 0}, Life: 0.5},
+		types.Particle{Pos: mgl32.Vec3{0, 1, 0}, Life: 1.5},
+	)
+
+	simulate(s, floor(), 1)
+	assert.Equal(t, 1, s.Len())
+	simulate(s, floor(), 1)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSpawnIsCapped(t *testing.T) {
+	s := NewSystem(3)
+	s.Spawn(make([]types.Particle, 5)...)
+	assert.Equal(t, 3, s.Len())
+}
+
+// inside tells if rect a is within rect b
+func inside(a, b mgl32.Vec4) bool {
+	const e = 1e-6
+	return a[0] >= b[0]-e && a[1] >= b[1]-e && a[2] <= b[2]+e && a[3] <= b[3]+e
+}
+
+func TestBreakShowsPiecesOfTheBlock(t *testing.T) {
+	stone := block.GetBlock(block.StoneID)
+	id := Vec3{X: 3, Y: 5, Z: -2}
+	ps := Break(id, stone, rand.New(rand.NewSource(1)))
+
+	rect := faceRect(item.Tex.Texture(block.StoneID).Front)
+	require.Len(t, ps, 64)
+	for _, p := range ps {
+		assert.True(t, inside(p.Tex, rect), "texture %v outside %v", p.Tex, rect)
+		assert.InDelta(t, (rect[2]-rect[0])/4, p.Tex[2]-p.Tex[0], 1e-6)
+		assert.Equal(t, id, cell(p.Pos), "starts inside the block")
+		assert.Greater(t, p.Life, float32(0))
+	}
+}
+
+func TestPlaceAndFootstepParticles(t *testing.T) {
+	grass := block.GetBlock(block.GrassBlockID)
+	rnd := rand.New(rand.NewSource(1))
+	id := Vec3{Y: 1}
+
+	for _, p := range Place(id, grass, rnd) {
+		assert.NotEqual(t, id, cell(p.Pos), "starts outside the placed block")
+		assert.Greater(t, p.Vel.Y(), float32(0))
+	}
+
+	top := faceRect(item.Tex.Texture(block.GrassBlockID).Up)
+	side := faceRect(item.Tex.Texture(block.GrassBlockID).Front)
+	require.NotEqual(t, top, side)
+	for _, p := range Footstep(mgl32.Vec3{0, 0.5, 0}, grass, rnd) {
+		assert.True(t, inside(p.Tex, top), "footsteps show the top of the block")
+		assert.Equal(t, float32(1), Round(p.Pos.Y()))
+	}
+}
+
+type testLight float32
+
+func (l testLight) Light(Vec3) (sky, torch float32) {
+	return 1, float32(l)
+}
+
+func TestInstanceData(t *testing.T) {
+	r := &ParticleRenderer{system: NewSystem(10)}
+	r.Spawn(
+		types.Particle{Pos: mgl32.Vec3{1, 2, 3}, Size: 0.2, Tex: mgl32.Vec4{0.1, 0.2, 0.3, 0.4}, Life: 1},
+		types.Particle{Life: 1},
+	)
+
+	n := r.fill(testLight(0.8), 0.5)
+	assert.Equal(t, 2, n)
+	assert.Len(t, r.data, 2*instanceFormat.Size()/4)
+	assert.Equal(t, []float32{1, 2, 3, 0.2, 0.1, 0.2, 0.3, 0.4, 0.8}, r.data[:9])
+
+	// daylight is brighter than the torch
+	r.fill(testLight(0.2), 1)
+	assert.Equal(t, float32(1), r.data[8])
+}
@@ -0,0 +1,189 @@
+package particle
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/game/world"
+	"github.com/artheus/go-minecraft/core/player"
+	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/glhf"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// MaxParticles is how many particles can live at once
+const MaxParticles = 4096
+
+// ParticleRenderer simulates the particles every frame and draws them
+type ParticleRenderer struct {
+	ctx     *ctx.Context
+	system  *System
+	shader  *glhf.Shader
+	texture *glhf.Texture
+
+	vao, corners, instances uint32
+	data                    []float32
+
+	rnd  *rand.Rand // only used by EventLoop
+	last time.Time
+}
+
+func NewParticleRenderer(ctx *ctx.Context) (*ParticleRenderer, error) {
+	img, rect, err := texture.LoadImage(*texture.TexturePath)
+	if err != nil {
+		return nil, err
+	}
+	r := &ParticleRenderer{
+		ctx:    ctx,
+		system: NewSystem(MaxParticles),
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	mainthread.Call(func() {
+		r.shader, err = glhf.NewShader(cornerFormat, particleUniformFormat, particleVertexSource, particleFragmentSource)
+		if err != nil {
+			return
+		}
+		r.texture = glhf.NewTexture(rect.Dx(), rect.Dy(), false, img)
+		r.makeBuffers()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// makeBuffers sets up a quad shared by all particles and a buffer of
+// particles, each drawn as one instance of the quad
+func (r *ParticleRenderer) makeBuffers() {
+	quad := []float32{
+		-1, -1, 1, -1, 1, 1,
+		1, 1, -1, 1, -1, -1,
+	}
+	gl.GenVertexArrays(1, &r.vao)
+	gl.GenBuffers(1, &r.corners)
+	gl.GenBuffers(1, &r.instances)
+	gl.BindVertexArray(r.vao)
+	defer gl.BindVertexArray(0)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.corners)
+	gl.BufferData(gl.ARRAY_BUFFER, len(quad)*4, gl.Ptr(quad), gl.STATIC_DRAW)
+	r.attribPointers(cornerFormat, 0)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instances)
+	gl.BufferData(gl.ARRAY_BUFFER, MaxParticles*instanceFormat.Size(), nil, gl.STREAM_DRAW)
+	r.attribPointers(instanceFormat, 1)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// attribPointers points the attributes of format to the bound buffer,
+// advancing once per divisor instances, or per vertex with 0
+func (r *ParticleRenderer) attribPointers(format glhf.AttrFormat, divisor uint32) {
+	offset := 0
+	for _, attr := range format {
+		loc := uint32(gl.GetAttribLocation(r.shader.ID(), gl.Str(attr.Name+"\x00")))
+		gl.VertexAttribPointer(loc, int32(attr.Type.Size()/4), gl.FLOAT, false,
+			int32(format.Size()), gl.PtrOffset(offset))
+		gl.EnableVertexAttribArray(loc)
+		gl.VertexAttribDivisor(loc, divisor)
+		offset += attr.Type.Size()
+	}
+}
+
+// Spawn adds particles, it is safe to call from any goroutine
+func (r *ParticleRenderer) Spawn(ps ...types.Particle) {
+	r.system.Spawn(ps...)
+}
+
+// EventLoop spawns the particles of blocks broken and placed,
+// and of the player's footsteps
+func (r *ParticleRenderer) EventLoop() {
+	subscriber := r.ctx.EventPipe().Subscriber()
+	for {
+		select {
+		case <-r.ctx.Context().Done():
+			return
+		case evt := <-subscriber.Get():
+			switch e := evt.Object().(type) {
+			case *world.EventBlockBroken:
+				r.Spawn(Break(e.ID, e.Block, r.rnd)...)
+			case *world.EventBlockPlaced:
+				r.Spawn(Place(e.ID, e.Block, r.rnd)...)
+			case *player.EventStep:
+				r.Spawn(Footstep(e.Pos, e.Block, r.rnd)...)
+			}
+		}
+	}
+}
+
+// Render moves the particles by the time since the last frame and
+// draws them, it must be called on the main thread
+func (r *ParticleRenderer) Render() {
+	now := time.Now()
+	dt := float32(now.Sub(r.last).Seconds())
+	r.last = now
+	// the first frame and frames after a stall don't move particles far
+	if dt > 0.1 {
+		dt = 0.1
+	}
+
+	game := r.ctx.Game()
+	r.system.Update(dt, game.World())
+	n := r.fill(game.World(), game.Clock().Daylight())
+	if n == 0 {
+		return
+	}
+
+	camera := game.Camera()
+	right := camera.Front().Cross(mgl32.Vec3{0, 1, 0}).Normalize()
+	up := right.Cross(camera.Front()).Normalize()
+
+	r.shader.Begin()
+	r.texture.Begin()
+	r.shader.SetUniformAttr(0, game.ChunkRenderer().Get3dMat())
+	r.shader.SetUniformAttr(1, camera.Pos())
+	r.shader.SetUniformAttr(2, right)
+	r.shader.SetUniformAttr(3, up)
+	r.shader.SetUniformAttr(4, settings.Current().Fog()*ChunkWidth)
+	r.shader.SetUniformAttr(5, game.Clock().FogColor())
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instances)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(r.data)*4, gl.Ptr(r.data))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	gl.Disable(gl.CULL_FACE)
+	gl.BindVertexArray(r.vao)
+	gl.DrawArraysInstanced(gl.TRIANGLES, 0, 6, int32(n))
+	gl.BindVertexArray(0)
+	gl.Enable(gl.CULL_FACE)
+
+	r.texture.End()
+	r.shader.End()
+}
+
+// lighter is the part of the world particles take their light from
+type lighter interface {
+	Light(id Vec3) (sky, torch float32)
+}
+
+// fill writes the instance data of the living particles to r.data,
+// lit like the block they are in, and returns how many there are
+func (r *ParticleRenderer) fill(w lighter, daylight float32) int {
+	r.data = r.data[:0]
+	n := 0
+	r.system.Range(func(p *types.Particle) {
+		sky, torch := w.Light(cell(p.Pos))
+		light := Max(Mix(0.3, 1, sky)*daylight, torch)
+		r.data = append(r.data,
+			p.Pos.X(), p.Pos.Y(), p.Pos.Z(), p.Size,
+			p.Tex[0], p.Tex[1], p.Tex[2], p.Tex[3],
+			light)
+		n++
+	})
+	return n
+}
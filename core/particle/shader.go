@@ -0,0 +1,78 @@
+package particle
+
+import "github.com/faiface/glhf"
+
+var (
+	// cornerFormat is the vertex of the quad shared by all particles
+	cornerFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "corner", Type: glhf.Vec2},
+	}
+
+	// instanceFormat is what each particle passes to the shader
+	instanceFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "center", Type: glhf.Vec3},
+		glhf.Attr{Name: "size", Type: glhf.Float},
+		glhf.Attr{Name: "rect", Type: glhf.Vec4},
+		glhf.Attr{Name: "light", Type: glhf.Float},
+	}
+
+	particleUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "camera", Type: glhf.Vec3},
+		glhf.Attr{Name: "right", Type: glhf.Vec3},
+		glhf.Attr{Name: "up", Type: glhf.Vec3},
+		glhf.Attr{Name: "fogdis", Type: glhf.Float},
+		glhf.Attr{Name: "fogcolor", Type: glhf.Vec3},
+	}
+
+	particleVertexSource = `
+#version 330 core
+
+in vec2 corner;
+in vec3 center;
+in float size;
+in vec4 rect;
+in float light;
+
+uniform mat4 matrix;
+uniform vec3 camera;
+uniform vec3 right;
+uniform vec3 up;
+uniform float fogdis;
+
+out vec2 Tex;
+out float shade;
+out float fog_factor;
+
+void main() {
+    vec3 pos = center + (right * corner.x + up * corner.y) * size * 0.5;
+    gl_Position = matrix * vec4(pos, 1.0);
+    Tex = mix(rect.xy, rect.zw, corner * 0.5 + 0.5);
+    shade = light;
+    float camera_distance = distance(pos, camera)/2;
+    fog_factor = pow(clamp(camera_distance/fogdis, 0, 1), 4);
+}
+`
+
+	particleFragmentSource = `
+#version 330 core
+
+in vec2 Tex;
+in float shade;
+in float fog_factor;
+
+uniform sampler2D tex;
+uniform vec3 fogcolor;
+
+out vec4 FragColor;
+
+void main() {
+    vec4 texel = texture(tex, vec2(Tex.x, 1-Tex.y));
+    if (texel.a < 0.5) {
+        discard;
+    }
+    vec3 color = mix(texel.rgb * shade, fogcolor, fog_factor/2);
+    FragColor = vec4(color, 1.0);
+}
+`
+)
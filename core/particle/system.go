@@ -0,0 +1,116 @@
+// Package particle simulates particles on the CPU and draws them with
+// one instanced draw call
+package particle
+
+import (
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// groundFriction is the part of the horizontal speed kept per
+// second by a particle lying on a block
+const groundFriction = 0.02
+
+// Blocks is the part of the world particles collide with
+type Blocks interface {
+	Block(id Vec3) *block.Block
+}
+
+// System holds the living particles, it is safe for concurrent use
+type System struct {
+	mx        sync.Mutex
+	particles []types.Particle
+	max       int
+}
+
+// NewSystem makes a system of at most max particles, more are dropped
+func NewSystem(max int) *System {
+	return &System{
+		particles: make([]types.Particle, 0, max),
+		max:       max,
+	}
+}
+
+// Spawn adds particles, as many as there is room for
+func (s *System) Spawn(ps ...types.Particle) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for _, p := range ps {
+		if len(s.particles) >= s.max {
+			return
+		}
+		s.particles = append(s.particles, p)
+	}
+}
+
+// Len returns the number of living particles
+func (s *System) Len() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.particles)
+}
+
+// Update ages the particles by dt seconds and moves them, removing the
+// ones whose life is over
+func (s *System) Update(dt float32, blocks Blocks) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	alive := s.particles[:0]
+	for _, p := range s.particles {
+		p.Age += dt
+		if p.Age >= p.Life {
+			continue
+		}
+		move(&p, dt, blocks)
+		alive = append(alive, p)
+	}
+	s.particles = alive
+}
+
+// Range calls f with every living particle, f must not spawn particles
+func (s *System) Range(f func(p *types.Particle)) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for i := range s.particles {
+		f(&s.particles[i])
+	}
+}
+
+// move applies gravity to p and moves it one axis at a time, stopping
+// on the axes that would take it into an obstacle
+func move(p *types.Particle, dt float32, blocks Blocks) {
+	p.Vel[1] -= p.Gravity * dt
+	grounded := false
+	for axis := 0; axis < 3; axis++ {
+		next := p.Pos
+		next[axis] += p.Vel[axis] * dt
+		if cell(next) != cell(p.Pos) && obstacle(blocks, cell(next)) {
+			if axis == 1 && p.Vel[1] < 0 {
+				grounded = true
+			}
+			p.Vel[axis] = 0
+			continue
+		}
+		p.Pos = next
+	}
+	if grounded {
+		keep := Pow(groundFriction, dt)
+		p.Vel[0] *= keep
+		p.Vel[2] *= keep
+	}
+}
+
+// cell returns the block p is in, blocks are centered on whole coordinates
+func cell(p mgl32.Vec3) Vec3 {
+	return Vec3{X: Round(p.X()), Y: Round(p.Y()), Z: Round(p.Z())}
+}
+
+func obstacle(blocks Blocks, id Vec3) bool {
+	b := blocks.Block(id)
+	return b != nil && b.Obstacle
+}
@@ -1,12 +1,16 @@
 package player
 
 import (
+	"github.com/artheus/go-events"
+	evttypes "github.com/artheus/go-events/types"
+	"github.com/artheus/go-minecraft/core/block"
 	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/clock"
+	"github.com/artheus/go-minecraft/core/game/settings"
 	. "github.com/artheus/go-minecraft/core/types"
 	. "github.com/artheus/go-minecraft/math/f32"
-	"github.com/faiface/mainthread"
-	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"time"
 )
 
 type CameraMovement int
@@ -17,67 +21,96 @@ const (
 	MoveLeft
 	MoveRight
 	MoveJump
+	MoveSneak
+	MoveSprint
+	numMovements
 )
 
 type Camera struct {
-	ctx    *ctx.Context
+	ctx          *ctx.Context
+	evtPublisher evttypes.Publisher
 
 	pos    mgl32.Vec3
+	prev   mgl32.Vec3 // the position on the tick before the last
+	alpha  float32    // how far the frame is from prev to pos
 	up     mgl32.Vec3
 	right  mgl32.Vec3
 	front  mgl32.Vec3
 	wfront mgl32.Vec3
 
-	prevtime         float64
+	moves            [numMovements]bool // asked for until the next tick
+	walked           float32            // since the last footstep
 	velocityY        float32
+	onGround         bool // the last tick ended on the ground
 	rotateX, rotateY float32
 
-	Sens float32
+	sneaking, sprinting bool
+	crouch              smoothed // how far the camera is lowered by sneaking
+	widen               smoothed // how much wider the view is by sprinting
 
 	flying bool
+	name   string
 }
 
 func NewCamera(ctx *ctx.Context, pos mgl32.Vec3) *Camera {
 	c := &Camera{
-		ctx:     ctx,
-		pos:     pos,
-		front:   mgl32.Vec3{0, 0, -1},
-		rotateY: 0,
-		rotateX: -90,
-		Sens:    0.14,
-		flying:  false,
+		ctx:          ctx,
+		pos:          pos,
+		prev:         pos,
+		evtPublisher: ctx.EventPipe().Publisher(),
+		front:        mgl32.Vec3{0, 0, -1},
+		rotateY:      0,
+		rotateX:      -90,
+		flying:       false,
 	}
 	c.updateAngles()
 	return c
 }
 
 func (c *Camera) Restore(state PlayerState) {
-	c.pos = mgl32.Vec3{state.X, state.Y, state.Z}
+	c.SetPos(mgl32.Vec3{state.X, state.Y, state.Z})
 	c.rotateX = state.Rx
 	c.rotateY = state.Ry
+	c.name = state.Name
 	c.updateAngles()
 }
 
 func (c *Camera) State() PlayerState {
 	return PlayerState{
-		X:  c.pos.X(),
-		Y:  c.pos.Y(),
-		Z:  c.pos.Z(),
-		Rx: c.rotateX,
-		Ry: c.rotateY,
+		X:    c.pos.X(),
+		Y:    c.pos.Y(),
+		Z:    c.pos.Z(),
+		Rx:   c.rotateX,
+		Ry:   c.rotateY,
+		Name: c.name,
+
+		Sneaking:  c.sneaking,
+		Sprinting: c.sprinting,
 	}
 }
 
 func (c *Camera) Matrix() mgl32.Mat4 {
-	return mgl32.LookAtV(c.pos, c.pos.Add(c.front), c.up)
+	pos := c.Pos()
+	return mgl32.LookAtV(pos, pos.Add(c.front), c.up)
 }
 
+// SetPos moves the player to pos at once, with nothing to interpolate
 func (c *Camera) SetPos(pos mgl32.Vec3) {
 	c.pos = pos
+	c.prev = pos
 }
 
+// Pos returns where the camera is seen from in the current frame,
+// between the positions of the player on the last two ticks
 func (c *Camera) Pos() mgl32.Vec3 {
-	return c.pos
+	pos := c.prev.Add(c.pos.Sub(c.prev).Mul(c.alpha))
+	return pos.Sub(mgl32.Vec3{0, c.crouch.at(c.alpha), 0})
+}
+
+// FOV returns the vertical field of view in degrees of the current
+// frame, wider while sprinting
+func (c *Camera) FOV() float32 {
+	return settings.Current().FOV * (1 + c.widen.at(c.alpha))
 }
 
 func (c *Camera) Front() mgl32.Vec3 {
@@ -96,8 +129,9 @@ func (c *Camera) OnAngleChange(dx, dy float32) {
 	if mgl32.Abs(dx) > 200 || mgl32.Abs(dy) > 200 {
 		return
 	}
-	c.rotateX += dx * c.Sens
-	c.rotateY += dy * c.Sens
+	sens := settings.Current().Sensitivity
+	c.rotateX += dx * sens
+	c.rotateY += dy * sens
 	if c.rotateY > 89 {
 		c.rotateY = 89
 	}
@@ -107,104 +141,187 @@ func (c *Camera) OnAngleChange(dx, dy float32) {
 	c.updateAngles()
 }
 
-func (c *Camera) MovementEventLoop() {
-	running := true
-	subscriber := c.ctx.EventPipe().Subscriber()
+// Player physics in blocks and seconds, walking at walkSpeed
+const (
+	flySpeed      = 60
+	jumpSpeed     = 8
+	gravity       = 20
+	terminalSpeed = 50
+)
+
+// Sneaking and sprinting, speeds are times walkSpeed
+const (
+	sneakSpeed  = 0.3
+	sneakDrop   = 0.35 // how far the camera is lowered
+	sprintSpeed = 1.3
+	sprintWiden = 0.15 // how much wider the view is
+	// easeTicks is how many ticks the camera takes to lower and the
+	// view to widen
+	easeTicks = 3
+)
 
-	go c.GravityLoop()
+// The player is a box playerWidth wide and playerHeight high, with the
+// camera eyeHeight above its feet
+const (
+	playerWidth  = 0.6
+	playerHeight = 1.8
+	// stepHeight is how high the player walks up without jumping
+	stepHeight = 0.6
+)
 
-	for running {
-		select {
-		case <-c.ctx.Context().Done():
-			running = false
-			break
-		case evt := <-subscriber.Get():
-			if move, ok := evt.Object().(*EventMove); ok {
-				c.OnMoveChange(move.Move, move.Delta)
-			}
-		}
-	}
+// Move asks for the player to move in dir on the next tick, held keys
+// ask again every frame
+func (c *Camera) Move(dir CameraMovement) {
+	c.moves[dir] = true
 }
 
-func (c *Camera) GravityLoop() {
-	var running = true
-
-	for running {
-		select {
-		case <- c.ctx.Context().Done():
-			running = false
-			break
-		default:
-			if c.flying {
-				continue
-			}
-
-			var dt float64
-
-			mainthread.Call(func() {
-				now := glfw.GetTime()
-				dt = now - c.prevtime
-				c.prevtime = now
-				if dt > 0.02 {
-					dt = 0.02
-				}
-			})
-
-			c.velocityY -= float32(dt * 20)
-			if c.velocityY < -50 {
-				c.velocityY = -50
-			}
-
-			y := c.pos.Y()
-			ny := Round(c.pos.Y())
-			const pad = 0.25
-
-			head := Vec3{
-				X: Round(c.pos.X()),
-				Y: ny,
-				Z: Round(c.pos.Z()),
-			}
-			feet := head.Down()
-
-			if c.ctx.Game().World().Block(feet.Down()).Obstacle && y < ny && ny-y > pad && c.velocityY < 0 {
-				c.velocityY = 0 //c.pos.Y() - ny - pad
-			}
-
-			c.pos = c.pos.Add(mgl32.Vec3{0, c.velocityY*float32(dt), 0})
-		}
+// Tick runs the player physics for one game tick, with the movements
+// asked for since the last one
+func (c *Camera) Tick() {
+	c.prev = c.pos
+	moves := c.moves
+	c.moves = [numMovements]bool{}
+
+	w := c.ctx.Game().World()
+	dt := float32(clock.Step.Seconds())
+
+	c.sneaking = moves[MoveSneak] && !c.flying
+	// sprinting lasts while walking forward
+	switch {
+	case !moves[MoveForward] || c.sneaking || c.flying:
+		c.sprinting = false
+	case moves[MoveSprint]:
+		c.sprinting = true
 	}
-}
 
-func (c *Camera) OnMoveChange(dir CameraMovement, delta float32) {
-	if c.flying {
-		delta = 5 * delta
+	front, speed := c.wfront, float32(walkSpeed)
+	switch {
+	case c.flying:
+		front, speed = c.front, flySpeed
+	case c.sneaking:
+		speed *= sneakSpeed
+	case c.sprinting:
+		speed *= sprintSpeed
+	}
+	var dir mgl32.Vec3
+	if moves[MoveForward] {
+		dir = dir.Add(front)
+	}
+	if moves[MoveBackward] {
+		dir = dir.Sub(front)
+	}
+	if moves[MoveLeft] {
+		dir = dir.Sub(c.right)
+	}
+	if moves[MoveRight] {
+		dir = dir.Add(c.right)
 	}
+	d := dir.Mul(speed * dt)
 
-	switch dir {
-	case MoveForward:
-		if c.flying {
-			c.pos = c.pos.Add(c.front.Mul(delta))
-		} else {
-			c.pos = c.pos.Add(c.wfront.Mul(delta))
+	step := float32(0)
+	if !c.flying {
+		// liquids are swum up through
+		if moves[MoveJump] && (c.onGround || w.Block(c.feet()).Liquid) {
+			c.velocityY = jumpSpeed
 		}
-	case MoveBackward:
-		if c.flying {
-			c.pos = c.pos.Sub(c.front.Mul(delta))
-		} else {
-			c.pos = c.pos.Sub(c.wfront.Mul(delta))
+		c.velocityY = Max(c.velocityY-gravity*dt, -terminalSpeed)
+		// nothing stops the fall until the chunk is loaded
+		if w.BlockChunk(c.feet()) == nil {
+			c.velocityY = 0
 		}
-	case MoveLeft:
-		c.pos = c.pos.Sub(c.right.Mul(delta))
-	case MoveRight:
-		c.pos = c.pos.Add(c.right.Mul(delta))
-	case MoveJump:
-		c.velocityY = delta
+		d[1] = c.velocityY * dt
+		step = stepHeight
+	}
+	// sneaking keeps the player from walking off edges
+	if c.sneaking && c.onGround {
+		d = w.Ledge(c.Box(), d, stepHeight)
 	}
 
-	pos := c.Pos()
+	moved, hit := w.Collide(c.Box(), d, step)
+	c.pos = c.pos.Add(moved)
+	c.onGround = hit.Ground
+	if (hit.Ground && c.velocityY < 0) || (hit.Ceiling && c.velocityY > 0) {
+		c.velocityY = 0
+	}
+	if hit.Wall {
+		c.sprinting = false
+	}
 
-	pos, _ = c.ctx.Game().World().Collide(pos)
-	c.SetPos(pos)
+	c.crouch.tick(sneakDrop, c.sneaking)
+	c.widen.tick(sprintWiden, c.sprinting)
+	c.step(mgl32.Vec2{moved.X(), moved.Z()}.Len())
+}
+
+// Box returns the box the player takes up in the world
+func (c *Camera) Box() block.Cuboid {
+	const half = playerWidth / 2
+	feet := c.pos.Sub(mgl32.Vec3{0, eyeHeight, 0})
+	return block.Cuboid{
+		Min: feet.Sub(mgl32.Vec3{half, 0, half}),
+		Max: feet.Add(mgl32.Vec3{half, playerHeight, half}),
+	}
+}
+
+// feet returns the block the feet of the player are in, the block below
+// is the one stood on
+func (c *Camera) feet() Vec3 {
+	// a little above the bottom of the box, which rests on the block below
+	y := c.pos.Y() - eyeHeight + 0.1
+	return Vec3{X: Round(c.pos.X()), Y: Round(y), Z: Round(c.pos.Z())}
+}
+
+// smoothed is a value eased in over easeTicks ticks, and interpolated
+// between ticks like the position
+type smoothed struct {
+	prev, cur float32
+}
+
+// tick eases the value towards full when on, towards 0 when not
+func (s *smoothed) tick(full float32, on bool) {
+	s.prev = s.cur
+	rate := full / easeTicks
+	if on {
+		s.cur = Min(s.cur+rate, full)
+	} else {
+		s.cur = Max(s.cur-rate, 0)
+	}
+}
+
+// at returns the value alpha of the way from the last tick to this one
+func (s smoothed) at(alpha float32) float32 {
+	return Mix(s.prev, s.cur, alpha)
+}
+
+// Interpolate places the camera alpha of the way from where the player
+// was on the tick before the last to where it is now, for the frames
+// drawn until the next tick
+func (c *Camera) Interpolate(alpha float32) {
+	c.alpha = alpha
+}
+
+// stepDistance is how far the player walks between footsteps
+const stepDistance = 1.6
+
+// step counts the distance walked on the ground, and publishes
+// an EventStep every stepDistance
+func (c *Camera) step(delta float32) {
+	if c.flying || !c.onGround || delta == 0 {
+		return
+	}
+	feet := c.feet()
+	ground := c.ctx.Game().World().Block(feet.Down())
+	if !ground.Obstacle {
+		return
+	}
+	c.walked += delta
+	if c.walked < stepDistance {
+		return
+	}
+	c.walked = 0
+	_ = c.evtPublisher.Publish(events.Event(time.Now(), &EventStep{
+		Pos:   mgl32.Vec3{c.pos.X(), feet.Y - 0.5, c.pos.Z()},
+		Block: ground,
+	}))
 }
 
 func (c *Camera) updateAngles() {
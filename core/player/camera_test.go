@@ -0,0 +1,26 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmoothedEasesIn(t *testing.T) {
+	var s smoothed
+	for i := 0; i < easeTicks; i++ {
+		s.tick(sneakDrop, true)
+	}
+	assert.InDelta(t, sneakDrop, s.at(1), 1e-6)
+	assert.InDelta(t, sneakDrop, s.at(0), sneakDrop/easeTicks+1e-6, "from the tick before")
+
+	s.tick(sneakDrop, true)
+	assert.Equal(t, float32(sneakDrop), s.cur, "no further than full")
+
+	s.tick(sneakDrop, false)
+	assert.InDelta(t, sneakDrop*(1-0.5/easeTicks), s.at(0.5), 1e-6, "between two ticks")
+	for i := 0; i < easeTicks; i++ {
+		s.tick(sneakDrop, false)
+	}
+	assert.Zero(t, s.cur)
+}
@@ -0,0 +1,13 @@
+package player
+
+import (
+	"github.com/artheus/go-minecraft/core/block"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// EventStep is published on the event pipe when the player takes a
+// step, Pos is on top of the Block stepped on
+type EventStep struct {
+	Pos   mgl32.Vec3
+	Block *block.Block
+}
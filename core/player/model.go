@@ -0,0 +1,165 @@
+package player
+
+import (
+	"image"
+
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The faces of a box, in the order they are unwrapped in a skin
+const (
+	faceFront = iota
+	faceBack
+	faceRight
+	faceLeft
+	faceUp
+	faceDown
+)
+
+// eyeHeight is how far above the feet the camera of a player is
+const eyeHeight = 1.62
+
+// pixel is the size of a skin pixel in blocks, the eyes are 28
+// pixels above the feet
+const pixel = eyeHeight / 28
+
+// part is a box of the player model, sizes and positions are in skin
+// pixels with the feet at the origin and the face towards -z
+type part struct {
+	base, overlay [2]int // top left corner of the unwrapped box in the skin
+	size          [3]int
+	min           mgl32.Vec3 // lowest corner
+	pivot         mgl32.Vec3 // the part rotates around this point
+	inflate       float32    // how far the overlay sticks out of the base
+}
+
+var (
+	headSize = [3]int{8, 8, 8}
+	bodySize = [3]int{8, 12, 4}
+	limbSize = [3]int{4, 12, 4}
+
+	head     = part{[2]int{0, 0}, [2]int{32, 0}, headSize, mgl32.Vec3{-4, 24, -4}, mgl32.Vec3{0, 24, 0}, 0.5}
+	body     = part{[2]int{16, 16}, [2]int{16, 32}, bodySize, mgl32.Vec3{-4, 12, -2}, mgl32.Vec3{0, 12, 0}, 0.25}
+	rightArm = part{[2]int{40, 16}, [2]int{40, 32}, limbSize, mgl32.Vec3{4, 12, -2}, mgl32.Vec3{5, 22, 0}, 0.25}
+	leftArm  = part{[2]int{32, 48}, [2]int{48, 48}, limbSize, mgl32.Vec3{-8, 12, -2}, mgl32.Vec3{-5, 22, 0}, 0.25}
+	rightLeg = part{[2]int{0, 16}, [2]int{0, 32}, limbSize, mgl32.Vec3{0, 0, -2}, mgl32.Vec3{2, 12, 0}, 0.25}
+	leftLeg  = part{[2]int{16, 48}, [2]int{0, 48}, limbSize, mgl32.Vec3{-4, 0, -2}, mgl32.Vec3{-2, 12, 0}, 0.25}
+)
+
+// modelParts are the parts of the model, Pose.Parts follows the same order
+var modelParts = []part{head, body, rightArm, leftArm, rightLeg, leftLeg}
+
+// boxFaces returns where the faces of a box are in the skin, relative
+// to the top left corner of the unwrapped box
+func boxFaces(size [3]int) [6]image.Rectangle {
+	w, h, d := size[0], size[1], size[2]
+	return [6]image.Rectangle{
+		faceFront: image.Rect(d, d, d+w, d+h),
+		faceBack:  image.Rect(2*d+w, d, 2*d+2*w, d+h),
+		faceRight: image.Rect(0, d, d, d+h),
+		faceLeft:  image.Rect(d+w, d, 2*d+w, d+h),
+		faceUp:    image.Rect(d, 0, d+w, d),
+		faceDown:  image.Rect(d+w, 0, d+2*w, d),
+	}
+}
+
+// vertices returns the triangles of the part and its overlay relative
+// to the pivot, as position, skin uv and normal
+func (p part) vertices() []float32 {
+	min := p.min.Sub(p.pivot)
+	size := mgl32.Vec3{float32(p.size[0]), float32(p.size[1]), float32(p.size[2])}
+	data := boxVertices(nil, min, size, p.base, p.size)
+	pad := mgl32.Vec3{p.inflate, p.inflate, p.inflate}
+	return boxVertices(data, min.Sub(pad), size.Add(pad.Mul(2)), p.overlay, p.size)
+}
+
+// boxVertices appends the 6 faces of the box at min to data, the
+// corners of each face are listed as seen from the outside
+func boxVertices(data []float32, min, size mgl32.Vec3, uv [2]int, tex [3]int) []float32 {
+	max := min.Add(size)
+	x0, y0, z0 := min.X(), min.Y(), min.Z()
+	x1, y1, z1 := max.X(), max.Y(), max.Z()
+	corners := [6][4]mgl32.Vec3{
+		faceFront: {{x1, y0, z0}, {x0, y0, z0}, {x0, y1, z0}, {x1, y1, z0}},
+		faceBack:  {{x0, y0, z1}, {x1, y0, z1}, {x1, y1, z1}, {x0, y1, z1}},
+		faceRight: {{x1, y0, z1}, {x1, y0, z0}, {x1, y1, z0}, {x1, y1, z1}},
+		faceLeft:  {{x0, y0, z0}, {x0, y0, z1}, {x0, y1, z1}, {x0, y1, z0}},
+		faceUp:    {{x1, y1, z0}, {x0, y1, z0}, {x0, y1, z1}, {x1, y1, z1}},
+		faceDown:  {{x1, y0, z1}, {x0, y0, z1}, {x0, y0, z0}, {x1, y0, z0}},
+	}
+	normals := [6]mgl32.Vec3{
+		faceFront: {0, 0, -1},
+		faceBack:  {0, 0, 1},
+		faceRight: {1, 0, 0},
+		faceLeft:  {-1, 0, 0},
+		faceUp:    {0, 1, 0},
+		faceDown:  {0, -1, 0},
+	}
+	for i, r := range boxFaces(tex) {
+		r = r.Add(image.Pt(uv[0], uv[1]))
+		u0, v0 := float32(r.Min.X)/SkinSize, float32(r.Min.Y)/SkinSize
+		u1, v1 := float32(r.Max.X)/SkinSize, float32(r.Max.Y)/SkinSize
+		// bottom left, bottom right, top right, top left
+		uvs := [4][2]float32{{u0, v1}, {u1, v1}, {u1, v0}, {u0, v0}}
+		c, n := corners[i], normals[i]
+		for _, j := range []int{0, 1, 2, 0, 2, 3} {
+			data = append(data,
+				c[j].X(), c[j].Y(), c[j].Z(),
+				uvs[j][0], uvs[j][1],
+				n.X(), n.Y(), n.Z())
+		}
+	}
+	return data
+}
+
+// Pose is how a player is placed and its parts are turned, the
+// rotations are in degrees
+type Pose struct {
+	Pos                mgl32.Vec3 // of the eyes
+	BodyYaw            float32    // same as PlayerState.Rx
+	HeadYaw, HeadPitch float32    // relative to the body
+	Swing              float32    // of the right leg, the other limbs follow
+	Sneak              float32    // how far the player crouches, 0-1
+}
+
+// sneakLean is how far in radians a sneaking player leans forward, from
+// the hips up
+const sneakLean = 0.5
+
+// Matrices returns the model matrix of every part in modelParts
+func (p Pose) Matrices() []mgl32.Mat4 {
+	base := mgl32.Translate3D(p.Pos.X(), p.Pos.Y()-eyeHeight, p.Pos.Z()).
+		Mul4(mgl32.HomogRotate3DY(yaw(p.BodyYaw))).
+		Mul4(mgl32.Scale3D(pixel, pixel, pixel))
+	// leaning turns the model towards -z around the hips, the head
+	// turns back up to look where it did
+	lean := sneakLean * p.Sneak
+	hips := body.pivot
+	leaning := mgl32.Translate3D(hips.X(), hips.Y(), hips.Z()).
+		Mul4(mgl32.HomogRotate3DX(-lean)).
+		Mul4(mgl32.Translate3D(-hips.X(), -hips.Y(), -hips.Z()))
+	turns := []mgl32.Mat4{
+		mgl32.HomogRotate3DX(lean).Mul4(mgl32.HomogRotate3DY(-Radian(p.HeadYaw))).Mul4(mgl32.HomogRotate3DX(Radian(p.HeadPitch))),
+		mgl32.Ident4(),
+		mgl32.HomogRotate3DX(-Radian(p.Swing)),
+		mgl32.HomogRotate3DX(Radian(p.Swing)),
+		mgl32.HomogRotate3DX(Radian(p.Swing)),
+		mgl32.HomogRotate3DX(-Radian(p.Swing)),
+	}
+	mats := make([]mgl32.Mat4, len(modelParts))
+	for i, part := range modelParts {
+		m := base
+		if part.min.Y() >= hips.Y() {
+			m = m.Mul4(leaning)
+		}
+		mats[i] = m.Mul4(mgl32.Translate3D(part.pivot.X(), part.pivot.Y(), part.pivot.Z())).Mul4(turns[i])
+	}
+	return mats
+}
+
+// yaw turns Rx, where 0 faces +x and 90 faces +z, into the
+// rotation of the model around the y axis
+func yaw(rx float32) float32 {
+	return -Radian(rx + 90)
+}
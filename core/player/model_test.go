@@ -0,0 +1,195 @@
+package player
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/texture"
+	. "github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoxFacesFollowTheSkinLayout(t *testing.T) {
+	faces := boxFaces(headSize)
+	assert.Equal(t, image.Rect(8, 8, 16, 16), faces[faceFront])
+	assert.Equal(t, image.Rect(24, 8, 32, 16), faces[faceBack])
+	assert.Equal(t, image.Rect(0, 8, 8, 16), faces[faceRight])
+	assert.Equal(t, image.Rect(16, 8, 24, 16), faces[faceLeft])
+	assert.Equal(t, image.Rect(8, 0, 16, 8), faces[faceUp])
+	assert.Equal(t, image.Rect(16, 0, 24, 8), faces[faceDown])
+}
+
+func TestPartVerticesAreAroundThePivot(t *testing.T) {
+	data := rightLeg.vertices()
+	// a base and an overlay box of 6 faces, 6 vertices of 8 floats each
+	require.Len(t, data, 2*6*6*8)
+	for i := 0; i < 6*6*8; i += 8 {
+		pos := mgl32.Vec3{data[i], data[i+1], data[i+2]}.Add(rightLeg.pivot)
+		assert.True(t, pos.X() >= 0 && pos.X() <= 4, "%v", pos)
+		assert.True(t, pos.Y() >= 0 && pos.Y() <= 12, "%v", pos)
+		assert.True(t, pos.Z() >= -2 && pos.Z() <= 2, "%v", pos)
+		u, v := data[i+3]*SkinSize, data[i+4]*SkinSize
+		assert.True(t, u >= 0 && u <= 16 && v >= 16 && v <= 32, "uv %v %v", u, v)
+	}
+}
+
+func TestPoseHeadLooksWhereThePlayerLooks(t *testing.T) {
+	for _, rx := range []float32{0, 90, -135} {
+		pose := Pose{Pos: mgl32.Vec3{3, 10, -2}, BodyYaw: rx - 30, HeadYaw: 30}
+		m := pose.Matrices()[0]
+		// the eyes are 4 pixels above the neck, the face 4 pixels in front
+		eyes := m.Mul4x1(mgl32.Vec4{0, 4, 0, 1}).Vec3()
+		face := m.Mul4x1(mgl32.Vec4{0, 4, -4, 1}).Vec3()
+		assert.InDeltaSlice(t, pose.Pos[:], eyes[:], 1e-4)
+		front := mgl32.Vec3{Cos(Radian(rx)), 0, Sin(Radian(rx))}
+		want := pose.Pos.Add(front.Mul(4 * pixel))
+		assert.InDeltaSlice(t, want[:], face[:], 1e-4, "rx %v", rx)
+	}
+}
+
+func TestPoseSwingsLimbsInOpposites(t *testing.T) {
+	mats := Pose{Swing: 30}.Matrices()
+	foot := func(i int) mgl32.Vec3 {
+		return mats[i].Mul4x1(mgl32.Vec4{0, -12, 0, 1}).Vec3()
+	}
+	// the model faces +x when Rx is 0
+	assert.Greater(t, foot(4).X(), float32(0), "right leg forward")
+	assert.Less(t, foot(5).X(), float32(0), "left leg back")
+	assert.Less(t, foot(2).X(), foot(3).X(), "arms swing the other way")
+}
+
+func TestInterpolateGivesSpeedUntilTheLastState(t *testing.T) {
+	p := &Player{}
+	p.UpdateState(playerState{PlayerState{X: 0, Z: 0}, 1})
+	p.UpdateState(playerState{PlayerState{X: 0.3, Z: 0.4}, 1.1})
+
+	s, speed := p.interpolate(1.15)
+	assert.InDelta(t, 0.15, s.X, 1e-4, "halfway from the previous state")
+	assert.InDelta(t, 5, speed, 1e-3)
+
+	_, speed = p.interpolate(1.3)
+	assert.Zero(t, speed)
+}
+
+func walk(p *Player, from, to, step float64, move func(t float64) PlayerState) Pose {
+	var pose Pose
+	for t := from; t < to; t += step {
+		if int(t*10) != int((t-step)*10) {
+			p.UpdateState(playerState{move(t), t})
+		}
+		pose = p.animate(t)
+	}
+	return pose
+}
+
+func TestAnimateSwingsWhileWalking(t *testing.T) {
+	p := &Player{}
+	walk(p, 1, 3, 1.0/60, func(t float64) PlayerState {
+		return PlayerState{X: float32(t) * walkSpeed}
+	})
+	assert.Greater(t, p.swing, float32(0.9))
+
+	pose := walk(p, 3, 5, 1.0/60, func(float64) PlayerState {
+		return PlayerState{X: 3 * walkSpeed}
+	})
+	assert.Less(t, p.swing, float32(0.01))
+	assert.InDelta(t, 0, pose.Swing, 0.5)
+}
+
+func TestAnimateTurnsTheBodyAfterTheHead(t *testing.T) {
+	p := &Player{}
+	standing := func(rx float32) func(float64) PlayerState {
+		return func(float64) PlayerState { return PlayerState{Rx: rx} }
+	}
+	pose := walk(p, 1, 2, 1.0/60, standing(0))
+	assert.Zero(t, pose.HeadYaw)
+
+	pose = walk(p, 2, 3, 1.0/60, standing(30))
+	assert.InDelta(t, 30, pose.HeadYaw, 1e-3, "the head turns alone")
+	assert.InDelta(t, 0, pose.BodyYaw, 1e-3)
+
+	pose = walk(p, 3, 4, 1.0/60, standing(-120))
+	assert.InDelta(t, -maxHeadYaw, pose.HeadYaw, 1e-3, "the body is dragged along")
+	assert.InDelta(t, -70, pose.BodyYaw, 1e-3)
+}
+
+func TestWrapAngle(t *testing.T) {
+	assert.Equal(t, float32(-90), wrapAngle(270))
+	assert.Equal(t, float32(10), wrapAngle(-350))
+	assert.Equal(t, float32(-180), wrapAngle(180))
+}
+
+func TestDefaultSkinHasAFaceAndNoOverlay(t *testing.T) {
+	skin := DefaultSkin()
+	face := boxFaces(headSize)[faceFront].Add(image.Pt(head.base[0], head.base[1]))
+	assert.Equal(t, uint8(0xff), skin.NRGBAAt(face.Min.X, face.Min.Y).A)
+	hat := boxFaces(headSize)[faceFront].Add(image.Pt(head.overlay[0], head.overlay[1]))
+	assert.Zero(t, skin.NRGBAAt(hat.Min.X, hat.Min.Y).A)
+}
+
+func TestNormalizeSkinMirrorsLegacyLimbs(t *testing.T) {
+	legacy := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	// the left most pixel of the right leg front, and of its outer side
+	legacy.SetNRGBA(4, 20, red)
+	legacy.SetNRGBA(0, 20, red)
+
+	skin, err := NormalizeSkin(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), skin.Bounds())
+	front := boxFaces(limbSize)[faceFront]
+	assert.Equal(t, red, skin.NRGBAAt(leftLeg.base[0]+front.Max.X-1, leftLeg.base[1]+front.Min.Y))
+	left := boxFaces(limbSize)[faceLeft]
+	assert.Equal(t, red, skin.NRGBAAt(leftLeg.base[0]+left.Max.X-1, leftLeg.base[1]+left.Min.Y))
+
+	_, err = NormalizeSkin(image.NewNRGBA(image.Rect(0, 0, 32, 32)))
+	assert.Error(t, err)
+}
+
+func TestLoadSkin(t *testing.T) {
+	dir := t.TempDir()
+	img, err := LoadSkin(dir, "7")
+	assert.NoError(t, err)
+	assert.Nil(t, img, "no skin")
+
+	require.NoError(t, texture.SavePNG(filepath.Join(dir, "7.png"), DefaultSkin()))
+	img, err = LoadSkin(dir, "7")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSkin().Pix, img.Pix)
+
+	require.NoError(t, texture.SavePNG(filepath.Join(dir, "8.png"), image.NewNRGBA(image.Rect(0, 0, 16, 16))))
+	_, err = LoadSkin(dir, "8")
+	assert.Error(t, err)
+}
+
+func TestPoseSneakLeansForward(t *testing.T) {
+	// the model faces -z when Rx is -90
+	standing := Pose{BodyYaw: -90}.Matrices()
+	sneaking := Pose{BodyYaw: -90, Sneak: 1}.Matrices()
+	neck := func(mats []mgl32.Mat4) mgl32.Vec3 {
+		return mats[0].Mul4x1(mgl32.Vec4{0, 0, 0, 1}).Vec3()
+	}
+	assert.Less(t, neck(sneaking).Z(), neck(standing).Z(), "the head is ahead")
+	assert.Less(t, neck(sneaking).Y(), neck(standing).Y(), "and lower")
+	assert.Equal(t, standing[4], sneaking[4], "the legs stay")
+
+	// the face still looks ahead
+	face := sneaking[0].Mul4x1(mgl32.Vec4{0, 4, -4, 1}).Vec3().Sub(sneaking[0].Mul4x1(mgl32.Vec4{0, 4, 0, 1}).Vec3())
+	assert.InDelta(t, 0, face.Y(), 1e-5)
+}
+
+func TestAnimateCrouchesWhileSneaking(t *testing.T) {
+	p := &Player{motion: motion{sneaking: true}}
+	still := func(float64) PlayerState { return PlayerState{} }
+	pose := walk(p, 1, 2, 1.0/60, still)
+	assert.Greater(t, pose.Sneak, float32(0.99))
+
+	p.motion = motion{}
+	pose = walk(p, 2, 3, 1.0/60, still)
+	assert.Less(t, pose.Sneak, float32(0.01), "and stands up again")
+}
@@ -0,0 +1,153 @@
+package player
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/artheus/go-minecraft/core/hud"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/glhf"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	Name     = flag.String("name", "", "display name shown to other players, saved with the player state")
+	NameTags = flag.String("nametags", "through", "name tags over other players: through (faint behind blocks), depth (hidden behind blocks) or off")
+)
+
+const (
+	tagScale    = 0.0125 // blocks per font pixel
+	tagLift     = 0.35   // from the eyes to the bottom of the tag
+	tagRange    = 64     // name tags further away are not drawn
+	tagFadeFrom = 48     // name tags fade out from here to tagRange
+	tagBehind   = 0.25   // alpha of the text seen through blocks
+)
+
+// tagBackground is the color of the box behind the text
+var tagBackground = mgl32.Vec4{0, 0, 0, 0.25}
+
+// tagName is the text of the name tag of player id
+func tagName(id int32, name string) string {
+	if name == "" {
+		return fmt.Sprintf("Player %d", id)
+	}
+	return name
+}
+
+// tagFade returns the alpha of a name tag dist blocks away
+func tagFade(dist float32) float32 {
+	return Max(0, Min(1, (tagRange-dist)/(tagRange-tagFadeFrom)))
+}
+
+// tagVertices lays out text centered above the origin over a box, in
+// font pixels with y down. The first 6 vertices are the box
+func tagVertices(font *hud.Font, text string) []float32 {
+	b := hud.NewTextBatch(font)
+	width := font.Measure(text)
+	left, top := -width/2, -font.Height
+	b.Box(image.Rect(left-2, top-1, left+width+2, 1), tagBackground)
+	b.Text(text, left, top, false)
+	return b.Vertices()
+}
+
+// nameTag is the text of a name tag on the gpu
+type nameTag struct {
+	text  string
+	slice *glhf.VertexSlice
+}
+
+// update lays out text again when it changed, on the main thread
+func (t *nameTag) update(shader *glhf.Shader, font *hud.Font, text string) {
+	if t.slice != nil && t.text == text {
+		return
+	}
+	data := tagVertices(font, text)
+	t.text = text
+	n := len(data) * 4 / shader.VertexFormat().Size()
+	t.slice = glhf.MakeVertexSlice(shader, n, n)
+	t.slice.Begin()
+	t.slice.SetVertexData(data)
+	t.slice.End()
+}
+
+// draw draws the box behind the text, unless its alpha box is 0,
+// and the text with alpha text
+func (t *nameTag) draw(shader *glhf.Shader, box, text float32) {
+	t.slice.Begin()
+	if box > 0 {
+		shader.SetUniformAttr(4, box)
+		t.slice.Slice(0, 6).Draw()
+	}
+	shader.SetUniformAttr(4, text)
+	t.slice.Slice(6, t.slice.Len()).Draw()
+	t.slice.End()
+}
+
+// renderTags draws the name tags of the players above their heads,
+// farthest first so they blend over each other
+func (r *PlayerRenderer) renderTags(mat mgl32.Mat4, camera, front mgl32.Vec3, poses map[int32]Pose) {
+	mode := *NameTags
+	if mode == "off" {
+		return
+	}
+
+	type visible struct {
+		tag   *nameTag
+		pos   mgl32.Vec3
+		dist  float32
+		depth bool // hidden behind blocks
+	}
+	var tags []visible
+	for id, pose := range poses {
+		pos := pose.Pos.Add(mgl32.Vec3{0, tagLift, 0})
+		dist := pos.Sub(camera).Len()
+		if dist >= tagRange {
+			continue
+		}
+		tag := r.players[id].tag
+		tag.update(r.tagShader, r.font, tagName(id, r.names[id]))
+		// sneaking players can't be spotted through walls
+		depth := mode == "depth" || r.players[id].motion.sneaking
+		tags = append(tags, visible{tag, pos, dist, depth})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].dist > tags[j].dist })
+
+	right := front.Cross(mgl32.Vec3{0, 1, 0}).Normalize()
+	up := right.Cross(front).Normalize()
+
+	gl.Disable(gl.CULL_FACE)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+	defer func() {
+		gl.DepthMask(true)
+		gl.Disable(gl.BLEND)
+		gl.Enable(gl.CULL_FACE)
+		gl.Enable(gl.DEPTH_TEST)
+	}()
+
+	r.tagShader.Begin()
+	r.tagTexture.Begin()
+	r.tagShader.SetUniformAttr(0, mat)
+	r.tagShader.SetUniformAttr(2, right.Mul(tagScale))
+	r.tagShader.SetUniformAttr(3, up.Mul(tagScale))
+	for _, t := range tags {
+		fade := tagFade(t.dist)
+		r.tagShader.SetUniformAttr(1, t.pos)
+		if t.depth {
+			t.tag.draw(r.tagShader, fade, fade)
+			continue
+		}
+		// faint through the blocks in front of the tag, then
+		// fully where nothing is in front
+		gl.Disable(gl.DEPTH_TEST)
+		t.tag.draw(r.tagShader, fade, fade*tagBehind)
+		gl.Enable(gl.DEPTH_TEST)
+		t.tag.draw(r.tagShader, 0, fade)
+	}
+	r.tagTexture.End()
+	r.tagShader.End()
+}
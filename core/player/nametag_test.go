@@ -0,0 +1,42 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/hud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagNameFallsBackToTheID(t *testing.T) {
+	assert.Equal(t, "Steve", tagName(3, "Steve"))
+	assert.Equal(t, "Player 3", tagName(3, ""))
+}
+
+func TestTagFade(t *testing.T) {
+	assert.Equal(t, float32(1), tagFade(5))
+	assert.Equal(t, float32(1), tagFade(tagFadeFrom))
+	assert.InDelta(t, 0.5, tagFade((tagFadeFrom+tagRange)/2), 1e-6)
+	assert.Zero(t, tagFade(tagRange))
+	assert.Zero(t, tagFade(100))
+}
+
+func TestTagVerticesAreCenteredOverABox(t *testing.T) {
+	font := hud.DefaultFont()
+	data := tagVertices(font, "ab")
+	// the box and 2 glyphs, 6 vertices of 8 floats each
+	require.Len(t, data, 3*6*8)
+
+	width := float32(font.Measure("ab"))
+	for i := 0; i < 6*8; i += 8 {
+		assert.Equal(t, tagBackground[:], data[i+4:i+8], "box color")
+	}
+	assert.Equal(t, -width/2-2, data[0], "box left")
+	assert.Equal(t, width/2+2, data[8], "box right")
+	for i := 6 * 8; i < len(data); i += 8 {
+		x, y := data[i], data[i+1]
+		assert.True(t, x >= -width/2 && x <= width/2+float32(font.Width), "x %v", x)
+		assert.True(t, y >= -float32(font.Height) && y <= 0, "y %v, the text is above the origin", y)
+		assert.Equal(t, float32(1), data[i+7], "text is opaque")
+	}
+}
@@ -1,13 +1,15 @@
 package player
 
 import (
-	"github.com/artheus/go-minecraft/core/block"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+
 	"github.com/artheus/go-minecraft/core/ctx"
-	"github.com/artheus/go-minecraft/core/item"
-	"github.com/artheus/go-minecraft/core/texture"
+	"github.com/artheus/go-minecraft/core/hud"
 	. "github.com/artheus/go-minecraft/core/types"
 	. "github.com/artheus/go-minecraft/math/f32"
-	"log"
 
 	"github.com/faiface/glhf"
 	"github.com/faiface/mainthread"
@@ -16,6 +18,20 @@ import (
 	"github.com/icexin/gocraft-server/proto"
 )
 
+// walkSpeed is how fast a walking player moves in blocks per second,
+// the limbs swing the most at this speed
+const walkSpeed = 6
+
+const (
+	maxSwing   = 40 // degrees the limbs swing back and forth
+	maxHeadYaw = 50 // degrees the head turns before the body follows
+)
+
+// motion is if a player sneaks or sprints
+type motion struct {
+	sneaking, sprinting bool
+}
+
 type playerState struct {
 	PlayerState
 	time float64
@@ -23,83 +39,144 @@ type playerState struct {
 
 type Player struct {
 	s1, s2 playerState
+	skin   *glhf.Texture
+	tag    *nameTag
+	motion motion
 
-	shader *glhf.Shader
-	mesh   *Mesh
+	last    float64 // time of the last animate
+	bodyYaw float32
+	phase   float32 // of the limb swing, in radians
+	swing   float32 // how much the limbs swing, 0-1, more sprinting
+	sneak   float32 // how far it crouches, 0-1
 }
 
-// Linear interpolation to calculate player position
-func (p *Player) computeMat() mgl32.Mat4 {
+// interpolate returns the state of the player at time now by linear
+// interpolation, and how fast it moves horizontally
+func (p *Player) interpolate(now float64) (PlayerState, float32) {
 	t1 := p.s2.time - p.s1.time
-	t2 := glfw.GetTime() - p.s2.time
-	t := Min(float32(t2/t1), 1)
-
-	x := Mix(p.s1.X, p.s2.X, t)
-	y := Mix(p.s1.Y, p.s2.Y, t)
-	z := Mix(p.s1.Z, p.s2.Z, t)
-	rx := Mix(p.s1.Rx, p.s2.Rx, t)
-	ry := Mix(p.s1.Ry, p.s2.Ry, t)
-
-	front := mgl32.Vec3{
-		Cos(Radian(ry)) * Cos(Radian(rx)),
-		Sin(Radian(ry)),
-		Cos(Radian(ry)) * Sin(Radian(rx)),
-	}.Normalize()
-	right := front.Cross(mgl32.Vec3{0, 1, 0})
-	up := right.Cross(front).Normalize()
-	pos := mgl32.Vec3{x, y, z}
-	return mgl32.LookAtV(pos, pos.Add(front), up).Inv()
-}
+	t := float32(1)
+	if t1 > 0 {
+		t = Min(float32((now-p.s2.time)/t1), 1)
+	}
 
-func (p *Player) UpdateState(s playerState) {
-	p.s1, p.s2 = p.s2, s
+	s := PlayerState{
+		X:  Mix(p.s1.X, p.s2.X, t),
+		Y:  Mix(p.s1.Y, p.s2.Y, t),
+		Z:  Mix(p.s1.Z, p.s2.Z, t),
+		Rx: Mix(p.s1.Rx, p.s2.Rx, t),
+		Ry: Mix(p.s1.Ry, p.s2.Ry, t),
+	}
+	if t >= 1 {
+		return s, 0
+	}
+	dx, dz := p.s2.X-p.s1.X, p.s2.Z-p.s1.Z
+	return s, Sqrt(dx*dx+dz*dz) / float32(t1)
 }
 
-func (p *Player) Draw(mat mgl32.Mat4) {
-	mat = mat.Mul4(p.computeMat())
+// animate moves the limbs and turns the body of the player up to
+// time now, and returns its pose
+func (p *Player) animate(now float64) Pose {
+	s, speed := p.interpolate(now)
+	if p.last == 0 {
+		p.last = now
+		p.bodyYaw = s.Rx
+	}
+	dt := float32(now - p.last)
+	p.last = now
 
-	p.shader.SetUniformAttr(0, mat)
-	p.mesh.Render()
+	// a full swing forth and back takes two footsteps
+	p.phase = float32(math.Mod(float64(p.phase+speed*dt*math.Pi/stepDistance), 2*math.Pi))
+	most := float32(1)
+	if p.motion.sprinting {
+		most = sprintSpeed
+	}
+	p.swing = Mix(p.swing, Min(speed/walkSpeed, most), Min(dt*8, 1))
+	sneak := float32(0)
+	if p.motion.sneaking {
+		sneak = 1
+	}
+	p.sneak = Mix(p.sneak, sneak, Min(dt*8, 1))
+
+	// the body turns to where a moving player looks, and is dragged
+	// along when the head turns too far
+	if speed > 0 {
+		p.bodyYaw += wrapAngle(s.Rx-p.bodyYaw) * Min(dt*8, 1)
+	}
+	turn := wrapAngle(s.Rx - p.bodyYaw)
+	if turn > maxHeadYaw {
+		p.bodyYaw += turn - maxHeadYaw
+	} else if turn < -maxHeadYaw {
+		p.bodyYaw += turn + maxHeadYaw
+	}
+
+	return Pose{
+		Pos:       mgl32.Vec3{s.X, s.Y, s.Z},
+		BodyYaw:   p.bodyYaw,
+		HeadYaw:   wrapAngle(s.Rx - p.bodyYaw),
+		HeadPitch: s.Ry,
+		Swing:     Sin(p.phase) * maxSwing * p.swing,
+		Sneak:     p.sneak,
+	}
+}
+
+// wrapAngle returns a in degrees within [-180, 180)
+func wrapAngle(a float32) float32 {
+	return a - 360*Floor((a+180)/360)
 }
 
-func (p *Player) Release() {
-	p.mesh.Release()
+func (p *Player) UpdateState(s playerState) {
+	p.s1, p.s2 = p.s2, s
 }
 
 type PlayerRenderer struct {
-	ctx     *ctx.Context
-	shader  *glhf.Shader
-	texture *glhf.Texture
+	ctx    *ctx.Context
+	shader *glhf.Shader
+	meshes []*Mesh       // of modelParts
+	skin   *glhf.Texture // for players without their own
+
+	font       *hud.Font
+	tagShader  *glhf.Shader
+	tagTexture *glhf.Texture
+
+	addMx   sync.Mutex // held while a player is made, so it is made once
+	mx      sync.Mutex
 	players map[int32]*Player
+	names   map[int32]string // may be known before the player shows up
+	motions map[int32]motion // likewise
 }
 
 func NewPlayerRenderer(ctx *ctx.Context) (*PlayerRenderer, error) {
-	var (
-		err error
-	)
-	img, rect, err := texture.LoadImage(*texture.TexturePath)
+	img, err := LoadSkin(*SkinPath, "default")
 	if err != nil {
-		return nil, err
+		log.Print(err)
+	}
+	if img == nil {
+		img = DefaultSkin()
 	}
 
 	r := &PlayerRenderer{
 		players: make(map[int32]*Player),
+		names:   make(map[int32]string),
+		motions: make(map[int32]motion),
+		font:    hud.GameFont(),
 		ctx:     ctx,
 	}
 	mainthread.Call(func() {
-		r.shader, err = glhf.NewShader(glhf.AttrFormat{
-			glhf.Attr{Name: "pos", Type: glhf.Vec3},
-			glhf.Attr{Name: "tex", Type: glhf.Vec2},
-			glhf.Attr{Name: "normal", Type: glhf.Vec3},
-		}, glhf.AttrFormat{
-			glhf.Attr{Name: "matrix", Type: glhf.Mat4},
-		}, playerVertexSource, playerFragmentSource)
-
+		r.shader, err = glhf.NewShader(modelVertexFormat, modelUniformFormat, playerVertexSource, playerFragmentSource)
 		if err != nil {
 			return
 		}
-		r.texture = glhf.NewTexture(rect.Dx(), rect.Dy(), false, img)
+		for _, part := range modelParts {
+			r.meshes = append(r.meshes, NewMesh(r.shader, part.vertices()))
+		}
+		r.skin = glhf.NewTexture(SkinSize, SkinSize, false, img.Pix)
 
+		r.tagShader, err = glhf.NewShader(tagVertexFormat, tagUniformFormat, tagVertexSource, tagFragmentSource)
+		if err != nil {
+			return
+		}
+		atlas := r.font.Atlas
+		r.tagTexture = glhf.NewTexture(atlas.Rect.Dx(), atlas.Rect.Dy(), false, atlas.Pix)
 	})
 	if err != nil {
 		return nil, err
@@ -108,6 +185,21 @@ func NewPlayerRenderer(ctx *ctx.Context) (*PlayerRenderer, error) {
 	return r, nil
 }
 
+// newPlayer loads the skin of player id, or uses the default skin
+func (r *PlayerRenderer) newPlayer(id int32) *Player {
+	p := &Player{skin: r.skin, tag: &nameTag{}}
+	img, err := LoadSkin(*SkinPath, strconv.Itoa(int(id)))
+	if err != nil {
+		log.Print(err)
+	}
+	if img != nil {
+		mainthread.Call(func() {
+			p.skin = glhf.NewTexture(SkinSize, SkinSize, false, img.Pix)
+		})
+	}
+	return p
+}
+
 func (r *PlayerRenderer) UpdateOrAdd(id int32, s proto.PlayerState) {
 	state := playerState{
 		PlayerState: PlayerState{
@@ -120,59 +212,93 @@ func (r *PlayerRenderer) UpdateOrAdd(id int32, s proto.PlayerState) {
 		time: glfw.GetTime(),
 	}
 
+	// the skin is loaded on the main thread, which renders holding mx
+	r.addMx.Lock()
+	defer r.addMx.Unlock()
+
+	r.mx.Lock()
 	p, ok := r.players[id]
+	r.mx.Unlock()
 	if !ok {
 		log.Printf("add new player %d", id)
-		blockData := block.BlockData(
-			[]float32{},
-			block.Sides(
-				true,
-				true,
-				true,
-				true,
-				true,
-				true,
-			),
-			Vec3{
-				0,
-				0,
-				0,
-			},
-			item.Tex.Texture("core:player"),
-		)
-		var mesh *Mesh
-		mainthread.Call(func() {
-			mesh = NewMesh(r.shader, blockData)
-		})
-		p = &Player{
-			shader: r.shader,
-			mesh:   mesh,
-		}
-		r.players[id] = p
-		p.s1 = state
+		p = r.newPlayer(id)
+		p.s2 = state
 	}
+
+	r.mx.Lock()
 	p.UpdateState(state)
+	r.players[id] = p
+	r.mx.Unlock()
 }
 
-func (r *PlayerRenderer) Remove(id int32) {
-	log.Printf("remove player %d", id)
-	p, ok := r.players[id]
-	if ok {
-		mainthread.CallNonBlock(func() {
-			p.Release()
+// SetName sets the display name of player id, shown on its name tag
+func (r *PlayerRenderer) SetName(id int32, name string) {
+	r.mx.Lock()
+	r.names[id] = name
+	r.mx.Unlock()
+}
+
+// SetMotion sets if player id sneaks or sprints, a sneaking player
+// crouches and its name tag is hidden behind blocks
+func (r *PlayerRenderer) SetMotion(id int32, sneaking, sprinting bool) {
+	r.mx.Lock()
+	r.motions[id] = motion{sneaking, sprinting}
+	r.mx.Unlock()
+}
+
+// Players returns the players with their name tag and latest position
+func (r *PlayerRenderer) Players() []RemotePlayer {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	players := make([]RemotePlayer, 0, len(r.players))
+	for id, p := range r.players {
+		players = append(players, RemotePlayer{
+			ID:   id,
+			Name: tagName(id, r.names[id]),
+			Pos:  mgl32.Vec3{p.s2.X, p.s2.Y, p.s2.Z},
 		})
 	}
-	delete(r.players, id)
+	return players
+}
 
+func (r *PlayerRenderer) Remove(id int32) {
+	log.Printf("remove player %d", id)
+	r.mx.Lock()
+	delete(r.players, id)
+	delete(r.names, id)
+	delete(r.motions, id)
+	r.mx.Unlock()
 }
 
 func (r *PlayerRenderer) Render() {
-	mat := r.ctx.Game().ChunkRenderer().Get3dMat()
+	game := r.ctx.Game()
+	mat := game.ChunkRenderer().Get3dMat()
+	daylight := game.Clock().Daylight()
+	now := glfw.GetTime()
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	poses := make(map[int32]Pose, len(r.players))
 	r.shader.Begin()
-	r.texture.Begin()
-	for _, p := range r.players {
-		p.Draw(mat)
+	r.shader.SetUniformAttr(0, mat)
+	for id, p := range r.players {
+		p.motion = r.motions[id]
+		pose := p.animate(now)
+		poses[id] = pose
+		pos := pose.Pos
+		sky, torch := game.World().Light(Vec3{X: Round(pos.X()), Y: Round(pos.Y()), Z: Round(pos.Z())})
+		r.shader.SetUniformAttr(2, Max(Mix(0.3, 1, sky)*daylight, torch))
+
+		p.skin.Begin()
+		for i, m := range pose.Matrices() {
+			r.shader.SetUniformAttr(1, m)
+			r.meshes[i].Render()
+		}
+		p.skin.End()
 	}
-	r.texture.End()
 	r.shader.End()
+
+	camera := game.Camera()
+	r.renderTags(mat, camera.Pos(), camera.Front(), poses)
 }
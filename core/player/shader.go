@@ -1,5 +1,23 @@
 package player
 
+import (
+	"github.com/artheus/go-minecraft/core/hud"
+	"github.com/faiface/glhf"
+)
+
+var (
+	modelVertexFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "pos", Type: glhf.Vec3},
+		glhf.Attr{Name: "tex", Type: glhf.Vec2},
+		glhf.Attr{Name: "normal", Type: glhf.Vec3},
+	}
+	modelUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "model", Type: glhf.Mat4},
+		glhf.Attr{Name: "light", Type: glhf.Float},
+	}
+)
+
 var playerVertexSource = `
 #version 330 core
 
@@ -8,12 +26,15 @@ in vec2 tex;
 in vec3 normal;
 
 uniform mat4 matrix;
+uniform mat4 model;
 
 out vec2 Tex;
+out vec3 Normal;
 
 void main() {
-    gl_Position = matrix *  vec4(pos, 1.0);
+    gl_Position = matrix * model * vec4(pos, 1.0);
     Tex = tex;
+    Normal = mat3(model) * normal;
 }
 `
 
@@ -21,15 +42,74 @@ var playerFragmentSource = `
 #version 330 core
 
 in vec2 Tex;
+in vec3 Normal;
+uniform sampler2D tex;
+uniform float light;
+
+out vec4 FragColor;
+
+void main() {
+    vec4 color = texture(tex, Tex);
+    if (color.a < 0.5) {
+        discard;
+    }
+    // tops are brightest and bottoms darkest, like the block faces
+    vec3 n = normalize(Normal);
+    float shade = 0.8 + 0.2 * n.y - 0.1 * abs(n.x);
+    FragColor = vec4(color.rgb * shade * light, 1);
+}
+`
+
+var (
+	tagUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "center", Type: glhf.Vec3},
+		glhf.Attr{Name: "right", Type: glhf.Vec3},
+		glhf.Attr{Name: "up", Type: glhf.Vec3},
+		glhf.Attr{Name: "alpha", Type: glhf.Float},
+	}
+)
+
+var tagVertexFormat = hud.TextVertexFormat
+
+var tagVertexSource = `
+#version 330 core
+
+in vec2 pos;
+in vec2 tex;
+in vec4 color;
+
+uniform mat4 matrix;
+uniform vec3 center;
+uniform vec3 right;
+uniform vec3 up;
+
+out vec2 Tex;
+out vec4 Color;
+
+void main() {
+    // the text is laid out with y down
+    gl_Position = matrix * vec4(center + right * pos.x - up * pos.y, 1.0);
+    Tex = tex;
+    Color = color;
+}
+`
+
+var tagFragmentSource = `
+#version 330 core
+
+in vec2 Tex;
+in vec4 Color;
 uniform sampler2D tex;
+uniform float alpha;
 
 out vec4 FragColor;
 
 void main() {
-    vec3 color = vec3(texture(tex, vec2(Tex.x, 1-Tex.y)));
-    if (color == vec3(1,0,1)) {
+    float a = Color.a * texture(tex, Tex).a * alpha;
+    if (a == 0) {
         discard;
     }
-    FragColor = vec4(color, 1);
+    FragColor = vec4(Color.rgb, a);
 }
 `
@@ -0,0 +1,143 @@
+package player
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	SkinPath = flag.String("skins", "skins", "directory of 64x64 player skins, named <player id>.png, default.png replaces the built in default")
+)
+
+// SkinSize is the width and height of a skin in pixels
+const SkinSize = 64
+
+// LoadSkin loads the skin dir/<name>.png, nil is returned when
+// there is no such file
+func LoadSkin(dir, name string) (*image.NRGBA, error) {
+	fname := filepath.Join(dir, name+".png")
+	if _, err := os.Stat(fname); os.IsNotExist(err) {
+		return nil, nil
+	}
+	img, err := loadSkinFile(fname)
+	if err != nil {
+		return nil, errors.Wrapf(err, "skin %s", fname)
+	}
+	return img, nil
+}
+
+func loadSkinFile(fname string) (*image.NRGBA, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return NormalizeSkin(img)
+}
+
+// NormalizeSkin converts a skin to 64x64 NRGBA pixels, legacy 64x32
+// skins get their left arm and leg mirrored from the right ones
+func NormalizeSkin(img image.Image) (*image.NRGBA, error) {
+	b := img.Bounds()
+	if b.Dx() != SkinSize || (b.Dy() != SkinSize && b.Dy() != SkinSize/2) {
+		return nil, errors.Errorf("bad skin size %dx%d, want 64x64 or 64x32", b.Dx(), b.Dy())
+	}
+	skin := image.NewNRGBA(image.Rect(0, 0, SkinSize, SkinSize))
+	draw.Draw(skin, image.Rect(0, 0, b.Dx(), b.Dy()), img, b.Min, draw.Src)
+	if b.Dy() == SkinSize/2 {
+		mirrorBox(skin, rightLeg.base, leftLeg.base, limbSize)
+		mirrorBox(skin, rightArm.base, leftArm.base, limbSize)
+	}
+	return skin, nil
+}
+
+// mirrorBox copies the unwrapped box at src to dst flipped left to
+// right, so the copy looks like the other side of the body
+func mirrorBox(img *image.NRGBA, src, dst [2]int, size [3]int) {
+	faces := boxFaces(size)
+	// the outer sides swap places when mirrored
+	swap := map[int]int{faceRight: faceLeft, faceLeft: faceRight}
+	for i, f := range faces {
+		to := faces[i]
+		if j, ok := swap[i]; ok {
+			to = faces[j]
+		}
+		for y := 0; y < f.Dy(); y++ {
+			for x := 0; x < f.Dx(); x++ {
+				c := img.At(src[0]+f.Min.X+x, src[1]+f.Min.Y+y)
+				img.Set(dst[0]+to.Max.X-1-x, dst[1]+to.Min.Y+y, c)
+			}
+		}
+	}
+}
+
+// DefaultSkin paints the skin used by players without one
+func DefaultSkin() *image.NRGBA {
+	var (
+		skin  = color.NRGBA{R: 0xc6, G: 0x8e, B: 0x6c, A: 0xff}
+		hair  = color.NRGBA{R: 0x3b, G: 0x28, B: 0x1c, A: 0xff}
+		shirt = color.NRGBA{R: 0x2f, G: 0x8f, B: 0x9d, A: 0xff}
+		pants = color.NRGBA{R: 0x3a, G: 0x3f, B: 0x8f, A: 0xff}
+		shoes = color.NRGBA{R: 0x4a, G: 0x4a, B: 0x4a, A: 0xff}
+		eye   = color.NRGBA{R: 0x2c, G: 0x3e, B: 0x7a, A: 0xff}
+		white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		mouth = color.NRGBA{R: 0x8a, G: 0x4c, B: 0x3d, A: 0xff}
+	)
+	img := image.NewNRGBA(image.Rect(0, 0, SkinSize, SkinSize))
+	fill := func(at [2]int, r image.Rectangle, c color.Color) {
+		r = r.Add(image.Pt(at[0], at[1]))
+		draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	faces := boxFaces(headSize)
+	for _, f := range faces {
+		fill(head.base, f, skin)
+	}
+	// hair on top and down the back and sides
+	fill(head.base, faces[faceUp], hair)
+	fill(head.base, faces[faceBack], hair)
+	for _, i := range []int{faceFront, faceLeft, faceRight} {
+		f := faces[i]
+		fill(head.base, image.Rect(f.Min.X, f.Min.Y, f.Max.X, f.Min.Y+2), hair)
+	}
+	front := faces[faceFront].Min
+	for _, x := range []int{1, 5} {
+		fill(head.base, image.Rect(front.X+x, front.Y+4, front.X+x+1, front.Y+5), white)
+		fill(head.base, image.Rect(front.X+x+1, front.Y+4, front.X+x+2, front.Y+5), eye)
+	}
+	fill(head.base, image.Rect(front.X+3, front.Y+6, front.X+5, front.Y+7), mouth)
+
+	for _, f := range boxFaces(bodySize) {
+		fill(body.base, f, shirt)
+	}
+	// short sleeves and shoes cover the top and bottom rows of the sides
+	for _, p := range []part{rightArm, leftArm} {
+		for i, f := range boxFaces(limbSize) {
+			fill(p.base, f, skin)
+			if i != faceUp && i != faceDown {
+				fill(p.base, image.Rect(f.Min.X, f.Min.Y, f.Max.X, f.Min.Y+4), shirt)
+			}
+		}
+		fill(p.base, boxFaces(limbSize)[faceUp], shirt)
+	}
+	for _, p := range []part{rightLeg, leftLeg} {
+		for i, f := range boxFaces(limbSize) {
+			fill(p.base, f, pants)
+			if i != faceUp && i != faceDown {
+				fill(p.base, image.Rect(f.Min.X, f.Max.Y-2, f.Max.X, f.Max.Y), shoes)
+			}
+		}
+		fill(p.base, boxFaces(limbSize)[faceDown], shoes)
+	}
+	return img
+}
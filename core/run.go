@@ -3,11 +3,17 @@ package core
 import (
 	"github.com/artheus/go-minecraft/core/ctx"
 	"github.com/artheus/go-minecraft/core/game"
+	"github.com/artheus/go-minecraft/core/game/clock"
 	"github.com/artheus/go-minecraft/core/game/rpc"
+	"github.com/artheus/go-minecraft/core/game/settings"
 	"github.com/artheus/go-minecraft/core/game/store"
 	"github.com/artheus/go-minecraft/core/item"
+	"github.com/artheus/go-minecraft/core/player"
+	"github.com/artheus/go-minecraft/core/types"
 	"log"
-	"time"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func Run() {
@@ -19,13 +25,25 @@ func Run() {
 		log.Fatal(err)
 	}
 
+	err = settings.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = store.InitStore()
 	if err != nil {
 		log.Panic(err)
 	}
 	defer store.Storage.Close()
 
-	gameApp, err = game.NewGame(800, 600)
+	if *game.Headless {
+		if *game.ScreenshotPath != "" {
+			log.Fatal("-screenshot needs a window, it can't be used with -headless")
+		}
+		gameApp, err = game.NewHeadlessGame()
+	} else {
+		gameApp, err = game.NewGame(800, 600)
+	}
 	if err != nil {
 		log.Panic(err)
 	}
@@ -49,14 +67,80 @@ func Run() {
 		defer rpc.Client.Close()
 	}
 
-	gameApp.Camera().Restore(store.Storage.GetPlayerState())
-	tick := time.Tick(time.Second / 60)
+	state := store.Storage.GetPlayerState()
+	if *player.Name != "" {
+		state.Name = *player.Name
+	}
+	gameApp.RestorePlayer(state)
+	rpc.ClientSetPlayerName(appCtx, state.Name)
+	restoreTime(gameApp.Clock())
+
+	if *game.ScreenshotPath != "" {
+		screenshot(gameApp)
+		return
+	}
+
+	// an interrupted game still saves the player state and time
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	// every update draws a frame, paced by vsync and -maxfps, and runs
+	// the game ticks due since the last one. A minimized window only
+	// ticks
+loop:
 	for !gameApp.ShouldClose() {
-		<-tick
-		gameApp.Update()
+		select {
+		case s := <-sig:
+			log.Printf("%s, stopping", s)
+			break loop
+		default:
+			gameApp.Update()
+		}
+	}
+
+	if err = store.Storage.UpdatePlayerState(gameApp.PlayerState()); err != nil {
+		log.Panic(err)
 	}
 
-	if err = store.Storage.UpdatePlayerState(gameApp.Camera().State()); err != nil {
+	if err = store.Storage.UpdateTime(gameApp.Clock().Time()); err != nil {
 		log.Panic(err)
 	}
 }
+
+// screenshot saves a single frame, seen from -camera when given,
+// the player state is left as it was
+func screenshot(gameApp *game.Application) {
+	// flying keeps gravity from moving the camera while chunks load
+	if !gameApp.Camera().Flying() {
+		gameApp.Camera().FlipFlying()
+	}
+	if *game.ScreenshotCamera != "" {
+		state, err := game.ParseCamera(*game.ScreenshotCamera)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gameApp.Camera().Restore(state)
+	}
+	if err := gameApp.Screenshot(*game.ScreenshotPath); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("saved screenshot %s", *game.ScreenshotPath)
+}
+
+// restoreTime sets the world time from the store, the -time flag
+// or the server, the later ones taking precedence
+func restoreTime(c types.IClock) {
+	if t, ok := store.Storage.GetTime(); ok {
+		c.Set(t)
+	}
+	if *clock.StartTime != "" {
+		t, err := clock.ParseTime(*clock.StartTime)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c.Set(t)
+	}
+	if t, ok := rpc.ClientFetchTime(); ok {
+		c.Set(t)
+	}
+}
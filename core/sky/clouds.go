@@ -0,0 +1,59 @@
+package sky
+
+import (
+	"math"
+
+	"github.com/artheus/go-minecraft/core/game/clock"
+	. "github.com/artheus/go-minecraft/math/f32"
+)
+
+const (
+	// cloudTextureSize is the width and height of the noise texture
+	cloudTextureSize = 256
+	// cloudTile is how many blocks the noise texture covers before it repeats
+	cloudTile = cloudTextureSize * 6
+	// cloudSlices are the layers drawn through the noise, cloudThickness apart
+	cloudSlices    = 4
+	cloudThickness = 4
+)
+
+// cloudTexture makes a square RGBA image of noise that repeats without
+// seams, each texel is blended with the noise one size over on each
+// axis, so the noise matches up at the opposite edges
+func cloudTexture(size int) []uint8 {
+	pix := make([]uint8, size*size*4)
+	noise := func(x, y float32) float32 {
+		return Noise2(x*0.1, y*0.1, 4, 0.5, 2)
+	}
+	n := float32(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			fx, fy := float32(x), float32(y)
+			wx, wy := fx/n, fy/n
+			v := noise(fx, fy)*(1-wx)*(1-wy) +
+				noise(fx-n, fy)*wx*(1-wy) +
+				noise(fx, fy-n)*(1-wx)*wy +
+				noise(fx-n, fy-n)*wx*wy
+			// blending flattens the noise towards the middle of the
+			// texture, stretch it back around its mean
+			c := Min(Max((v-0.5)*2.4+0.5, 0), 1)
+			i := (y*size + x) * 4
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = uint8(c*255), uint8(c*255), uint8(c*255), 255
+		}
+	}
+	return pix
+}
+
+// cloudOffset returns how far the clouds have moved along x at the
+// given world time, wrapped to the texture so it stays precise
+func cloudOffset(ticks int64, speed float32) float32 {
+	d := float64(ticks) / clock.TickRate * float64(speed)
+	return float32(math.Mod(d, cloudTile))
+}
+
+// cloudThreshold returns the noise a slice of the layer needs to be
+// cloudy, the outer slices need more so the clouds are rounded
+func cloudThreshold(coverage float32, slice int) float32 {
+	edge := float32(2*slice)/(cloudSlices-1) - 1
+	return 1 - coverage + 0.12*edge*edge
+}
@@ -0,0 +1,195 @@
+// Package sky draws what is behind the world, the sky, the sun,
+// the moon and the cloud layer
+package sky
+
+import (
+	"github.com/artheus/go-minecraft/core/ctx"
+	"github.com/artheus/go-minecraft/core/game/settings"
+	"github.com/artheus/go-minecraft/core/types"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/faiface/glhf"
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// bodyDistance is how far from the camera the sun and moon are
+	// drawn, it has to be within the nearest far plane
+	bodyDistance = 40
+	// bodySize is half the width of the sun and moon quads
+	bodySize = 10
+)
+
+var (
+	sunriseTint = mgl32.Vec3{1, 0.55, 0.25}
+	sunTint     = mgl32.Vec3{1, 0.95, 0.8}
+	moonTint    = mgl32.Vec3{0.85, 0.88, 0.95}
+)
+
+// SkyRenderer draws the sky before the chunks and the clouds after them
+type SkyRenderer struct {
+	ctx *ctx.Context
+
+	domeShader, bodyShader, cloudShader *glhf.Shader
+	dome, body, cloud                   *types.Mesh
+	noise                               *glhf.Texture
+}
+
+func NewSkyRenderer(ctx *ctx.Context) (*SkyRenderer, error) {
+	r := &SkyRenderer{ctx: ctx}
+	pix := cloudTexture(cloudTextureSize)
+
+	var err error
+	mainthread.Call(func() {
+		r.domeShader, err = glhf.NewShader(quadFormat, domeUniformFormat, domeVertexSource, domeFragmentSource)
+		if err != nil {
+			return
+		}
+		r.bodyShader, err = glhf.NewShader(quadFormat, bodyUniformFormat, bodyVertexSource, bodyFragmentSource)
+		if err != nil {
+			return
+		}
+		r.cloudShader, err = glhf.NewShader(quadFormat, cloudUniformFormat, cloudVertexSource, cloudFragmentSource)
+		if err != nil {
+			return
+		}
+		r.dome = types.NewMesh(r.domeShader, quadVertices)
+		r.body = types.NewMesh(r.bodyShader, quadVertices)
+		r.cloud = types.NewMesh(r.cloudShader, quadVertices)
+
+		r.noise = glhf.NewTexture(cloudTextureSize, cloudTextureSize, true, pix)
+		r.noise.Begin()
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
+		r.noise.End()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// quadVertices are the two triangles of a quad from -1,-1 to 1,1
+var quadVertices = []float32{
+	-1, -1, 1, -1, 1, 1,
+	1, 1, -1, 1, -1, -1,
+}
+
+// Render draws the sky dome, the sun and the moon, it must be called on
+// the main thread before the chunks, as it writes no depth
+func (r *SkyRenderer) Render() {
+	game := r.ctx.Game()
+	camera := game.Camera().Pos()
+	// the sky is drawn as seen from the origin, so it moves with the camera
+	mat := game.ChunkRenderer().Get3dMat().Mul4(mgl32.Translate3D(camera.X(), camera.Y(), camera.Z()))
+	r.render(mat, game.Clock())
+}
+
+// render draws the sky with mat, a projection times a camera matrix
+// without translation
+func (r *SkyRenderer) render(mat mgl32.Mat4, clock types.IClock) {
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.CULL_FACE)
+	gl.DepthMask(false)
+	defer func() {
+		gl.DepthMask(true)
+		gl.Enable(gl.CULL_FACE)
+		gl.Enable(gl.DEPTH_TEST)
+		gl.Disable(gl.BLEND)
+	}()
+
+	r.domeShader.Begin()
+	r.domeShader.SetUniformAttr(0, mat.Inv())
+	r.domeShader.SetUniformAttr(1, clock.SkyColor())
+	r.domeShader.SetUniformAttr(2, clock.FogColor())
+	r.domeShader.SetUniformAttr(3, clock.SunDirection())
+	r.domeShader.SetUniformAttr(4, clock.Daylight())
+	r.dome.Render()
+	r.domeShader.End()
+
+	gl.Enable(gl.BLEND)
+	r.bodyShader.Begin()
+	r.bodyShader.SetUniformAttr(0, mat)
+
+	sun := clock.SunDirection()
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE)
+	tint := sunriseTint.Add(sunTint.Sub(sunriseTint).Mul(Min(Max(sun.Y()*3, 0), 1)))
+	r.renderBody(sun, tint, false)
+
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	r.renderBody(clock.MoonDirection(), moonTint, true)
+	r.bodyShader.End()
+}
+
+func (r *SkyRenderer) renderBody(dir, tint mgl32.Vec3, moon bool) {
+	center, right, up := bodyQuad(dir)
+	r.bodyShader.SetUniformAttr(1, center)
+	r.bodyShader.SetUniformAttr(2, right)
+	r.bodyShader.SetUniformAttr(3, up)
+	r.bodyShader.SetUniformAttr(4, tint)
+	var m int32
+	if moon {
+		m = 1
+	}
+	r.bodyShader.SetUniformAttr(5, m)
+	r.body.Render()
+}
+
+// bodyQuad returns the center of the sun or moon quad in direction dir
+// from the camera, and the half edges of the quad facing the camera
+func bodyQuad(dir mgl32.Vec3) (center, right, up mgl32.Vec3) {
+	center = dir.Mul(bodyDistance)
+	// the sun and moon move in the x-y plane, tilted a little towards z,
+	// so dir is never along z
+	right = dir.Cross(mgl32.Vec3{0, 0, 1}).Normalize()
+	up = dir.Cross(right).Normalize()
+	return center, right.Mul(bodySize), up.Mul(bodySize)
+}
+
+// RenderClouds draws the cloud layer, it must be called on the
+// main thread after the opaque chunks, to be hidden behind them
+func (r *SkyRenderer) RenderClouds() {
+	game := r.ctx.Game()
+	r.renderClouds(game.ChunkRenderer().Get3dMat(), game.Camera().Pos(), game.Clock(), settings.Current())
+}
+
+func (r *SkyRenderer) renderClouds(mat mgl32.Mat4, camera mgl32.Vec3, clock types.IClock, s settings.Settings) {
+	if s.CloudCoverage <= 0 {
+		return
+	}
+	gl.Disable(gl.CULL_FACE)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+	defer func() {
+		gl.DepthMask(true)
+		gl.Disable(gl.BLEND)
+		gl.Enable(gl.CULL_FACE)
+	}()
+
+	r.cloudShader.Begin()
+	r.noise.Begin()
+	r.cloudShader.SetUniformAttr(0, mat)
+	r.cloudShader.SetUniformAttr(1, camera)
+	r.cloudShader.SetUniformAttr(3, float32(s.RenderRadius*ChunkWidth))
+	r.cloudShader.SetUniformAttr(4, mgl32.Vec2{-cloudOffset(clock.Time(), s.CloudSpeed), 0})
+	r.cloudShader.SetUniformAttr(5, float32(cloudTile))
+	r.cloudShader.SetUniformAttr(7, clock.Daylight())
+	r.cloudShader.SetUniformAttr(8, clock.FogColor())
+
+	// the farthest slice is drawn first
+	below := camera.Y() < s.CloudHeight+cloudThickness/2
+	for i := 0; i < cloudSlices; i++ {
+		slice := i
+		if below {
+			slice = cloudSlices - 1 - i
+		}
+		height := s.CloudHeight + float32(slice)*cloudThickness/(cloudSlices-1)
+		r.cloudShader.SetUniformAttr(2, height)
+		r.cloudShader.SetUniformAttr(6, cloudThreshold(s.CloudCoverage, slice))
+		r.cloud.Render()
+	}
+	r.noise.End()
+	r.cloudShader.End()
+}
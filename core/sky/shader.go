@@ -0,0 +1,190 @@
+package sky
+
+import "github.com/faiface/glhf"
+
+var (
+	// quadFormat is a corner of a quad from -1,-1 to 1,1, all sky
+	// shaders place the quad with their uniforms
+	quadFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "corner", Type: glhf.Vec2},
+	}
+
+	domeUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "invmatrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "skycolor", Type: glhf.Vec3},
+		glhf.Attr{Name: "fogcolor", Type: glhf.Vec3},
+		glhf.Attr{Name: "sundir", Type: glhf.Vec3},
+		glhf.Attr{Name: "daylight", Type: glhf.Float},
+	}
+
+	// the dome covers the screen, each pixel gets the color of the
+	// direction it looks in
+	domeVertexSource = `
+#version 330 core
+
+in vec2 corner;
+
+uniform mat4 invmatrix;
+
+out vec3 direction;
+
+void main() {
+    vec4 p = invmatrix * vec4(corner, 1.0, 1.0);
+    direction = p.xyz / p.w;
+    gl_Position = vec4(corner, 0.0, 1.0);
+}
+`
+
+	domeFragmentSource = `
+#version 330 core
+
+in vec3 direction;
+
+uniform vec3 skycolor;
+uniform vec3 fogcolor;
+uniform vec3 sundir;
+uniform float daylight;
+
+out vec4 color;
+
+void main() {
+    vec3 dir = normalize(direction);
+    vec3 c = mix(fogcolor, skycolor, smoothstep(0.0, 0.45, dir.y));
+    // below the horizon the sky darkens towards the ground
+    c = mix(c, fogcolor * 0.6, smoothstep(0.0, -0.4, dir.y));
+    // the sky around the sun is brighter
+    float halo = pow(max(dot(dir, sundir), 0.0), 12.0);
+    c += fogcolor * halo * 0.35 * daylight;
+    color = vec4(c, 1.0);
+}
+`
+
+	bodyUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "center", Type: glhf.Vec3},
+		glhf.Attr{Name: "right", Type: glhf.Vec3},
+		glhf.Attr{Name: "up", Type: glhf.Vec3},
+		glhf.Attr{Name: "tint", Type: glhf.Vec3},
+		glhf.Attr{Name: "moon", Type: glhf.Int},
+	}
+
+	// the sun and the moon are quads facing the camera, drawn
+	// around the camera so they never get closer
+	bodyVertexSource = `
+#version 330 core
+
+in vec2 corner;
+
+uniform mat4 matrix;
+uniform vec3 center;
+uniform vec3 right;
+uniform vec3 up;
+
+out vec2 uv;
+out float height;
+
+void main() {
+    vec3 p = center + right * corner.x + up * corner.y;
+    uv = corner;
+    height = normalize(p).y;
+    gl_Position = matrix * vec4(p, 1.0);
+}
+`
+
+	bodyFragmentSource = `
+#version 330 core
+
+in vec2 uv;
+in float height;
+
+uniform vec3 tint;
+uniform int moon;
+
+out vec4 color;
+
+void main() {
+    float d = length(uv);
+    float a;
+    if (moon == 1) {
+        a = 1.0 - smoothstep(0.3, 0.34, d);
+        // darker patches on the face of the moon
+        float patches = 0.85 + 0.15 * sin(uv.x * 17.0) * sin(uv.y * 13.0);
+        color.rgb = tint * patches;
+    } else {
+        float disc = 1.0 - smoothstep(0.22, 0.26, d);
+        float glow = pow(max(1.0 - d, 0.0), 3.0) * 0.6;
+        a = max(disc, glow);
+        color.rgb = mix(tint, vec3(1.0), disc);
+    }
+    // sets behind the horizon
+    color.a = a * smoothstep(-0.08, 0.02, height);
+}
+`
+
+	cloudUniformFormat = glhf.AttrFormat{
+		glhf.Attr{Name: "matrix", Type: glhf.Mat4},
+		glhf.Attr{Name: "camera", Type: glhf.Vec3},
+		glhf.Attr{Name: "height", Type: glhf.Float},
+		glhf.Attr{Name: "extent", Type: glhf.Float},
+		glhf.Attr{Name: "offset", Type: glhf.Vec2},
+		glhf.Attr{Name: "tile", Type: glhf.Float},
+		glhf.Attr{Name: "threshold", Type: glhf.Float},
+		glhf.Attr{Name: "daylight", Type: glhf.Float},
+		glhf.Attr{Name: "fogcolor", Type: glhf.Vec3},
+	}
+
+	// the cloud layer is a stack of slices through a noise texture, a
+	// higher threshold near the top and the bottom slice rounds the clouds
+	cloudVertexSource = `
+#version 330 core
+
+in vec2 corner;
+
+uniform mat4 matrix;
+uniform vec3 camera;
+uniform float height;
+uniform float extent;
+uniform vec2 offset;
+uniform float tile;
+
+out vec2 uv;
+out vec3 position;
+
+void main() {
+    vec3 p = vec3(camera.x + corner.x * extent, height, camera.z + corner.y * extent);
+    uv = (p.xz + offset) / tile;
+    position = p;
+    gl_Position = matrix * vec4(p, 1.0);
+}
+`
+
+	cloudFragmentSource = `
+#version 330 core
+
+in vec2 uv;
+in vec3 position;
+
+uniform sampler2D noise;
+uniform vec3 camera;
+uniform float extent;
+uniform float threshold;
+uniform float daylight;
+uniform vec3 fogcolor;
+
+out vec4 color;
+
+void main() {
+    float n = texture(noise, uv).r;
+    float density = smoothstep(threshold, threshold + 0.12, n);
+    if (density <= 0.0) {
+        discard;
+    }
+    // thick clouds are darker, like their shaded underside
+    float shade = 1.0 - 0.3 * smoothstep(threshold, threshold + 0.4, n);
+    vec3 c = vec3(shade) * (0.25 + 0.75 * daylight);
+    // clouds fade into the fog at the edge of the layer
+    float fog = smoothstep(0.5, 1.0, length(position - camera) / extent);
+    color = vec4(mix(c, fogcolor, fog), density * 0.85 * (1.0 - fog));
+}
+`
+)
@@ -0,0 +1,59 @@
+package sky
+
+import (
+	"testing"
+
+	"github.com/artheus/go-minecraft/core/game/clock"
+	. "github.com/artheus/go-minecraft/math/f32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudTextureRepeatsWithoutSeams(t *testing.T) {
+	const size = 64
+	pix := cloudTexture(size)
+	at := func(x, y int) float32 {
+		return float32(pix[((y%size)*size+x%size)*4])
+	}
+
+	// the step over the edge is no larger than the steps inside
+	var inside, edge float32
+	for y := 0; y < size; y++ {
+		for x := 1; x < size; x++ {
+			inside = Max(inside, Abs(at(x, y)-at(x-1, y)))
+		}
+		edge = Max(edge, Abs(at(size, y)-at(size-1, y)))
+	}
+	for x := 0; x < size; x++ {
+		edge = Max(edge, Abs(at(x, size)-at(x, size-1)))
+	}
+	assert.LessOrEqual(t, edge, inside)
+}
+
+func TestCloudsMoveWithTheWorldTime(t *testing.T) {
+	assert.Equal(t, float32(0), cloudOffset(0, 2))
+	assert.Equal(t, float32(2), cloudOffset(clock.TickRate, 2))
+	assert.Equal(t, float32(0), cloudOffset(clock.TickRate*100, 0))
+	// wraps around the texture
+	assert.InDelta(t, 1, cloudOffset(clock.TickRate*(cloudTile+1), 1), 1e-3)
+}
+
+func TestCloudSlicesAreRounded(t *testing.T) {
+	middle := cloudThreshold(0.5, 1)
+	assert.Greater(t, cloudThreshold(0.5, 0), middle)
+	assert.Greater(t, cloudThreshold(0.5, cloudSlices-1), middle)
+	assert.Less(t, cloudThreshold(0.8, 1), middle)
+}
+
+func TestBodyQuadFacesTheCamera(t *testing.T) {
+	dir := clock.NewClock(clock.Day).SunDirection()
+	center, right, up := bodyQuad(dir)
+
+	assert.InDelta(t, bodyDistance, center.Len(), 1e-3)
+	assert.InDelta(t, 0, right.Dot(dir), 1e-4)
+	assert.InDelta(t, 0, up.Dot(dir), 1e-4)
+	assert.InDelta(t, 0, right.Dot(up), 1e-4)
+	assert.InDelta(t, bodySize, right.Len(), 1e-3)
+	// up points away from the horizon
+	assert.Greater(t, up.Dot(mgl32.Vec3{0, 1, 0}), float32(0))
+}
@@ -11,6 +11,9 @@ var (
 	TexturePath = flag.String("t", "texture.png", "texture file")
 )
 
+// LoadImage loads an image as non premultiplied RGBA pixels. Magenta
+// (255, 0, 255) pixels are made fully transparent, so the textures
+// can use it as a color key
 func LoadImage(fname string) ([]uint8, image.Rectangle, error) {
 	f, err := os.Open(fname)
 	if err != nil {
@@ -21,7 +24,16 @@ func LoadImage(fname string) ([]uint8, image.Rectangle, error) {
 	if err != nil {
 		return nil, image.Rectangle{}, err
 	}
-	rgba := image.NewRGBA(img.Bounds())
+	rgba := image.NewNRGBA(img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	keyMagenta(rgba.Pix)
 	return rgba.Pix, img.Bounds(), nil
 }
+
+func keyMagenta(pix []uint8) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		if pix[i] == 255 && pix[i+1] == 0 && pix[i+2] == 255 {
+			pix[i+3] = 0
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package texture
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// SavePNG writes img to fname as a png file, creating its directory
+func SavePNG(fname string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
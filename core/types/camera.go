@@ -7,6 +7,8 @@ type ICamera interface {
 	SetPos(pos mgl32.Vec3)
 	Pos() mgl32.Vec3
 	Front() mgl32.Vec3
+	// FOV is the vertical field of view in degrees
+	FOV() float32
 	FlipFlying()
 	Flying() bool
 	OnAngleChange(dx, dy float32)
@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// ChatMessage is a line of the chat log, from a player or the game
+type ChatMessage struct {
+	Text string
+	Time time.Time // when it was received
+}
+
+// IChat is the chat console, the log of messages and the line typed
+// while it is open
+type IChat interface {
+	// Add appends a message to the log
+	Add(text string)
+	// Messages returns the log, oldest first
+	Messages() []ChatMessage
+	Visible() bool
+	// Input returns the typed line and the cursor position in runes
+	Input() (string, int)
+	// Scroll returns how many messages the log is scrolled back
+	Scroll() int
+	// Completions returns the lines the typed line completes to and
+	// the index of the one shown
+	Completions() ([]string, int)
+}
@@ -0,0 +1,17 @@
+package types
+
+import "github.com/go-gl/mathgl/mgl32"
+
+type IClock interface {
+	Time() int64
+	Set(ticks int64)
+	Add(ticks int64)
+	TimeOfDay() int64
+
+	SunDirection() mgl32.Vec3
+	MoonDirection() mgl32.Vec3
+	LightDirection() mgl32.Vec3
+	SkyColor() mgl32.Vec3
+	FogColor() mgl32.Vec3
+	Daylight() float32
+}
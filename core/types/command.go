@@ -0,0 +1,38 @@
+package types
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Permission is the level a command source needs to run a command
+type Permission int
+
+const (
+	// PermissionPlayer is enough for commands that only change what
+	// the player sees, or only tell
+	PermissionPlayer Permission = iota
+	// PermissionOperator is needed to change the world and the players
+	PermissionOperator
+)
+
+// CommandSource is who runs a command
+type CommandSource struct {
+	Name       string
+	Permission Permission
+	// Pos is where ~ coordinates are relative to
+	Pos mgl32.Vec3
+}
+
+// ICommands runs slash commands, from the chat, stdin or rpc
+type ICommands interface {
+	// Run runs line and returns the message to show the source
+	Run(src CommandSource, line string) (string, error)
+	// Complete returns the lines line can be completed to, for tab
+	// completion
+	Complete(src CommandSource, line string) []string
+}
+
+// RemotePlayer is another player in the game, where it was last seen
+type RemotePlayer struct {
+	ID   int32
+	Name string // shown on its name tag
+	Pos  mgl32.Vec3
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"image"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/pkg/errors"
+)
+
+// Framebuffer is an offscreen render target with a color and a depth buffer,
+// all methods must be called on the main thread
+type Framebuffer struct {
+	fbo, color, depth uint32
+	width, height     int
+}
+
+func NewFramebuffer(width, height int) (*Framebuffer, error) {
+	f := new(Framebuffer)
+	gl.GenFramebuffers(1, &f.fbo)
+	gl.GenRenderbuffers(1, &f.color)
+	gl.GenRenderbuffers(1, &f.depth)
+	if err := f.Resize(width, height); err != nil {
+		f.Release()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Size returns the size of the framebuffer in pixels
+func (f *Framebuffer) Size() (int, int) {
+	return f.width, f.height
+}
+
+// Resize reallocates the buffers when the size changed
+func (f *Framebuffer) Resize(width, height int) error {
+	if width == f.width && height == f.height {
+		return nil
+	}
+	f.width, f.height = width, height
+
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.color)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, int32(width), int32(height))
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+	defer gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, f.color)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, f.depth)
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return errors.Errorf("framebuffer %dx%d incomplete: 0x%x", width, height, status)
+	}
+	return nil
+}
+
+// Begin makes the framebuffer the target of all drawing
+func (f *Framebuffer) Begin() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+	gl.Viewport(0, 0, int32(f.width), int32(f.height))
+}
+
+// End draws to the window again
+func (f *Framebuffer) End() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// Blit copies the color buffer to the window, scaled to width x height
+func (f *Framebuffer) Blit(width, height int) {
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, f.fbo)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, 0)
+	gl.BlitFramebuffer(0, 0, int32(f.width), int32(f.height),
+		0, 0, int32(width), int32(height), gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// Image reads back the color buffer. The frame is opaque, so alpha
+// left behind by blending translucent faces is dropped
+func (f *Framebuffer) Image() *image.NRGBA {
+	img := f.ImageAlpha()
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// ImageAlpha reads back the color buffer with its alpha, for drawings
+// on a cleared, transparent background
+func (f *Framebuffer) ImageAlpha() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, f.width, f.height))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, f.fbo)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(f.width), int32(f.height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+
+	// OpenGL rows start at the bottom
+	row := make([]uint8, img.Stride)
+	for y := 0; y < f.height/2; y++ {
+		top := img.Pix[y*img.Stride : (y+1)*img.Stride]
+		bottom := img.Pix[(f.height-1-y)*img.Stride : (f.height-y)*img.Stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+	return img
+}
+
+func (f *Framebuffer) Release() {
+	gl.DeleteFramebuffers(1, &f.fbo)
+	gl.DeleteRenderbuffers(1, &f.color)
+	gl.DeleteRenderbuffers(1, &f.depth)
+	f.fbo, f.color, f.depth = 0, 0, 0
+}
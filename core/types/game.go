@@ -9,6 +9,15 @@ type IGameApplication interface {
 	World() IWorld
 	Camera() ICamera
 	Window() *glfw.Window
+	Clock() IClock
+	Inventory() IInventory
+	Hotbar() IHotbar
+	Chat() IChat
+	Commands() ICommands
+	// RunCommand runs a command between two ticks, from any goroutine
+	RunCommand(src CommandSource, line string) (string, error)
+	// RunOnTick runs f between two ticks, from any goroutine
+	RunOnTick(f func()) error
 
 	CurrentBlockid() f32.Vec3
 	ShouldClose() bool
@@ -17,4 +26,8 @@ type IGameApplication interface {
 	LineRenderer() ILineRenderer
 	PlayerRenderer() IPlayerRenderer
 	ChunkRenderer() IChunkRenderer
+	ParticleRenderer() IParticleRenderer
+	TextRenderer() ITextRenderer
+	DebugOverlay() IDebugOverlay
+	Minimap() IMinimap
 }
@@ -58,6 +58,16 @@ func NewMesh(shader *glhf.Shader, data []float32) *Mesh {
 	return m
 }
 
+// Update replaces the vertex data of the mesh with data of the same size
+func (m *Mesh) Update(data []float32) {
+	if m.vbo == 0 {
+		return
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.vbo)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(data)*4, gl.Ptr(data))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
 func (m *Mesh) Faces() int {
 	return m.faces
 }
@@ -0,0 +1,17 @@
+package types
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Particle is a small textured quad facing the camera, that falls,
+// lands on blocks and disappears when its life is over
+type Particle struct {
+	Pos, Vel mgl32.Vec3
+	// Gravity is the downward acceleration in blocks per second squared
+	Gravity float32
+	// Life is how many seconds the particle lives, Age how many it has lived
+	Life, Age float32
+	// Size is the width of the quad in blocks
+	Size float32
+	// Tex is the part of the texture atlas shown, as u0, v0, u1, v1
+	Tex mgl32.Vec4
+}
@@ -1,6 +1,36 @@
 package types
 
 type PlayerState struct {
-	X, Y, Z float32
-	Rx, Ry  float32
+	X, Y, Z   float32
+	Rx, Ry    float32
+	Name      string      // shown to other players
+	Inventory []ItemStack `json:",omitempty"` // hotbar first
+	Slot      int         `json:",omitempty"` // selected hotbar slot
+	Sneaking  bool        `json:"-"`          // sent to other players, not saved
+	Sprinting bool        `json:"-"`
+}
+
+// ItemStack is a number of the same block, the empty stack has no ID
+type ItemStack struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// Empty tells if there is nothing in the stack
+func (s ItemStack) Empty() bool {
+	return s.ID == "" || s.Count <= 0
+}
+
+// IInventory is the blocks a player carries
+type IInventory interface {
+	Stacks() []ItemStack
+	// Held returns the stack picked up in the inventory screen
+	Held() ItemStack
+}
+
+// IHotbar is the row of blocks at hand shown at the bottom of the
+// screen, the block in the selected slot is the one placed
+type IHotbar interface {
+	Slots() []ItemStack
+	Selected() int
 }
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"image"
+
+	"github.com/artheus/go-minecraft/core/chunk/state"
 	"github.com/artheus/go-minecraft/math/f32"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/icexin/gocraft-server/proto"
@@ -14,7 +17,13 @@ type IPlayerRenderer interface {
 	IRenderer
 
 	UpdateOrAdd(id int32, s proto.PlayerState)
+	// SetName sets the display name shown over player id
+	SetName(id int32, name string)
+	// SetMotion sets if player id is shown sneaking or sprinting
+	SetMotion(id int32, sneaking, sprinting bool)
 	Remove(id int32)
+	// Players returns the players drawn, where they were last seen
+	Players() []RemotePlayer
 }
 
 type IChunkRenderer interface {
@@ -25,8 +34,91 @@ type IChunkRenderer interface {
 	Get2dMat() mgl32.Mat4
 	DirtyChunk(id f32.Vec3)
 	UpdateLoop()
+	// LoadChunks builds all meshes around the camera before returning
+	LoadChunks()
+	// ItemIcons draws the blocks ids as icons of size x size pixels
+	ItemIcons(ids []string, size int) ([]*image.NRGBA, error)
+	State() state.State
+}
+
+// ISkyRenderer draws the sky behind the chunks and the clouds over them
+type ISkyRenderer interface {
+	Render()
+	RenderClouds()
+}
+
+// IParticleRenderer simulates and draws particles, other systems
+// spawn theirs with Spawn
+type IParticleRenderer interface {
+	Render()
+	Spawn(ps ...Particle)
 }
 
 type ILineRenderer interface {
 	IRenderer
-}
\ No newline at end of file
+}
+
+// ITextRenderer draws text and boxes over the screen, what is added
+// during a frame is drawn by Render. Positions are in gui pixels
+// from the top left corner
+type ITextRenderer interface {
+	IRenderer
+
+	// Text adds s with color codes, it returns the width of s
+	Text(s string, x, y int, shadow bool) int
+	// TextAlpha adds s like Text, faded to alpha
+	TextAlpha(s string, x, y int, shadow bool, alpha float32) int
+	Box(r image.Rectangle, c mgl32.Vec4)
+	Width(s string) int
+	LineHeight() int
+	// Size returns the size of the screen in gui pixels
+	Size() (int, int)
+}
+
+// IMinimap draws a map of the terrain around the player in a corner of
+// the screen
+type IMinimap interface {
+	IRenderer
+
+	// DirtyChunk draws the map of chunk id again
+	DirtyChunk(id f32.Vec3)
+	UpdateLoop()
+	// Zoom goes through the zoom levels and hiding the map
+	Zoom()
+	// Rotate switches between north up and turning with the player
+	Rotate()
+}
+
+// DebugSide is the side of the screen debug lines are shown on
+type DebugSide int
+
+const (
+	DebugLeft DebugSide = iota
+	DebugRight
+)
+
+// IInventoryRenderer draws the hotbar, and the inventory screen when
+// it is open
+type IInventoryRenderer interface {
+	IRenderer
+
+	// Toggle opens or closes the inventory screen
+	Toggle()
+	Visible() bool
+	// SetCursor moves the cursor of the screen, in window coordinates
+	SetCursor(x, y float64)
+	// HoveredSlot returns the inventory slot under the cursor, or -1
+	HoveredSlot() int
+}
+
+// IDebugOverlay is the toggleable overlay of debug information. Other
+// systems add their lines with Register, lines is only called while
+// the overlay is shown, on the main thread
+type IDebugOverlay interface {
+	IRenderer
+
+	Register(name string, side DebugSide, lines func() []string)
+	Unregister(name string)
+	Toggle()
+	Visible() bool
+}
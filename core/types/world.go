@@ -6,10 +6,21 @@ import (
 	"github.com/go-gl/mathgl/mgl32"
 )
 
+// Collision tells what stopped a box moving through the world
+type Collision struct {
+	Ground  bool // it landed on a block
+	Ceiling bool // its top hit a block
+	Wall    bool // it was stopped sideways
+}
+
 type IWorld interface {
-	Collide(pos mgl32.Vec3) (mgl32.Vec3, bool)
+	Collide(box block.Cuboid, d mgl32.Vec3, step float32) (mgl32.Vec3, Collision)
+	// Ledge returns the move d of a box standing on the ground cut short
+	// so it doesn't walk off a drop deeper than drop
+	Ledge(box block.Cuboid, d mgl32.Vec3, drop float32) mgl32.Vec3
 	HitTest(pos mgl32.Vec3, vec mgl32.Vec3) (*Vec3, *Vec3)
 	Block(id Vec3) *block.Block
+	Light(id Vec3) (sky, torch float32)
 	BlockChunk(block Vec3) IChunk
 	UpdateBlock(id Vec3, tp *block.Block)
 	HasBlock(id Vec3) bool
@@ -11,19 +11,19 @@ require (
 	github.com/go-gl/glfw v0.0.0-20211024062804-40e447a793be
 	github.com/go-gl/mathgl v1.0.0
 	github.com/hashicorp/golang-lru v0.5.4
+	github.com/hashicorp/yamux v0.0.0-20211028200310-0bc27b27de87
 	github.com/icexin/gocraft-server v0.0.0-20200316021447-c466fe50ae44
 	github.com/ojrac/opensimplex-go v1.0.2
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/hashicorp/yamux v0.0.0-20211028200310-0bc27b27de87 // indirect
 	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/sirupsen/logrus v1.5.0 // indirect
-	golang.org/x/image v0.0.0-20211028202545-6944b10bf410 // indirect
 	golang.org/x/sys v0.0.0-20211117180635-dee7805ff2e1 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
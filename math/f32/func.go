@@ -31,6 +31,14 @@ func Cos(x float32) float32 {
 	return float32(math.Cos(float64(x)))
 }
 
+func Sqrt(x float32) float32 {
+	return float32(math.Sqrt(float64(x)))
+}
+
+func Pow(x, y float32) float32 {
+	return float32(math.Pow(float64(x), float64(y)))
+}
+
 func Radian(angle float32) float32 {
 	return mgl32.DegToRad(angle)
 }
@@ -36,6 +36,10 @@ func (v Vec3) Back() Vec3 {
 	return Vec3{v.X, v.Y, v.Z - 1}
 }
 
+func (v Vec3) Add(o Vec3) Vec3 {
+	return Vec3{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
 func (v Vec3) ChunkID() Vec3 {
 	return Vec3{
 		X: Floor(v.X / ChunkWidth),
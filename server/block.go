@@ -0,0 +1,45 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/icexin/gocraft-server/proto"
+)
+
+// BlockService keeps the blocks changed by the players, and pushes
+// every change to the other clients
+type BlockService struct {
+	mutex  sync.Mutex
+	server *Server
+	store  *Store
+}
+
+func NewBlockService(server *Server, store *Store) *BlockService {
+	return &BlockService{
+		server: server,
+		store:  store,
+	}
+}
+
+func (s *BlockService) UpdateBlock(req *proto.UpdateBlockRequest, rep *proto.UpdateBlockResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	version, err := s.store.UpdateBlock(req.P, req.Q, req.X, req.Y, req.Z, req.W)
+	if err != nil {
+		return err
+	}
+	req.Version = version
+	rep.Version = version
+	s.server.Push(req.Id, "Block.UpdateBlock", req, new(proto.UpdateBlockResponse))
+	return nil
+}
+
+func (s *BlockService) FetchChunk(req *proto.FetchChunkRequest, rep *proto.FetchChunkResponse) error {
+	rep.Version = s.store.GetChunkVersion(req.P, req.Q)
+	if req.Version == rep.Version {
+		return nil
+	}
+	return s.store.RangeBlocks(req.P, req.Q, func(x, y, z, w int) {
+		rep.Blocks = append(rep.Blocks, [...]int{x, y, z, w})
+	})
+}
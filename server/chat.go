@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/artheus/go-minecraft/core/game/rpc/wire"
+)
+
+// ChatService passes the chat messages of a client on to the others
+type ChatService struct {
+	server *Server
+}
+
+func NewChatService(server *Server) *ChatService {
+	return &ChatService{server: server}
+}
+
+// Send pushes a message to the clients but the one who sent it, who
+// already shows it
+func (s *ChatService) Send(req *wire.ChatRequest, rep *wire.ChatResponse) error {
+	s.server.Push(req.Id, "Chat.Message", req, new(wire.ChatResponse))
+	return nil
+}
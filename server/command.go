@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/game/rpc/wire"
+)
+
+// CommandService runs the slash commands of operators. A client sends
+// a line it may not run itself, and when it comes from an operator the
+// server runs it back on that client's game. Operators are made on the
+// server's console with /op and last until they leave
+type CommandService struct {
+	mutex   sync.Mutex
+	server  *Server
+	players *PlayerService
+	ops     map[int32]bool
+}
+
+func NewCommandService(server *Server, players *PlayerService) *CommandService {
+	return &CommandService{
+		server:  server,
+		players: players,
+		ops:     make(map[int32]bool),
+	}
+}
+
+// CommandCall is a CommandRequest with the client that sent it, which
+// is set by the server and can't be sent by the client
+type CommandCall struct {
+	wire.CommandRequest
+	caller int32
+}
+
+func (c *CommandCall) setCaller(id int32) {
+	c.caller = id
+}
+
+// Run runs the line of an operator on its game, other players are told
+// they aren't one
+func (s *CommandService) Run(req *CommandCall, rep *wire.CommandResponse) error {
+	if !s.isOp(req.caller) {
+		rep.Error = "you are not an operator"
+		return nil
+	}
+	sess, ok := s.server.Session(req.caller)
+	if !ok {
+		return nil
+	}
+	return sess.Call("Command.Run", &req.CommandRequest, rep)
+}
+
+func (s *CommandService) isOp(id int32) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ops[id]
+}
+
+// Console runs the commands typed on the server's console, one per
+// line, and logs what they did
+func (s *CommandService) Console(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		msg, err := s.console(line)
+		if err != nil {
+			log.Printf("%s: %s", line, err)
+			continue
+		}
+		log.Print(msg)
+	}
+}
+
+// console runs one line of the console
+func (s *CommandService) console(line string) (string, error) {
+	words := strings.Fields(strings.TrimPrefix(line, "/"))
+	switch {
+	case len(words) == 1 && words[0] == "ops":
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		ids := make([]string, 0, len(s.ops))
+		for id := range s.ops {
+			ids = append(ids, fmt.Sprint(id))
+		}
+		sort.Strings(ids)
+		return "operators: " + strings.Join(ids, ", "), nil
+	case len(words) == 2 && (words[0] == "op" || words[0] == "deop"):
+		id, ok := s.players.Find(words[1])
+		if !ok {
+			return "", fmt.Errorf("no player %q", words[1])
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if words[0] == "deop" {
+			delete(s.ops, id)
+			return fmt.Sprintf("%d is no longer an operator", id), nil
+		}
+		s.ops[id] = true
+		return fmt.Sprintf("made %d an operator", id), nil
+	}
+	return "", fmt.Errorf("unknown command, the console has /op <player>, /deop <player> and /ops")
+}
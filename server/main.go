@@ -0,0 +1,70 @@
+// The gocraft server keeps the changed blocks, the world time and the
+// players of a multiplayer world, passes on their chat and runs the
+// commands of operators. It speaks the protocol of
+// github.com/icexin/gocraft-server, and the calls this game adds to it
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/artheus/go-minecraft/core/game/clock"
+)
+
+var (
+	listenAddr = flag.String("l", ":8421", "listen address")
+	dbpath     = flag.String("db", "gocraft-server.db", "db file name")
+)
+
+func main() {
+	flag.Parse()
+
+	store, err := NewStore(*dbpath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	ticks, _ := store.GetTime()
+	if *clock.StartTime != "" {
+		if ticks, err = clock.ParseTime(*clock.StartTime); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	l, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := NewServer()
+	timeService := NewTimeService(server, store, ticks)
+	server.RegisterService("Block", NewBlockService(server, store))
+	players := NewPlayerService(server)
+	commands := NewCommandService(server, players)
+	server.RegisterService("Player", players)
+	server.RegisterService("Time", timeService)
+	server.RegisterService("Chat", NewChatService(server))
+	server.RegisterService("Command", commands)
+	go commands.Console(os.Stdin)
+	go timeService.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	log.Printf("listening on %s", l.Addr())
+	server.Serve(l)
+
+	if err = timeService.Save(); err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/artheus/go-minecraft/core/game/rpc/wire"
+	"github.com/icexin/gocraft-server/proto"
+)
+
+// PlayerService keeps the state and the display names of the connected
+// players, every client gets the others' states when it sends its own
+// and names and motions are pushed when they are set
+type PlayerService struct {
+	mutex   sync.Mutex
+	server  *Server
+	players map[int32]proto.PlayerState
+	names   map[int32]string
+}
+
+func NewPlayerService(server *Server) *PlayerService {
+	s := &PlayerService{
+		server:  server,
+		players: make(map[int32]proto.PlayerState),
+		names:   make(map[int32]string),
+	}
+	server.SetPlayerCallback(s.onPlayerCallback)
+	return s
+}
+
+func (s *PlayerService) UpdateState(req *proto.UpdateStateRequest, rep *proto.UpdateStateResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.players[req.Id]; !ok {
+		return nil
+	}
+	s.players[req.Id] = req.State
+	rep.Players = make(map[int32]proto.PlayerState)
+	for id, state := range s.players {
+		if id == req.Id {
+			continue
+		}
+		rep.Players[id] = state
+	}
+	return nil
+}
+
+// SetName sets the display name of a player and pushes it to the others
+func (s *PlayerService) SetName(req *wire.PlayerNameRequest, rep *wire.PlayerNameResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.players[req.Id]; !ok {
+		return nil
+	}
+	s.names[req.Id] = req.Name
+	s.server.Push(req.Id, "Player.SetName", req, new(wire.PlayerNameResponse))
+	return nil
+}
+
+// SetMotion pushes to the others that a player started or stopped
+// sneaking or sprinting
+func (s *PlayerService) SetMotion(req *wire.PlayerMotionRequest, rep *wire.PlayerMotionResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.players[req.Id]; !ok {
+		return nil
+	}
+	s.server.Push(req.Id, "Player.SetMotion", req, new(wire.PlayerMotionResponse))
+	return nil
+}
+
+// GetNames returns the display names of the players who have one
+func (s *PlayerService) GetNames(req *wire.PlayerNamesRequest, rep *wire.PlayerNamesResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rep.Names = make(map[int32]string, len(s.names))
+	for id, name := range s.names {
+		rep.Names[id] = name
+	}
+	return nil
+}
+
+// Find returns the id of the connected player with a display name or
+// an id
+func (s *PlayerService) Find(player string) (int32, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, name := range s.names {
+		if name == player {
+			return id, true
+		}
+	}
+	id, err := strconv.ParseInt(player, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	_, ok := s.players[int32(id)]
+	return int32(id), ok
+}
+
+func (s *PlayerService) onPlayerCallback(action string, id int32) {
+	switch action {
+	case "online":
+		s.addPlayer(id)
+	case "offline":
+		s.removePlayer(id)
+	}
+}
+
+func (s *PlayerService) addPlayer(pid int32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.players[pid] = proto.PlayerState{}
+}
+
+func (s *PlayerService) removePlayer(pid int32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.players, pid)
+	delete(s.names, pid)
+	s.server.Push(pid, "Player.RemovePlayer", &proto.RemovePlayerRequest{Id: pid}, new(proto.RemovePlayerResponse))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/yamux"
+)
+
+// Server speaks the gocraft-server protocol, every connection gets an
+// id and a duplex rpc session over yamux: the client calls the
+// services of the server, and the server calls the services of the
+// client to push changes of the other players
+type Server struct {
+	clientid  int32
+	sessions  sync.Map // map[id]*Session
+	rpcServer *rpc.Server
+
+	playerCallback func(string, int32)
+}
+
+func NewServer() *Server {
+	return &Server{
+		rpcServer: rpc.NewServer(),
+	}
+}
+
+// Session calls the services of a connected client
+type Session struct {
+	masterConn net.Conn
+	*rpc.Client
+}
+
+func NewSession(masterConn, clientConn net.Conn) *Session {
+	return &Session{
+		masterConn: masterConn,
+		Client:     rpc.NewClientWithCodec(jsonrpc.NewClientCodec(clientConn)),
+	}
+}
+
+func (s *Session) Close() {
+	s.Client.Close()
+	s.masterConn.Close()
+}
+
+// callerCodec reads the calls of client id, requests that have a
+// caller are told it, rather than trusting an id the client sends
+type callerCodec struct {
+	rpc.ServerCodec
+	id int32
+}
+
+func (c callerCodec) ReadRequestBody(body interface{}) error {
+	err := c.ServerCodec.ReadRequestBody(body)
+	if r, ok := body.(interface{ setCaller(int32) }); ok {
+		r.setCaller(c.id)
+	}
+	return err
+}
+
+func (s *Server) serveRpc(sess *yamux.Session, id int32) {
+	conn, err := sess.Accept()
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	s.rpcServer.ServeCodec(callerCodec{jsonrpc.NewServerCodec(conn), id})
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	id := atomic.AddInt32(&s.clientid, 1)
+	log.Printf("allocated %d for %s", id, conn.RemoteAddr())
+	// send id to client, handshake done.
+	binary.Write(conn, binary.BigEndian, id)
+
+	sess, err := yamux.Server(conn, nil)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	clientConn, err := sess.Open()
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	session := NewSession(conn, clientConn)
+	s.sessions.Store(id, session)
+	s.playerCallback("online", id)
+	s.serveRpc(sess, id)
+	s.sessions.Delete(id)
+	s.playerCallback("offline", id)
+	log.Printf("%s(%d) closed connection", conn.RemoteAddr(), id)
+}
+
+func (s *Server) RegisterService(name string, service interface{}) error {
+	return s.rpcServer.RegisterName(name, service)
+}
+
+// Session returns the session of client id
+func (s *Server) Session(id int32) (*Session, bool) {
+	sess, ok := s.sessions.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return sess.(*Session), true
+}
+
+func (s *Server) RangeSession(f func(id int32, sess *Session)) {
+	s.sessions.Range(func(k, v interface{}) bool {
+		f(k.(int32), v.(*Session))
+		return true
+	})
+}
+
+// Push calls method of every client except the one with id skip,
+// without waiting for them
+func (s *Server) Push(skip int32, method string, req, rep interface{}) {
+	s.RangeSession(func(id int32, sess *Session) {
+		if id == skip {
+			return
+		}
+		sess.Go(method, req, rep, nil)
+	})
+}
+
+func (s *Server) SetPlayerCallback(callback func(string, int32)) {
+	s.playerCallback = callback
+}
+
+func (s *Server) Serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		go s.handleConn(conn)
+	}
+}
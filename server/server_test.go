@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/game/rpc/wire"
+	gocraft "github.com/icexin/gocraft-server/client"
+	"github.com/icexin/gocraft-server/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushes records what the server pushed to a client
+type pushes struct {
+	mx    sync.Mutex
+	calls []interface{}
+}
+
+func (p *pushes) add(req interface{}) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.calls = append(p.calls, req)
+}
+
+// received returns the pushed requests
+func (p *pushes) received() []interface{} {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return append([]interface{}(nil), p.calls...)
+}
+
+type testBlock struct{ *pushes }
+
+func (s testBlock) UpdateBlock(req *proto.UpdateBlockRequest, rep *proto.UpdateBlockResponse) error {
+	s.add(*req)
+	return nil
+}
+
+type testPlayer struct{ *pushes }
+
+func (s testPlayer) SetName(req *wire.PlayerNameRequest, rep *wire.PlayerNameResponse) error {
+	s.add(*req)
+	return nil
+}
+
+func (s testPlayer) SetMotion(req *wire.PlayerMotionRequest, rep *wire.PlayerMotionResponse) error {
+	s.add(*req)
+	return nil
+}
+
+func (s testPlayer) RemovePlayer(req *proto.RemovePlayerRequest, rep *proto.RemovePlayerResponse) error {
+	s.add(*req)
+	return nil
+}
+
+type testTime struct{ *pushes }
+
+func (s testTime) SetTime(req *wire.TimeRequest, rep *wire.TimeResponse) error {
+	s.add(*req)
+	return nil
+}
+
+type testChat struct{ *pushes }
+
+func (s testChat) Message(req *wire.ChatRequest, rep *wire.ChatResponse) error {
+	s.add(*req)
+	return nil
+}
+
+// testCommand runs the lines the server sends back
+type testCommand struct{ *pushes }
+
+func (s testCommand) Run(req *wire.CommandRequest, rep *wire.CommandResponse) error {
+	s.add(*req)
+	rep.Message = "ran " + req.Line
+	return nil
+}
+
+// testServer serves a new world on a free port
+type testServer struct {
+	addr     string
+	time     *TimeService
+	store    *Store
+	players  *PlayerService
+	commands *CommandService
+}
+
+func newTestServer(t *testing.T) *testServer {
+	store, err := NewStore(filepath.Join(t.TempDir(), "server.db"))
+	require.NoError(t, err)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		l.Close()
+		store.Close()
+	})
+
+	server := NewServer()
+	s := &testServer{addr: l.Addr().String(), time: NewTimeService(server, store, 1000), store: store}
+	server.RegisterService("Block", NewBlockService(server, store))
+	s.players = NewPlayerService(server)
+	s.commands = NewCommandService(server, s.players)
+	server.RegisterService("Player", s.players)
+	server.RegisterService("Time", s.time)
+	server.RegisterService("Chat", NewChatService(server))
+	server.RegisterService("Command", s.commands)
+	go server.Serve(l)
+	return s
+}
+
+// join connects a client, what the server pushes to it is recorded
+func (s *testServer) join(t *testing.T) (*gocraft.Client, *pushes) {
+	conn, err := net.Dial("tcp", s.addr)
+	require.NoError(t, err)
+	p := new(pushes)
+	c := gocraft.NewClient()
+	c.RegisterService("Block", testBlock{p})
+	c.RegisterService("Player", testPlayer{p})
+	c.RegisterService("Time", testTime{p})
+	c.RegisterService("Chat", testChat{p})
+	c.RegisterService("Command", testCommand{p})
+	c.Start(conn)
+	t.Cleanup(c.Close)
+	// a call returns once the server added the player
+	require.NoError(t, c.Call("Player.UpdateState", &proto.UpdateStateRequest{Id: c.ClientId}, new(proto.UpdateStateResponse)))
+	return c, p
+}
+
+func eventually(t *testing.T, p *pushes, want interface{}) {
+	assert.Eventually(t, func() bool {
+		for _, req := range p.received() {
+			if assert.ObjectsAreEqual(want, req) {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond, "%v not pushed, got %v", want, p.received())
+}
+
+func TestBlocks(t *testing.T) {
+	s := newTestServer(t)
+	a, _ := s.join(t)
+	b, pb := s.join(t)
+
+	req := &proto.UpdateBlockRequest{Id: a.ClientId, P: -1, Q: 0, X: -3, Y: 5, Z: 2, W: 7}
+	rep := new(proto.UpdateBlockResponse)
+	require.NoError(t, a.Call("Block.UpdateBlock", req, rep))
+	assert.NotEmpty(t, rep.Version)
+	eventually(t, pb, proto.UpdateBlockRequest{Id: a.ClientId, P: -1, Q: 0, X: -3, Y: 5, Z: 2, W: 7, Version: rep.Version})
+
+	chunk := new(proto.FetchChunkResponse)
+	require.NoError(t, b.Call("Block.FetchChunk", &proto.FetchChunkRequest{P: -1, Q: 0}, chunk))
+	assert.Equal(t, [][4]int{{-3, 5, 2, 7}}, chunk.Blocks)
+	assert.Equal(t, rep.Version, chunk.Version)
+
+	unchanged := new(proto.FetchChunkResponse)
+	require.NoError(t, b.Call("Block.FetchChunk", &proto.FetchChunkRequest{P: -1, Q: 0, Version: rep.Version}, unchanged))
+	assert.Empty(t, unchanged.Blocks, "the client has this version")
+
+	require.NoError(t, b.Call("Block.FetchChunk", &proto.FetchChunkRequest{P: 0, Q: 0}, chunk))
+	assert.Empty(t, chunk.Blocks, "another chunk")
+}
+
+func TestPlayers(t *testing.T) {
+	s := newTestServer(t)
+	a, _ := s.join(t)
+	b, pb := s.join(t)
+
+	state := proto.PlayerState{X: 1, Y: 2, Z: 3, Rx: 4, Ry: 5}
+	require.NoError(t, a.Call("Player.UpdateState", &proto.UpdateStateRequest{Id: a.ClientId, State: state}, new(proto.UpdateStateResponse)))
+	rep := new(proto.UpdateStateResponse)
+	require.NoError(t, b.Call("Player.UpdateState", &proto.UpdateStateRequest{Id: b.ClientId}, rep))
+	assert.Equal(t, map[int32]proto.PlayerState{a.ClientId: state}, rep.Players)
+
+	a.Close()
+	eventually(t, pb, proto.RemovePlayerRequest{Id: a.ClientId})
+}
+
+func TestNames(t *testing.T) {
+	s := newTestServer(t)
+	a, pa := s.join(t)
+	b, pb := s.join(t)
+
+	require.NoError(t, a.Call("Player.SetName", &wire.PlayerNameRequest{Id: a.ClientId, Name: "Alex"}, new(wire.PlayerNameResponse)))
+	eventually(t, pb, wire.PlayerNameRequest{Id: a.ClientId, Name: "Alex"})
+	assert.Empty(t, pa.received(), "not pushed back to who set it")
+
+	c, _ := s.join(t)
+	rep := new(wire.PlayerNamesResponse)
+	require.NoError(t, c.Call("Player.GetNames", &wire.PlayerNamesRequest{}, rep))
+	assert.Equal(t, map[int32]string{a.ClientId: "Alex"}, rep.Names, "joining players get the names")
+
+	a.Close()
+	eventually(t, pb, proto.RemovePlayerRequest{Id: a.ClientId})
+	left := new(wire.PlayerNamesResponse)
+	require.NoError(t, b.Call("Player.GetNames", &wire.PlayerNamesRequest{}, left))
+	assert.Empty(t, left.Names, "forgotten when the player leaves")
+}
+
+func TestMotions(t *testing.T) {
+	s := newTestServer(t)
+	a, pa := s.join(t)
+	_, pb := s.join(t)
+
+	req := &wire.PlayerMotionRequest{Id: a.ClientId, Sneaking: true}
+	require.NoError(t, a.Call("Player.SetMotion", req, new(wire.PlayerMotionResponse)))
+	eventually(t, pb, *req)
+	assert.Empty(t, pa.received(), "not pushed back to who sneaks")
+}
+
+func TestTime(t *testing.T) {
+	s := newTestServer(t)
+	a, pa := s.join(t)
+	b, pb := s.join(t)
+
+	rep := new(wire.TimeResponse)
+	require.NoError(t, b.Call("Time.GetTime", &wire.TimeRequest{}, rep))
+	assert.Equal(t, int64(1000), rep.Time)
+
+	require.NoError(t, a.Call("Time.SetTime", &wire.TimeRequest{Time: 6000}, new(wire.TimeResponse)))
+	eventually(t, pb, wire.TimeRequest{Time: 6000})
+	eventually(t, pa, wire.TimeRequest{Time: 6000})
+	require.NoError(t, b.Call("Time.GetTime", &wire.TimeRequest{}, rep))
+	assert.Equal(t, int64(6000), rep.Time)
+
+	saved, ok := s.store.GetTime()
+	assert.True(t, ok)
+	assert.Equal(t, int64(6000), saved, "kept for the next start")
+
+	s.time.clock.Tick()
+	require.NoError(t, b.Call("Time.GetTime", &wire.TimeRequest{}, rep))
+	assert.Equal(t, int64(6001), rep.Time, "the clock ticks on the server")
+}
+
+func TestChat(t *testing.T) {
+	s := newTestServer(t)
+	a, pa := s.join(t)
+	_, pb := s.join(t)
+
+	req := &wire.ChatRequest{Id: a.ClientId, Name: "Alex", Text: "hello"}
+	require.NoError(t, a.Call("Chat.Send", req, new(wire.ChatResponse)))
+	eventually(t, pb, *req)
+	assert.Empty(t, pa.received(), "not pushed back to who sent it")
+}
+
+func TestCommands(t *testing.T) {
+	s := newTestServer(t)
+	a, pa := s.join(t)
+	b, pb := s.join(t)
+	require.NoError(t, a.Call("Player.SetName", &wire.PlayerNameRequest{Id: a.ClientId, Name: "Alex"}, new(wire.PlayerNameResponse)))
+
+	req := &wire.CommandRequest{Name: "Alex", Line: "/time set noon"}
+	rep := new(wire.CommandResponse)
+	require.NoError(t, a.Call("Command.Run", req, rep))
+	assert.Equal(t, "you are not an operator", rep.Error)
+
+	_, err := s.commands.console("/op Alex")
+	require.NoError(t, err)
+	rep = new(wire.CommandResponse)
+	require.NoError(t, a.Call("Command.Run", req, rep))
+	assert.Equal(t, wire.CommandResponse{Message: "ran /time set noon"}, *rep)
+	eventually(t, pa, *req)
+
+	rep = new(wire.CommandResponse)
+	require.NoError(t, b.Call("Command.Run", req, rep))
+	assert.Equal(t, "you are not an operator", rep.Error, "who is an operator is known by the connection")
+	for _, call := range pb.received() {
+		assert.NotEqual(t, *req, call, "not run on another player's game")
+	}
+
+	msg, err := s.commands.console("/ops")
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("operators: %d", a.ClientId), msg)
+	_, err = s.commands.console(fmt.Sprintf("/deop %d", a.ClientId))
+	require.NoError(t, err)
+	rep = new(wire.CommandResponse)
+	require.NoError(t, a.Call("Command.Run", req, rep))
+	assert.Equal(t, "you are not an operator", rep.Error)
+
+	_, err = s.commands.console("/op Steve")
+	assert.Error(t, err, "no such player")
+	_, err = s.commands.console("/stop")
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	blockBucket = []byte("block")
+	chunkBucket = []byte("chunk")
+	worldBucket = []byte("world")
+
+	timeKey = []byte("time")
+)
+
+// Store keeps the blocks changed by the players, the version of the
+// chunks they are in and the world time
+type Store struct {
+	db *bolt.DB
+}
+
+func NewStore(p string) (*Store, error) {
+	db, err := bolt.Open(p, 0666, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{blockBucket, chunkBucket, worldBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.NoSync = true
+	return &Store{
+		db: db,
+	}, nil
+}
+
+// UpdateBlock saves block w at x, y, z in chunk p, q and returns the
+// new version of the chunk
+func (s *Store) UpdateBlock(p, q, x, y, z, w int) (string, error) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 16)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		value := make([]byte, 4)
+		binary.LittleEndian.PutUint32(value, uint32(w))
+		if err := tx.Bucket(blockBucket).Put(encodeKey(p, q, x, y, z), value); err != nil {
+			return err
+		}
+		return tx.Bucket(chunkBucket).Put(encodeKey(p, q), []byte(version))
+	})
+	return version, err
+}
+
+// RangeBlocks calls f with every saved block of chunk p, q
+func (s *Store) RangeBlocks(p, q int, f func(x, y, z, w int)) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		prefix := encodeKey(p, q)
+		iter := tx.Bucket(blockBucket).Cursor()
+		for k, v := iter.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = iter.Next() {
+			var key [5]int32
+			binary.Read(bytes.NewReader(k), binary.LittleEndian, &key)
+			f(int(key[2]), int(key[3]), int(key[4]), int(binary.LittleEndian.Uint32(v)))
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetChunkVersion(p, q int) string {
+	var version string
+	s.db.View(func(tx *bolt.Tx) error {
+		version = string(tx.Bucket(chunkBucket).Get(encodeKey(p, q)))
+		return nil
+	})
+	return version
+}
+
+func (s *Store) UpdateTime(ticks int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		value := make([]byte, 8)
+		binary.LittleEndian.PutUint64(value, uint64(ticks))
+		return tx.Bucket(worldBucket).Put(timeKey, value)
+	})
+}
+
+// GetTime returns the saved world time, ok is false for a new world
+func (s *Store) GetTime() (ticks int64, ok bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(worldBucket).Get(timeKey)
+		if len(value) == 8 {
+			ticks, ok = int64(binary.LittleEndian.Uint64(value)), true
+		}
+		return nil
+	})
+	return ticks, ok
+}
+
+func (s *Store) Close() {
+	s.db.Sync()
+	s.db.Close()
+}
+
+func encodeKey(v ...int) []byte {
+	buf := new(bytes.Buffer)
+	for _, i := range v {
+		binary.Write(buf, binary.LittleEndian, int32(i))
+	}
+	return buf.Bytes()
+}
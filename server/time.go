@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/artheus/go-minecraft/core/game/clock"
+	"github.com/artheus/go-minecraft/core/game/rpc/wire"
+)
+
+// resyncInterval is how often the time is pushed to all clients, so
+// their clocks don't drift apart
+const resyncInterval = 30 * time.Second
+
+// TimeService keeps the world time, it ticks on the server and
+// clients fetch it when they join. A client changing it pushes it to
+// the others
+type TimeService struct {
+	server *Server
+	store  *Store
+	clock  *clock.Clock
+}
+
+func NewTimeService(server *Server, store *Store, ticks int64) *TimeService {
+	return &TimeService{
+		server: server,
+		store:  store,
+		clock:  clock.NewClock(ticks),
+	}
+}
+
+func (s *TimeService) GetTime(req *wire.TimeRequest, rep *wire.TimeResponse) error {
+	rep.Time = s.clock.Time()
+	return nil
+}
+
+// SetTime sets the world time of a client, set by /time, and pushes
+// it to the others
+func (s *TimeService) SetTime(req *wire.TimeRequest, rep *wire.TimeResponse) error {
+	s.clock.Set(req.Time)
+	rep.Time = req.Time
+	s.server.Push(0, "Time.SetTime", req, new(wire.TimeResponse))
+	return s.store.UpdateTime(req.Time)
+}
+
+// Run ticks the clock until ctx is done, pushing the time to the
+// clients every resyncInterval and saving it
+func (s *TimeService) Run(ctx context.Context) {
+	tick := time.NewTicker(clock.Step)
+	defer tick.Stop()
+	resync := time.NewTicker(resyncInterval)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			s.clock.Tick()
+		case <-resync.C:
+			req := &wire.TimeRequest{Time: s.clock.Time()}
+			s.server.Push(0, "Time.SetTime", req, new(wire.TimeResponse))
+			if err := s.store.UpdateTime(req.Time); err != nil {
+				log.Print(err)
+			}
+		}
+	}
+}
+
+// Save saves the world time
+func (s *TimeService) Save() error {
+	return s.store.UpdateTime(s.clock.Time())
+}